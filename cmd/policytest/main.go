@@ -0,0 +1,44 @@
+// Package main contains policy test runner, it validates policies and evaluates their test cases
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Entetry/authService/internal/policy"
+)
+
+func main() {
+	policiesDir := flag.String("policies", "policies", "directory with policy files")
+	testsDir := flag.String("tests", "", "directory with *_test.yaml files, defaults to policies directory")
+	flag.Parse()
+	if *testsDir == "" {
+		*testsDir = *policiesDir
+	}
+
+	set, err := policy.LoadDir(*policiesDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "policies are invalid: %v\n", err)
+		os.Exit(1)
+	}
+	results, err := policy.RunTests(set, *testsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't run policy tests: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Passed {
+			fmt.Printf("PASS %s: %s\n", result.File, result.Name)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s: %s: got %s (%s)\n", result.File, result.Name, result.Got, result.Reason)
+	}
+	fmt.Printf("%d passed, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,31 @@
+// Package audit contains audit event sinks
+package audit
+
+import (
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Logger writes audit events to application log
+type Logger struct {
+	logger *log.Logger
+}
+
+// NewLogger creates new audit Logger
+func NewLogger(logger *log.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Emit writes audit event
+func (l *Logger) Emit(event *model.AuditEvent) {
+	l.logger.WithFields(log.Fields{
+		"audit":      true,
+		"event":      event.Type,
+		"tenant_id":  event.TenantID,
+		"username":   event.Username,
+		"session_id": event.SessionID,
+		"event_time": time.Unix(event.Time, 0).UTC().Format(time.RFC3339),
+	}).Info("audit event")
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// APIKeyConfig config file for personal access tokens, zero MaxExpiration allows keys that never expire.
+// Keys are created only with access tokens of users authenticated no longer than MaxAuthAge ago.
+type APIKeyConfig struct {
+	MaxExpiration time.Duration `env:"API_KEY_MAX_EXPIRATION" envDefault:"0"`
+	MaxPerUser    int           `env:"API_KEY_MAX_PER_USER" envDefault:"20"`
+	MaxAuthAge    time.Duration `env:"API_KEY_MAX_AUTH_AGE" envDefault:"15m"`
+}
+
+// NewAPIKeyConfig creates new APIKeyConfig object
+func NewAPIKeyConfig() (*APIKeyConfig, error) {
+	cfg := new(APIKeyConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// AuthorizationConfig config file for authorization decisions
+type AuthorizationConfig struct {
+	DecisionCacheTTL             time.Duration `env:"DECISION_CACHE_TTL" envDefault:"1m"`
+	DecisionCacheCleanupInterval time.Duration `env:"DECISION_CACHE_CLEANUP_INTERVAL" envDefault:"5m"`
+	PolicyDir                    string        `env:"POLICY_DIR"`
+	PolicyReloadInterval         time.Duration `env:"POLICY_RELOAD_INTERVAL" envDefault:"30s"`
+}
+
+// NewAuthorizationConfig creates new AuthorizationConfig object
+func NewAuthorizationConfig() (*AuthorizationConfig, error) {
+	cfg := new(AuthorizationConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Access token formats
+const (
+	AccessTokenFormatJWT          = "jwt"
+	AccessTokenFormatOpaque       = "opaque"
+	AccessTokenFormatPasetoLocal  = "v4.local"
+	AccessTokenFormatPasetoPublic = "v4.public"
+	AccessTokenFormatJWE          = "jwe"
+)
+
+// ClientProfile per client application token settings, empty values fall back to tenant settings.
+// Empty GrantTypes or Scopes do not restrict the client, empty TenantID allows client in every tenant.
+// Empty AccessTokenFormat falls back to JwtConfig.
+// Clients other than the default one authenticate with a secret, whose hex encoded SHA-256 is kept in SecretHash,
+// or with a mutual TLS certificate listed in CertificateThumbprints by its x5t#S256 value.
+type ClientProfile struct {
+	ID                     string        `yaml:"id"`
+	TenantID               string        `yaml:"tenantId"`
+	AccessTokenExpiration  time.Duration `yaml:"accessTokenExpiration"`
+	RefreshTokenExpiration time.Duration `yaml:"refreshTokenExpiration"`
+	IdleTimeout            time.Duration `yaml:"idleTimeout"`
+	AbsoluteTimeout        time.Duration `yaml:"absoluteTimeout"`
+	GrantTypes             []string      `yaml:"grantTypes"`
+	Scopes                 []string      `yaml:"scopes"`
+	AccessTokenFormat      string        `yaml:"accessTokenFormat"`
+	SecretHash             string        `yaml:"secretHash"`
+	CertificateThumbprints []string      `yaml:"certificateThumbprints"`
+}
+
+// clientsFile client profiles file layout
+type clientsFile struct {
+	Clients []*ClientProfile `yaml:"clients"`
+}
+
+// Client returns client profile with tenant defaults applied, empty id means default client.
+// Returned profile carries id of the tenant it is resolved for.
+func (c *JwtConfig) Client(tenant *Tenant, id string) (*ClientProfile, bool) {
+	if id == "" {
+		id = c.DefaultClient
+	}
+	client, ok := c.Clients[id]
+	if !ok && id != c.DefaultClient {
+		return nil, false
+	}
+	effective := &ClientProfile{ID: id}
+	if client != nil {
+		*effective = *client
+	}
+	if effective.TenantID != "" && effective.TenantID != tenant.ID {
+		return nil, false
+	}
+	effective.TenantID = tenant.ID
+	if effective.AccessTokenExpiration == 0 {
+		effective.AccessTokenExpiration = tenant.AccessTokenExpiration
+	}
+	if effective.RefreshTokenExpiration == 0 {
+		effective.RefreshTokenExpiration = tenant.RefreshTokenExpiration
+	}
+	if effective.IdleTimeout == 0 {
+		effective.IdleTimeout = tenant.IdleTimeout
+	}
+	if effective.AbsoluteTimeout == 0 {
+		effective.AbsoluteTimeout = tenant.AbsoluteTimeout
+	}
+	if effective.AccessTokenFormat == "" {
+		effective.AccessTokenFormat = c.AccessTokenFormat
+	}
+	return effective, true
+}
+
+// loadClients reads client profiles from yaml file
+func loadClients(name string) (map[string]*ClientProfile, error) {
+	clients := make(map[string]*ClientProfile)
+	if name == "" {
+		return clients, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	file := &clientsFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	for _, client := range file.Clients {
+		if client.ID == "" {
+			return nil, errors.New("empty client id")
+		}
+		if !validAccessTokenFormat(client.AccessTokenFormat) {
+			return nil, errors.New("unsupported access token format of client " + client.ID)
+		}
+		clients[client.ID] = client
+	}
+	return clients, nil
+}
+
+// validAccessTokenFormat checks access token format, empty format means default one
+func validAccessTokenFormat(format string) bool {
+	switch format {
+	case "", AccessTokenFormatJWT, AccessTokenFormatOpaque, AccessTokenFormatPasetoLocal, AccessTokenFormatPasetoPublic,
+		AccessTokenFormatJWE:
+		return true
+	default:
+		return false
+	}
+}
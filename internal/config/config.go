@@ -2,13 +2,23 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+
 	"github.com/caarlos0/env/v6"
 )
 
-// Config Main application config
+// Config Main application config.
+// TLS is enabled with certificate and key files, client CA file turns on optional mutual TLS.
 type Config struct {
-	Port         int    `env:"APP_PORT" envDefault:"22800"`
-	UserEndpoint string `env:"USER_ENDPOINT"`
+	Port               int    `env:"APP_PORT" envDefault:"22800"`
+	UserEndpoint       string `env:"USER_ENDPOINT"`
+	TLSCertificateFile string `env:"APP_TLS_CERTIFICATE_FILE"`
+	TLSKeyFile         string `env:"APP_TLS_KEY_FILE"`
+	TLSClientCAFile    string `env:"APP_TLS_CLIENT_CA_FILE"`
 }
 
 // New Creates Config object
@@ -20,3 +30,41 @@ func New() (*Config, error) {
 	}
 	return cfg, nil
 }
+
+// ServerTLS returns server TLS config, nil when TLS is not configured.
+// Client certificates are verified when presented, clients without certificate are still accepted.
+func (c *Config) ServerTLS() (*tls.Config, error) {
+	if c.TLSCertificateFile == "" && c.TLSKeyFile == "" {
+		if c.TLSClientCAFile != "" {
+			return nil, errors.New("APP_TLS_CLIENT_CA_FILE requires server certificate")
+		}
+		return nil, nil
+	}
+	keyPair, err := tls.LoadX509KeyPair(filepath.Clean(c.TLSCertificateFile), filepath.Clean(c.TLSKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{keyPair}, MinVersion: tls.VersionTLS12}
+	if c.TLSClientCAFile != "" {
+		clientCAs, err := loadCertPool(c.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.ClientCAs = clientCAs
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsCfg, nil
+}
+
+// loadCertPool reads PEM encoded CA certificates of file
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no client CA certificates found in " + file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// DPoPConfig config file for DPoP sender-constrained tokens, see RFC 9449.
+// ServiceURI is the base of htu claims expected by token RPCs, empty ServiceURI falls back to https://<authority>.
+type DPoPConfig struct {
+	ServiceURI      string        `env:"DPOP_SERVICE_URI"`
+	ProofLifetime   time.Duration `env:"DPOP_PROOF_LIFETIME" envDefault:"1m"`
+	ClockSkew       time.Duration `env:"DPOP_CLOCK_SKEW" envDefault:"30s"`
+	CleanupInterval time.Duration `env:"DPOP_CLEANUP_INTERVAL" envDefault:"1m"`
+}
+
+// NewDPoPConfig creates new DPoPConfig object
+func NewDPoPConfig() (*DPoPConfig, error) {
+	cfg := new(DPoPConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
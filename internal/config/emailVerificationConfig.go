@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// EmailVerificationConfig config file for email verification, verified users are kept in StorageFile
+type EmailVerificationConfig struct {
+	TokenKey        string        `env:"EMAIL_VERIFICATION_KEY" envDefault:"my-email-verification-key"`
+	TokenExpiration time.Duration `env:"EMAIL_VERIFICATION_TOKEN_EXPIRATION" envDefault:"24h"`
+	URL             string        `env:"EMAIL_VERIFICATION_URL"`
+	StorageFile     string        `env:"EMAIL_VERIFICATION_FILE"`
+}
+
+// NewEmailVerificationConfig creates new EmailVerificationConfig object
+func NewEmailVerificationConfig() (*EmailVerificationConfig, error) {
+	cfg := new(EmailVerificationConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncryptionKey P-256 key encrypting access tokens of one audience, empty KeyID falls back to the audience
+type EncryptionKey struct {
+	Audience       string `yaml:"audience"`
+	KeyID          string `yaml:"keyId"`
+	PrivateKeyFile string `yaml:"privateKeyFile"`
+	PrivateKey     *ecdsa.PrivateKey
+}
+
+// encryptionKeysFile encryption keys file layout
+type encryptionKeysFile struct {
+	Keys []*EncryptionKey `yaml:"keys"`
+}
+
+// loadEncryptionKeys reads encryption keys from yaml file, private keys are PEM encoded SEC 1 or PKCS #8 files
+func loadEncryptionKeys(name string) (map[string]*EncryptionKey, error) {
+	keys := make(map[string]*EncryptionKey)
+	if name == "" {
+		return keys, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	file := &encryptionKeysFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	keyIDs := make(map[string]bool)
+	for _, key := range file.Keys {
+		if key.Audience == "" {
+			return nil, errors.New("empty encryption key audience")
+		}
+		if key.KeyID == "" {
+			key.KeyID = key.Audience
+		}
+		if keyIDs[key.KeyID] {
+			return nil, fmt.Errorf("duplicate encryption key id %q", key.KeyID)
+		}
+		keyIDs[key.KeyID] = true
+		key.PrivateKey, err = readECPrivateKey(key.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key of audience %q: %w", key.Audience, err)
+		}
+		keys[key.Audience] = key
+	}
+	return keys, nil
+}
+
+func readECPrivateKey(name string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var key interface{}
+	if block.Type == "EC PRIVATE KEY" {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, errors.New("private key must be a P-256 key")
+	}
+	return ecKey, nil
+}
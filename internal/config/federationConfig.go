@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// Federation provider types
+const (
+	FederationProviderOIDC   = "oidc"
+	FederationProviderOAuth2 = "oauth2"
+	FederationProviderGitHub = "github"
+)
+
+// GitHub OAuth app endpoints used by github providers unless overridden
+const (
+	gitHubAuthURL     = "https://github.com/login/oauth/authorize"
+	gitHubTokenURL    = "https://github.com/login/oauth/access_token"
+	gitHubUserInfoURL = "https://api.github.com/user"
+	gitHubEmailsURL   = "https://api.github.com/user/emails"
+)
+
+// FederationConfig config file for sign-in with upstream OpenID Connect and OAuth 2.0 providers
+type FederationConfig struct {
+	ProvidersFile   string        `env:"FEDERATION_PROVIDERS_FILE"`
+	RedirectURL     string        `env:"FEDERATION_REDIRECT_URL"`
+	StateExpiration time.Duration `env:"FEDERATION_STATE_EXPIRATION" envDefault:"10m"`
+	CleanupInterval time.Duration `env:"FEDERATION_CLEANUP_INTERVAL" envDefault:"5m"`
+	Providers       map[string]*FederationProvider
+}
+
+// FederationProvider upstream provider. OpenID Connect endpoints and keys are discovered from Issuer,
+// plain OAuth 2.0 providers name their endpoints and the UserInfoURL claims holding user subject and email.
+// GitHub providers default to GitHub endpoints and read verified email from EmailsURL.
+// Empty Type means OpenID Connect, empty RedirectURL falls back to FederationConfig.RedirectURL.
+type FederationProvider struct {
+	ID           string   `yaml:"id"`
+	Type         string   `yaml:"type"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret string   `yaml:"clientSecret"`
+	RedirectURL  string   `yaml:"redirectUrl"`
+	Scopes       []string `yaml:"scopes"`
+	AuthURL      string   `yaml:"authUrl"`
+	TokenURL     string   `yaml:"tokenUrl"`
+	UserInfoURL  string   `yaml:"userInfoUrl"`
+	EmailsURL    string   `yaml:"emailsUrl"`
+	SubjectClaim string   `yaml:"subjectClaim"`
+	EmailClaim   string   `yaml:"emailClaim"`
+}
+
+// federationProvidersFile providers file layout
+type federationProvidersFile struct {
+	Providers []*FederationProvider `yaml:"providers"`
+}
+
+// NewFederationConfig creates new FederationConfig object
+func NewFederationConfig() (*FederationConfig, error) {
+	cfg := new(FederationConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Providers, err = loadFederationProviders(cfg.ProvidersFile)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFederationProviders reads upstream providers from yaml file
+func loadFederationProviders(name string) (map[string]*FederationProvider, error) {
+	providers := make(map[string]*FederationProvider)
+	if name == "" {
+		return providers, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	file := &federationProvidersFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	for _, provider := range file.Providers {
+		if err = provider.applyDefaults(); err != nil {
+			return nil, err
+		}
+		providers[provider.ID] = provider
+	}
+	return providers, nil
+}
+
+// applyDefaults fills in endpoints and claims implied by provider type and checks required settings
+func (p *FederationProvider) applyDefaults() error {
+	if p.ID == "" || p.ClientID == "" {
+		return errors.New("federation provider requires id and clientId")
+	}
+	switch p.Type {
+	case "", FederationProviderOIDC:
+		p.Type = FederationProviderOIDC
+		if p.Issuer == "" {
+			return errors.New("openid connect provider " + p.ID + " requires issuer")
+		}
+		return nil
+	case FederationProviderGitHub:
+		p.AuthURL = defaultString(p.AuthURL, gitHubAuthURL)
+		p.TokenURL = defaultString(p.TokenURL, gitHubTokenURL)
+		p.UserInfoURL = defaultString(p.UserInfoURL, gitHubUserInfoURL)
+		p.EmailsURL = defaultString(p.EmailsURL, gitHubEmailsURL)
+		p.SubjectClaim = defaultString(p.SubjectClaim, "id")
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"read:user", "user:email"}
+		}
+	case FederationProviderOAuth2:
+	default:
+		return errors.New("unsupported type of federation provider " + p.ID)
+	}
+	if p.AuthURL == "" || p.TokenURL == "" || p.UserInfoURL == "" {
+		return errors.New("oauth2 provider " + p.ID + " requires authUrl, tokenUrl and userInfoUrl")
+	}
+	p.SubjectClaim = defaultString(p.SubjectClaim, "sub")
+	p.EmailClaim = defaultString(p.EmailClaim, "email")
+	return nil
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
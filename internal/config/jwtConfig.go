@@ -1,16 +1,49 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
-// JwtConfig config file for jwt auth
+// JwtConfig config file for jwt auth.
+// PASETO keys are hex encoded, PasetoSecretKeyHex is the Ed25519 seed signing v4.public tokens.
+// EncryptionKeys are keyed by the audience whose JWE access tokens they encrypt.
 type JwtConfig struct {
 	AccessTokenKey         string        `env:"ACCESS_TOKEN_KEY" envDefault:"my-access-token-key"`
 	AccessTokenExpiration  time.Duration `env:"ACCESS_TOKEN_EXPIRATION" envDefault:"30m"`
 	RefreshTokenExpiration time.Duration `env:"REFRESH_TOKEN_EXPIRATION" envDefault:"3000m"`
+	Issuer                 string        `env:"TOKEN_ISSUER" envDefault:"authService"`
+	Audience               string        `env:"TOKEN_AUDIENCE" envDefault:"api"`
+	Leeway                 time.Duration `env:"TOKEN_LEEWAY" envDefault:"30s"`
+	SessionIdleTimeout     time.Duration `env:"SESSION_IDLE_TIMEOUT"`
+	SessionAbsoluteTimeout time.Duration `env:"SESSION_ABSOLUTE_TIMEOUT" envDefault:"720h"`
+	RequireVerifiedEmail   bool          `env:"REQUIRE_VERIFIED_EMAIL" envDefault:"false"`
+	DefaultTenant          string        `env:"DEFAULT_TENANT" envDefault:"default"`
+	TenantsFile            string        `env:"TENANTS_FILE"`
+	Tenants                map[string]*Tenant
+	DefaultClient          string `env:"DEFAULT_CLIENT_ID" envDefault:"default"`
+	ClientsFile            string `env:"CLIENTS_FILE"`
+	Clients                map[string]*ClientProfile
+	AccessTokenFormat      string `env:"ACCESS_TOKEN_FORMAT" envDefault:"jwt"`
+
+	PasetoLocalKeyHex  string `env:"PASETO_LOCAL_KEY"`
+	PasetoSecretKeyHex string `env:"PASETO_SECRET_KEY"`
+	PasetoLocalKey     []byte
+	PasetoSecretKey    ed25519.PrivateKey
+
+	EncryptionKeysFile string `env:"ENCRYPTION_KEYS_FILE"`
+	EncryptionKeys     map[string]*EncryptionKey
+
+	ReferenceTokenCacheSize       int           `env:"REFERENCE_TOKEN_CACHE_SIZE" envDefault:"10000"`
+	ReferenceTokenCleanupInterval time.Duration `env:"REFERENCE_TOKEN_CLEANUP_INTERVAL" envDefault:"5m"`
+
+	RevokedTokensCapacity          uint          `env:"REVOKED_TOKENS_CAPACITY" envDefault:"100000"`
+	RevokedTokensFalsePositiveRate float64       `env:"REVOKED_TOKENS_FALSE_POSITIVE_RATE" envDefault:"0.01"`
+	RevokedTokensCleanupInterval   time.Duration `env:"REVOKED_TOKENS_CLEANUP_INTERVAL" envDefault:"5m"`
 }
 
 // NewJwtConfig creates new JwtConfig object
@@ -20,5 +53,100 @@ func NewJwtConfig() (*JwtConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !validAccessTokenFormat(cfg.AccessTokenFormat) {
+		return nil, errors.New("unsupported access token format " + cfg.AccessTokenFormat)
+	}
+	cfg.Tenants, err = loadTenants(cfg.TenantsFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Clients, err = loadClients(cfg.ClientsFile)
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.loadPasetoKeys(); err != nil {
+		return nil, err
+	}
+	cfg.EncryptionKeys, err = loadEncryptionKeys(cfg.EncryptionKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EncryptionKeys[cfg.Audience] == nil && cfg.usesAccessTokenFormat(AccessTokenFormatJWE) {
+		return nil, errors.New("encryption key of audience " + cfg.Audience + " is required for jwe access tokens")
+	}
 	return cfg, nil
 }
+
+// errInvalidPasetoKey godoc
+var errInvalidPasetoKey = errors.New("must be 32 hex encoded bytes")
+
+// loadPasetoKeys decodes PASETO keys, keys are required once default or client format needs them
+// and some tenant has no key of its own
+func (c *JwtConfig) loadPasetoKeys() error {
+	var err error
+	if c.PasetoLocalKey, err = decodePasetoLocalKey(c.PasetoLocalKeyHex); err != nil {
+		return errors.New("PASETO_LOCAL_KEY " + err.Error())
+	}
+	if c.PasetoSecretKey, err = decodePasetoSecretKey(c.PasetoSecretKeyHex); err != nil {
+		return errors.New("PASETO_SECRET_KEY " + err.Error())
+	}
+	if c.usesAccessTokenFormat(AccessTokenFormatPasetoLocal) && c.PasetoLocalKey == nil &&
+		!c.tenantsHaveKeys(func(tenant *Tenant) bool { return tenant.PasetoLocalKey != nil }) {
+		return errors.New("PASETO_LOCAL_KEY is required for v4.local access tokens")
+	}
+	if c.usesAccessTokenFormat(AccessTokenFormatPasetoPublic) && c.PasetoSecretKey == nil &&
+		!c.tenantsHaveKeys(func(tenant *Tenant) bool { return tenant.PasetoSecretKey != nil }) {
+		return errors.New("PASETO_SECRET_KEY is required for v4.public access tokens")
+	}
+	return nil
+}
+
+// tenantsHaveKeys reports whether default tenant and every configured tenant have key of their own
+func (c *JwtConfig) tenantsHaveKeys(hasKey func(tenant *Tenant) bool) bool {
+	if _, ok := c.Tenants[c.DefaultTenant]; !ok {
+		return false
+	}
+	for _, tenant := range c.Tenants {
+		if !hasKey(tenant) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodePasetoLocalKey decodes hex encoded v4.local key, empty value means no key
+func decodePasetoLocalKey(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	key, err := hex.DecodeString(value)
+	if err != nil || len(key) != 32 {
+		return nil, errInvalidPasetoKey
+	}
+	return key, nil
+}
+
+// decodePasetoSecretKey decodes hex encoded Ed25519 seed of v4.public key, empty value means no key
+func decodePasetoSecretKey(value string) (ed25519.PrivateKey, error) {
+	if value == "" {
+		return nil, nil
+	}
+	seed, err := hex.DecodeString(value)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errInvalidPasetoKey
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// usesAccessTokenFormat reports whether default or some client access token format is the given one
+func (c *JwtConfig) usesAccessTokenFormat(format string) bool {
+	if c.AccessTokenFormat == format {
+		return true
+	}
+	for _, client := range c.Clients {
+		if client.AccessTokenFormat == format {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// LDAP realm defaults
+const (
+	defaultLDAPUserFilter      = "(uid=%s)"
+	defaultLDAPUserIDAttribute = "entryUUID"
+	defaultLDAPGroupAttribute  = "memberOf"
+	defaultLDAPPoolSize        = 4
+	defaultLDAPTimeout         = 10 * time.Second
+)
+
+// LDAPConfig config file for LDAP and Active Directory credential realms
+type LDAPConfig struct {
+	RealmsFile string `env:"LDAP_REALMS_FILE"`
+	Realms     []*LDAPRealm
+}
+
+// LDAPRealm directory checking passwords of usernames matching UsernamePattern.
+// User entry is searched under UserBaseDN with UserFilter, %s is replaced with escaped username,
+// e.g. (sAMAccountName=%s) or (userPrincipalName=%s) for Active Directory, then password is checked
+// by binding as that entry. Groups listed in GroupAttribute are mapped to tenant roles with GroupRoles.
+// Empty BindDN searches anonymously.
+type LDAPRealm struct {
+	Name            string              `yaml:"name"`
+	UsernamePattern string              `yaml:"usernamePattern"`
+	URL             string              `yaml:"url"`
+	StartTLS        bool                `yaml:"startTls"`
+	BindDN          string              `yaml:"bindDn"`
+	BindPassword    string              `yaml:"bindPassword"`
+	UserBaseDN      string              `yaml:"userBaseDn"`
+	UserFilter      string              `yaml:"userFilter"`
+	UserIDAttribute string              `yaml:"userIdAttribute"`
+	GroupAttribute  string              `yaml:"groupAttribute"`
+	GroupRoles      map[string][]string `yaml:"groupRoles"`
+	PoolSize        int                 `yaml:"poolSize"`
+	Timeout         time.Duration       `yaml:"timeout"`
+	Pattern         *regexp.Regexp      `yaml:"-"`
+}
+
+// ldapRealmsFile realms file layout
+type ldapRealmsFile struct {
+	Realms []*LDAPRealm `yaml:"realms"`
+}
+
+// NewLDAPConfig creates new LDAPConfig object
+func NewLDAPConfig() (*LDAPConfig, error) {
+	cfg := new(LDAPConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Realms, err = loadLDAPRealms(cfg.RealmsFile)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadLDAPRealms reads realms from yaml file keeping their order, the first realm matching username wins
+func loadLDAPRealms(name string) ([]*LDAPRealm, error) {
+	if name == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	file := &ldapRealmsFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	for _, realm := range file.Realms {
+		if realm.Name == "" || realm.UsernamePattern == "" || realm.URL == "" || realm.UserBaseDN == "" {
+			return nil, errors.New("ldap realm requires name, usernamePattern, url and userBaseDn")
+		}
+		realm.Pattern, err = regexp.Compile(realm.UsernamePattern)
+		if err != nil {
+			return nil, err
+		}
+		setLDAPRealmDefaults(realm)
+	}
+	return file.Realms, nil
+}
+
+func setLDAPRealmDefaults(realm *LDAPRealm) {
+	if realm.UserFilter == "" {
+		realm.UserFilter = defaultLDAPUserFilter
+	}
+	if realm.UserIDAttribute == "" {
+		realm.UserIDAttribute = defaultLDAPUserIDAttribute
+	}
+	if realm.GroupAttribute == "" {
+		realm.GroupAttribute = defaultLDAPGroupAttribute
+	}
+	if realm.PoolSize <= 0 {
+		realm.PoolSize = defaultLDAPPoolSize
+	}
+	if realm.Timeout <= 0 {
+		realm.Timeout = defaultLDAPTimeout
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// PasswordPolicyConfig config file for password policy and old password checks of password change
+type PasswordPolicyConfig struct {
+	MinLength           int           `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	MaxLength           int           `env:"PASSWORD_MAX_LENGTH" envDefault:"72"`
+	RequireMixedCase    bool          `env:"PASSWORD_REQUIRE_MIXED_CASE" envDefault:"false"`
+	RequireDigit        bool          `env:"PASSWORD_REQUIRE_DIGIT" envDefault:"false"`
+	RequireSymbol       bool          `env:"PASSWORD_REQUIRE_SYMBOL" envDefault:"false"`
+	MaxChangeAttempts   int           `env:"PASSWORD_CHANGE_MAX_ATTEMPTS" envDefault:"5"`
+	ChangeAttemptWindow time.Duration `env:"PASSWORD_CHANGE_ATTEMPT_WINDOW" envDefault:"15m"`
+}
+
+// NewPasswordPolicyConfig creates new PasswordPolicyConfig object
+func NewPasswordPolicyConfig() (*PasswordPolicyConfig, error) {
+	cfg := new(PasswordPolicyConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
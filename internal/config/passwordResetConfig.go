@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// PasswordResetConfig config file for password reset
+type PasswordResetConfig struct {
+	TokenExpiration time.Duration `env:"PASSWORD_RESET_TOKEN_EXPIRATION" envDefault:"15m"`
+	URL             string        `env:"PASSWORD_RESET_URL"`
+	CleanupInterval time.Duration `env:"PASSWORD_RESET_CLEANUP_INTERVAL" envDefault:"5m"`
+	MaxRequests     int           `env:"PASSWORD_RESET_MAX_REQUESTS" envDefault:"3"`
+	RequestWindow   time.Duration `env:"PASSWORD_RESET_REQUEST_WINDOW" envDefault:"1h"`
+}
+
+// NewPasswordResetConfig creates new PasswordResetConfig object
+func NewPasswordResetConfig() (*PasswordResetConfig, error) {
+	cfg := new(PasswordResetConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// PasswordlessConfig config file for passwordless sign-in
+type PasswordlessConfig struct {
+	TokenExpiration time.Duration `env:"PASSWORDLESS_TOKEN_EXPIRATION" envDefault:"10m"`
+	MaxAttempts     int           `env:"PASSWORDLESS_MAX_ATTEMPTS" envDefault:"5"`
+	URL             string        `env:"PASSWORDLESS_URL"`
+	CleanupInterval time.Duration `env:"PASSWORDLESS_CLEANUP_INTERVAL" envDefault:"5m"`
+}
+
+// NewPasswordlessConfig creates new PasswordlessConfig object
+func NewPasswordlessConfig() (*PasswordlessConfig, error) {
+	cfg := new(PasswordlessConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v6"
+)
+
+// RBACConfig config file for roles and permissions
+type RBACConfig struct {
+	AdminPermission string   `env:"RBAC_ADMIN_PERMISSION" envDefault:"roles:admin"`
+	AdminRole       string   `env:"RBAC_ADMIN_ROLE" envDefault:"admin"`
+	AdminUserIDs    []string `env:"RBAC_ADMIN_USER_IDS" envSeparator:","`
+}
+
+// NewRBACConfig creates new RBACConfig object
+func NewRBACConfig() (*RBACConfig, error) {
+	cfg := new(RBACConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// SAMLConfig config file for SP-initiated SAML 2.0 login. Empty EntityID falls back to MetadataURL.
+// Key pair is optional, it is published in SP metadata and used to decrypt encrypted assertions.
+// ClockSkew can only narrow the 3m leeway of the SAML library. Identity provider metadata is read again
+// after MetadataRefreshInterval or the shorter cacheDuration it declares.
+type SAMLConfig struct {
+	EntityID                string        `env:"SAML_ENTITY_ID"`
+	MetadataURL             string        `env:"SAML_METADATA_URL"`
+	ACSURL                  string        `env:"SAML_ACS_URL"`
+	CertificateFile         string        `env:"SAML_CERTIFICATE_FILE"`
+	KeyFile                 string        `env:"SAML_KEY_FILE"`
+	ProvidersFile           string        `env:"SAML_PROVIDERS_FILE"`
+	ClockSkew               time.Duration `env:"SAML_CLOCK_SKEW" envDefault:"3m"`
+	RequestExpiration       time.Duration `env:"SAML_REQUEST_EXPIRATION" envDefault:"10m"`
+	CleanupInterval         time.Duration `env:"SAML_CLEANUP_INTERVAL" envDefault:"5m"`
+	MetadataRefreshInterval time.Duration `env:"SAML_METADATA_REFRESH_INTERVAL" envDefault:"24h"`
+	MetadataTimeout         time.Duration `env:"SAML_METADATA_TIMEOUT" envDefault:"10s"`
+	Certificate             *x509.Certificate
+	Key                     *rsa.PrivateKey
+	Providers               map[string]*SAMLProvider
+}
+
+// SAMLProvider SAML identity provider, metadata is read from MetadataFile or fetched from MetadataURL.
+// Attributes are matched by name or friendly name, EmailAttribute value is trusted as verified only with TrustEmail.
+// Values of GroupsAttribute are mapped to tenant roles with GroupRoles.
+type SAMLProvider struct {
+	ID              string              `yaml:"id"`
+	MetadataFile    string              `yaml:"metadataFile"`
+	MetadataURL     string              `yaml:"metadataUrl"`
+	EmailAttribute  string              `yaml:"emailAttribute"`
+	TrustEmail      bool                `yaml:"trustEmail"`
+	GroupsAttribute string              `yaml:"groupsAttribute"`
+	GroupRoles      map[string][]string `yaml:"groupRoles"`
+}
+
+// samlProvidersFile providers file layout
+type samlProvidersFile struct {
+	Providers []*SAMLProvider `yaml:"providers"`
+}
+
+// NewSAMLConfig creates new SAMLConfig object
+func NewSAMLConfig() (*SAMLConfig, error) {
+	cfg := new(SAMLConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CertificateFile != "" || cfg.KeyFile != "" {
+		cfg.Certificate, cfg.Key, err = loadSAMLKeyPair(cfg.CertificateFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cfg.Providers, err = loadSAMLProviders(cfg.ProvidersFile)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadSAMLKeyPair reads PEM encoded SP certificate and its RSA key
+func loadSAMLKeyPair(certificateFile, keyFile string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	keyPair, err := tls.LoadX509KeyPair(filepath.Clean(certificateFile), filepath.Clean(keyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("saml key must be an RSA private key")
+	}
+	certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return certificate, key, nil
+}
+
+// loadSAMLProviders reads identity providers from yaml file
+func loadSAMLProviders(name string) (map[string]*SAMLProvider, error) {
+	providers := make(map[string]*SAMLProvider)
+	if name == "" {
+		return providers, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	file := &samlProvidersFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	for _, provider := range file.Providers {
+		if provider.ID == "" || provider.MetadataFile == "" && provider.MetadataURL == "" {
+			return nil, errors.New("saml provider requires id and metadataFile or metadataUrl")
+		}
+		providers[provider.ID] = provider
+	}
+	return providers, nil
+}
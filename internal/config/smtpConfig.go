@@ -0,0 +1,24 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v6"
+)
+
+// SMTPConfig config file for outgoing mail
+type SMTPConfig struct {
+	Host     string `env:"SMTP_HOST" envDefault:"localhost"`
+	Port     int    `env:"SMTP_PORT" envDefault:"25"`
+	Username string `env:"SMTP_USERNAME"`
+	Password string `env:"SMTP_PASSWORD"`
+	From     string `env:"SMTP_FROM" envDefault:"no-reply@localhost"`
+}
+
+// NewSMTPConfig creates new SMTPConfig object
+func NewSMTPConfig() (*SMTPConfig, error) {
+	cfg := new(SMTPConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
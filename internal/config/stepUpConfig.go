@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// StepUpConfig config file for step-up authentication and authenticator app second factor.
+// Empty TOTPSecretsFile keeps authenticator app secrets in memory, so users enroll again after restart.
+type StepUpConfig struct {
+	TokenExpiration  time.Duration `env:"STEP_UP_TOKEN_EXPIRATION" envDefault:"5m"`
+	MaxAttempts      int           `env:"STEP_UP_MAX_ATTEMPTS" envDefault:"5"`
+	AttemptWindow    time.Duration `env:"STEP_UP_ATTEMPT_WINDOW" envDefault:"15m"`
+	CleanupInterval  time.Duration `env:"STEP_UP_CLEANUP_INTERVAL" envDefault:"5m"`
+	TOTPIssuer       string        `env:"TOTP_ISSUER" envDefault:"authService"`
+	TOTPSecretsFile  string        `env:"TOTP_SECRETS_FILE"`
+	EnrollMaxAuthAge time.Duration `env:"TOTP_ENROLL_MAX_AUTH_AGE" envDefault:"10m"`
+}
+
+// NewStepUpConfig creates new StepUpConfig object
+func NewStepUpConfig() (*StepUpConfig, error) {
+	cfg := new(StepUpConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tenant per tenant token settings, empty values fall back to JwtConfig.
+// PASETO keys are hex encoded like the global ones.
+type Tenant struct {
+	ID                     string             `yaml:"id"`
+	AccessTokenKey         string             `yaml:"accessTokenKey"`
+	AccessTokenExpiration  time.Duration      `yaml:"accessTokenExpiration"`
+	RefreshTokenExpiration time.Duration      `yaml:"refreshTokenExpiration"`
+	IdleTimeout            time.Duration      `yaml:"idleTimeout"`
+	AbsoluteTimeout        time.Duration      `yaml:"absoluteTimeout"`
+	AdminUserIDs           []string           `yaml:"adminUserIds"`
+	PasetoLocalKeyHex      string             `yaml:"pasetoLocalKey"`
+	PasetoSecretKeyHex     string             `yaml:"pasetoSecretKey"`
+	PasetoLocalKey         []byte             `yaml:"-"`
+	PasetoSecretKey        ed25519.PrivateKey `yaml:"-"`
+}
+
+// tenantsFile tenants file layout
+type tenantsFile struct {
+	Tenants []*Tenant `yaml:"tenants"`
+}
+
+// Tenant returns tenant settings with defaults applied, empty id means default tenant
+func (c *JwtConfig) Tenant(id string) (*Tenant, bool) {
+	if id == "" {
+		id = c.DefaultTenant
+	}
+	tenant, ok := c.Tenants[id]
+	if !ok && id != c.DefaultTenant {
+		return nil, false
+	}
+	effective := &Tenant{ID: id}
+	if tenant != nil {
+		*effective = *tenant
+	}
+	if effective.AccessTokenKey == "" {
+		effective.AccessTokenKey = c.AccessTokenKey
+	}
+	if effective.AccessTokenExpiration == 0 {
+		effective.AccessTokenExpiration = c.AccessTokenExpiration
+	}
+	if effective.RefreshTokenExpiration == 0 {
+		effective.RefreshTokenExpiration = c.RefreshTokenExpiration
+	}
+	if effective.IdleTimeout == 0 {
+		effective.IdleTimeout = c.SessionIdleTimeout
+	}
+	if effective.AbsoluteTimeout == 0 {
+		effective.AbsoluteTimeout = c.SessionAbsoluteTimeout
+	}
+	if effective.PasetoLocalKey == nil {
+		effective.PasetoLocalKey = c.PasetoLocalKey
+	}
+	if effective.PasetoSecretKey == nil {
+		effective.PasetoSecretKey = c.PasetoSecretKey
+	}
+	return effective, true
+}
+
+// loadTenants reads tenants from yaml file
+func loadTenants(name string) (map[string]*Tenant, error) {
+	tenants := make(map[string]*Tenant)
+	if name == "" {
+		return tenants, nil
+	}
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	file := &tenantsFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	for _, tenant := range file.Tenants {
+		if tenant.ID == "" || strings.Contains(tenant.ID, "/") {
+			return nil, fmt.Errorf("invalid tenant id %q", tenant.ID)
+		}
+		if tenant.PasetoLocalKey, err = decodePasetoLocalKey(tenant.PasetoLocalKeyHex); err != nil {
+			return nil, fmt.Errorf("pasetoLocalKey of tenant %s: %w", tenant.ID, err)
+		}
+		if tenant.PasetoSecretKey, err = decodePasetoSecretKey(tenant.PasetoSecretKeyHex); err != nil {
+			return nil, fmt.Errorf("pasetoSecretKey of tenant %s: %w", tenant.ID, err)
+		}
+		tenants[tenant.ID] = tenant
+	}
+	return tenants, nil
+}
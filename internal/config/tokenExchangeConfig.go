@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// TokenExchangeConfig config file for token exchange policy
+type TokenExchangeConfig struct {
+	AllowedAudiences      []string      `env:"TOKEN_EXCHANGE_AUDIENCES" envSeparator:","`
+	Actors                []string      `env:"TOKEN_EXCHANGE_ACTORS" envSeparator:","`
+	ImpersonatePermission string        `env:"TOKEN_EXCHANGE_IMPERSONATE_PERMISSION" envDefault:"users:impersonate"`
+	TokenExpiration       time.Duration `env:"TOKEN_EXCHANGE_TOKEN_EXPIRATION" envDefault:"5m"`
+}
+
+// NewTokenExchangeConfig creates new TokenExchangeConfig object
+func NewTokenExchangeConfig() (*TokenExchangeConfig, error) {
+	cfg := new(TokenExchangeConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
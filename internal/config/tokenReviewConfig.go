@@ -0,0 +1,58 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// TokenReviewConfig config file for Kubernetes TokenReview webhook, zero Port disables the webhook.
+// Tokens are validated in Tenant, reviews without audiences need tokens issued for Audience.
+// Prefixes are prepended to reviewed usernames and role groups.
+// The webhook is served over TLS only, callers authenticate with certificate issued by ClientCAFile,
+// with BearerToken or with both when both are set.
+type TokenReviewConfig struct {
+	Port           int    `env:"TOKEN_REVIEW_PORT" envDefault:"0"`
+	Path           string `env:"TOKEN_REVIEW_PATH" envDefault:"/tokenreview"`
+	Tenant         string `env:"TOKEN_REVIEW_TENANT"`
+	Audience       string `env:"TOKEN_REVIEW_AUDIENCE" envDefault:"kubernetes"`
+	UsernamePrefix string `env:"TOKEN_REVIEW_USERNAME_PREFIX"`
+	GroupPrefix    string `env:"TOKEN_REVIEW_GROUP_PREFIX"`
+	ClientCAFile   string `env:"TOKEN_REVIEW_CLIENT_CA_FILE"`
+	BearerToken    string `env:"TOKEN_REVIEW_BEARER_TOKEN"`
+}
+
+// NewTokenReviewConfig creates new TokenReviewConfig object
+func NewTokenReviewConfig() (*TokenReviewConfig, error) {
+	cfg := new(TokenReviewConfig)
+	err := env.Parse(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Audience == "" {
+		return nil, errors.New("TOKEN_REVIEW_AUDIENCE must not be empty")
+	}
+	if cfg.Port != 0 && cfg.ClientCAFile == "" && cfg.BearerToken == "" {
+		return nil, errors.New("TOKEN_REVIEW_PORT needs TOKEN_REVIEW_CLIENT_CA_FILE or TOKEN_REVIEW_BEARER_TOKEN")
+	}
+	return cfg, nil
+}
+
+// ServerTLS returns webhook TLS config based on server one, client certificates issued by ClientCAFile
+// are required when it is set. The webhook is not served without server TLS.
+func (c *TokenReviewConfig) ServerTLS(serverTLS *tls.Config) (*tls.Config, error) {
+	if serverTLS == nil {
+		return nil, errors.New("TOKEN_REVIEW_PORT needs APP_TLS_CERTIFICATE_FILE and APP_TLS_KEY_FILE")
+	}
+	tlsCfg := serverTLS.Clone()
+	if c.ClientCAFile != "" {
+		clientCAs, err := loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.ClientCAs = clientCAs
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsCfg, nil
+}
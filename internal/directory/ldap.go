@@ -0,0 +1,176 @@
+// Package directory contains credential verifiers backed by external user directories
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service"
+	"github.com/go-ldap/ldap/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// LDAP verifies passwords by binding to LDAP or Active Directory as the user entry
+type LDAP struct {
+	realm      *config.LDAPRealm
+	groupRoles map[string][]string
+	pool       *connPool
+}
+
+// NewLDAP creates new LDAP verifier for realm, connections are dialed lazily
+func NewLDAP(realm *config.LDAPRealm) *LDAP {
+	groupRoles := make(map[string][]string, len(realm.GroupRoles))
+	for group, roles := range realm.GroupRoles {
+		groupRoles[normalizeDN(group)] = roles
+	}
+	l := &LDAP{realm: realm, groupRoles: groupRoles}
+	l.pool = newConnPool(realm.PoolSize, l.dial)
+	return l
+}
+
+// VerifyCredentials finds user entry and binds as it with password, the connection is bound back
+// to the service account before it is returned to the pool
+func (l *LDAP) VerifyCredentials(_ context.Context, username, password string) (*model.Identity, error) {
+	// empty password makes an unauthenticated bind which most servers accept for any DN
+	if password == "" {
+		return nil, service.ErrInvalidPassword
+	}
+	conn, err := l.pool.get()
+	if err != nil {
+		log.Errorf("LDAP / VerifyCredentials / dial realm %s err %v ", l.realm.Name, err)
+		return nil, err
+	}
+	entry, err := l.findUser(conn, username)
+	if err != nil {
+		l.pool.put(conn, errors.Is(err, service.ErrInvalidPassword))
+		return nil, err
+	}
+	err = conn.Bind(entry.DN, password)
+	l.pool.put(conn, l.bindService(conn) == nil)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return nil, service.ErrInvalidPassword
+	} else if err != nil {
+		log.Errorf("LDAP / VerifyCredentials / Bind realm %s err %v ", l.realm.Name, err)
+		return nil, err
+	}
+
+	userID := entry.GetAttributeValue(l.realm.UserIDAttribute)
+	if userID == "" {
+		userID = entry.DN
+	}
+	return &model.Identity{
+		UserID:   userID,
+		Username: username,
+		Realm:    l.realm.Name,
+		Roles:    l.roles(entry.GetAttributeValues(l.realm.GroupAttribute)),
+	}, nil
+}
+
+// ResolveRoles finds user entry as the service account and maps its current groups to roles
+func (l *LDAP) ResolveRoles(_ context.Context, username string) ([]string, error) {
+	conn, err := l.pool.get()
+	if err != nil {
+		log.Errorf("LDAP / ResolveRoles / dial realm %s err %v ", l.realm.Name, err)
+		return nil, err
+	}
+	entry, err := l.findUser(conn, username)
+	l.pool.put(conn, err == nil || errors.Is(err, service.ErrInvalidPassword))
+	if errors.Is(err, service.ErrInvalidPassword) {
+		return nil, service.ErrRealmUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return l.roles(entry.GetAttributeValues(l.realm.GroupAttribute)), nil
+}
+
+// Close closes idle connections
+func (l *LDAP) Close() {
+	l.pool.close()
+}
+
+// findUser searches the single entry matching username, unknown and ambiguous usernames
+// fail with ErrInvalidPassword
+func (l *LDAP) findUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		l.realm.UserBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(l.realm.UserFilter, ldap.EscapeFilter(username)),
+		[]string{l.realm.UserIDAttribute, l.realm.GroupAttribute},
+		nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) ||
+		ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, service.ErrInvalidPassword
+	} else if err != nil {
+		log.Errorf("LDAP / findUser / Search realm %s err %v ", l.realm.Name, err)
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, service.ErrInvalidPassword
+	}
+	return result.Entries[0], nil
+}
+
+// roles maps group DNs to tenant roles
+func (l *LDAP) roles(groups []string) []string {
+	var roles []string
+	granted := make(map[string]bool)
+	for _, group := range groups {
+		for _, role := range l.groupRoles[normalizeDN(group)] {
+			if !granted[role] {
+				granted[role] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// dial opens connection bound as the service account
+func (l *LDAP) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(l.realm.URL, ldap.DialWithDialer(&net.Dialer{Timeout: l.realm.Timeout}))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(l.realm.Timeout)
+	if l.realm.StartTLS {
+		u, err := url.Parse(l.realm.URL)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err = conn.StartTLS(&tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if err = l.bindService(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (l *LDAP) bindService(conn *ldap.Conn) error {
+	if l.realm.BindDN == "" {
+		return conn.UnauthenticatedBind("")
+	}
+	return conn.Bind(l.realm.BindDN, l.realm.BindPassword)
+}
+
+// normalizeDN makes DNs differing only in case and spacing between components comparable
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.ToLower(strings.Join(parts, ","))
+}
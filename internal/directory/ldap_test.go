@@ -0,0 +1,247 @@
+package directory
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/service"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockBindDN       = "cn=svc,dc=example,dc=org"
+	mockBindPassword = "svc-secret"
+	mockUserDN       = "uid=alice,ou=people,dc=example,dc=org"
+	mockUserID       = "2f1c6a7e-7c1d-4b5e-9a51-0d4f1c2b3a4d"
+	mockPassword     = "alice-secret"
+)
+
+// testEntry directory entry served by testServer
+type testEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// testServer minimal in-process LDAP server answering simple binds and equality searches on uid
+type testServer struct {
+	listener net.Listener
+	entries  []*testEntry
+	accepted int32
+}
+
+func newTestServer(t *testing.T, entries ...*testEntry) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, "Expected no error when listening on loopback")
+	s := &testServer{listener: listener, entries: entries}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&s.accepted, 1)
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := s.bind(op.Children[1].Value.(string), op.Children[2].Data.String())
+			_, err = conn.Write(result(messageID, ldap.ApplicationBindResponse, code).Bytes())
+		case ldap.ApplicationSearchRequest:
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, entry := range s.entries {
+				if filter == "(uid="+entry.attributes["uid"][0]+")" {
+					if _, err = conn.Write(searchEntry(messageID, entry).Bytes()); err != nil {
+						return
+					}
+				}
+			}
+			_, err = conn.Write(result(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+		default:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *testServer) bind(dn, password string) int {
+	if dn == "" && password == "" || dn == mockBindDN && password == mockBindPassword {
+		return ldap.LDAPResultSuccess
+	}
+	for _, entry := range s.entries {
+		if entry.dn == dn && entry.password == password {
+			return ldap.LDAPResultSuccess
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials
+}
+
+func message(messageID int64, op *ber.Packet) *ber.Packet {
+	packet := ber.NewSequence("")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, ""))
+	packet.AppendChild(op)
+	return packet
+}
+
+func result(messageID int64, tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return message(messageID, op)
+}
+
+func searchEntry(messageID int64, entry *testEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, ""))
+	attributes := ber.NewSequence("")
+	for name, values := range entry.attributes {
+		attribute := ber.NewSequence("")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+	return message(messageID, op)
+}
+
+func newTestRealm(url string) *config.LDAPRealm {
+	return &config.LDAPRealm{
+		Name:            "corp",
+		URL:             url,
+		BindDN:          mockBindDN,
+		BindPassword:    mockBindPassword,
+		UserBaseDN:      "ou=people,dc=example,dc=org",
+		UserFilter:      "(uid=%s)",
+		UserIDAttribute: "entryUUID",
+		GroupAttribute:  "memberOf",
+		GroupRoles: map[string][]string{
+			"cn=Admins, ou=groups,dc=example,dc=org": {"admin"},
+			"cn=staff,ou=groups,dc=example,dc=org":   {"viewer", "editor"},
+		},
+		PoolSize: 2,
+	}
+}
+
+func newTestUser() *testEntry {
+	return &testEntry{
+		dn:       mockUserDN,
+		password: mockPassword,
+		attributes: map[string][]string{
+			"uid":       {"alice"},
+			"entryUUID": {mockUserID},
+			"memberOf": {
+				"cn=admins,ou=groups,dc=example,dc=org",
+				"cn=staff,ou=groups,dc=example,dc=org",
+				"cn=other,ou=groups,dc=example,dc=org",
+			},
+		},
+	}
+}
+
+func TestLDAP_VerifyCredentials(t *testing.T) {
+	server := newTestServer(t, newTestUser())
+	verifier := NewLDAP(newTestRealm(server.url()))
+	defer verifier.Close()
+
+	t.Log("password is checked by binding as user entry, groups are mapped to roles")
+	identity, err := verifier.VerifyCredentials(context.Background(), "alice", mockPassword)
+	assert.NoError(t, err, "Expected no error when verifying valid credentials")
+	if assert.NotNil(t, identity, "Expected identity of verified user") {
+		assert.Equal(t, mockUserID, identity.UserID, "User ID mismatch")
+		assert.Equal(t, "alice", identity.Username, "Username mismatch")
+		assert.Equal(t, "corp", identity.Realm, "Realm mismatch")
+		assert.Equal(t, []string{"admin", "editor", "viewer"}, identity.Roles, "Expected groups mapped to roles")
+	}
+
+	t.Log("wrong password is rejected")
+	_, err = verifier.VerifyCredentials(context.Background(), "alice", "wrong")
+	assert.ErrorIs(t, err, service.ErrInvalidPassword, "Expected ErrInvalidPassword for wrong password")
+
+	t.Log("empty password never reaches the server as unauthenticated bind")
+	_, err = verifier.VerifyCredentials(context.Background(), "alice", "")
+	assert.ErrorIs(t, err, service.ErrInvalidPassword, "Expected ErrInvalidPassword for empty password")
+
+	t.Log("unknown user is rejected like wrong password")
+	_, err = verifier.VerifyCredentials(context.Background(), "bob", mockPassword)
+	assert.ErrorIs(t, err, service.ErrInvalidPassword, "Expected ErrInvalidPassword for unknown user")
+
+	t.Log("username is escaped in search filter")
+	_, err = verifier.VerifyCredentials(context.Background(), "*", mockPassword)
+	assert.ErrorIs(t, err, service.ErrInvalidPassword, "Expected ErrInvalidPassword for wildcard username")
+
+	t.Log("connection is reused across verifications")
+	_, err = verifier.VerifyCredentials(context.Background(), "alice", mockPassword)
+	assert.NoError(t, err, "Expected no error when verifying again")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.accepted), "Expected single pooled connection")
+}
+
+func TestLDAP_ResolveRoles(t *testing.T) {
+	server := newTestServer(t, newTestUser())
+	verifier := NewLDAP(newTestRealm(server.url()))
+	defer verifier.Close()
+
+	t.Log("roles are resolved from groups without user password")
+	roles, err := verifier.ResolveRoles(context.Background(), "alice")
+	assert.NoError(t, err, "Expected no error when resolving roles")
+	assert.Equal(t, []string{"admin", "editor", "viewer"}, roles, "Expected groups mapped to roles")
+
+	t.Log("user removed from directory is reported")
+	_, err = verifier.ResolveRoles(context.Background(), "bob")
+	assert.ErrorIs(t, err, service.ErrRealmUserNotFound, "Expected ErrRealmUserNotFound for unknown user")
+}
+
+func TestLDAP_VerifyCredentialsFallbackUserID(t *testing.T) {
+	user := newTestUser()
+	delete(user.attributes, "entryUUID")
+	server := newTestServer(t, user)
+	verifier := NewLDAP(newTestRealm(server.url()))
+	defer verifier.Close()
+
+	identity, err := verifier.VerifyCredentials(context.Background(), "alice", mockPassword)
+	assert.NoError(t, err, "Expected no error when verifying valid credentials")
+	if assert.NotNil(t, identity, "Expected identity of verified user") {
+		assert.Equal(t, mockUserDN, identity.UserID, "Expected entry DN as user ID without ID attribute")
+	}
+}
+
+func TestLDAP_VerifyCredentialsServiceBindFailed(t *testing.T) {
+	server := newTestServer(t, newTestUser())
+	realm := newTestRealm(server.url())
+	realm.BindPassword = "wrong"
+	verifier := NewLDAP(realm)
+	defer verifier.Close()
+
+	_, err := verifier.VerifyCredentials(context.Background(), "alice", mockPassword)
+	assert.Error(t, err, "Expected error when service account can't bind")
+	assert.NotErrorIs(t, err, service.ErrInvalidPassword, "Expected directory failure not reported as wrong password")
+}
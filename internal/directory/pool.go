@@ -0,0 +1,52 @@
+package directory
+
+import "github.com/go-ldap/ldap/v3"
+
+// connPool keeps up to size idle connections, more are dialed under load and closed when returned to a full pool
+type connPool struct {
+	dial func() (*ldap.Conn, error)
+	idle chan *ldap.Conn
+}
+
+func newConnPool(size int, dial func() (*ldap.Conn, error)) *connPool {
+	return &connPool{dial: dial, idle: make(chan *ldap.Conn, size)}
+}
+
+// get returns idle connection or dials a new one, connections closed by the server are dropped
+func (p *connPool) get() (*ldap.Conn, error) {
+	for {
+		select {
+		case conn := <-p.idle:
+			if conn.IsClosing() {
+				continue
+			}
+			return conn, nil
+		default:
+			return p.dial()
+		}
+	}
+}
+
+// put returns healthy connection to the pool, broken ones are closed
+func (p *connPool) put(conn *ldap.Conn, healthy bool) {
+	if !healthy || conn.IsClosing() {
+		conn.Close()
+		return
+	}
+	select {
+	case p.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (p *connPool) close() {
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
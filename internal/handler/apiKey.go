@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// CreateAPIKey create personal access token, the key secret is only returned here
+func (a *Auth) CreateAPIKey(ctx context.Context, request *authService.CreateAPIKeyRequest) (*authService.CreateAPIKeyResponse, error) {
+	secret, key, err := a.apiKeys.CreateAPIKey(ctx, request.AccessToken, request.Name, request.Scope,
+		time.Duration(request.ExpiresIn)*time.Second, request.AllowedIps)
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+
+	return &authService.CreateAPIKeyResponse{ApiKey: secret, Key: apiKeyResponse(key)}, nil
+}
+
+// ListAPIKeys list personal access tokens of the user
+func (a *Auth) ListAPIKeys(ctx context.Context, request *authService.ListAPIKeysRequest) (*authService.ListAPIKeysResponse, error) {
+	keys, err := a.apiKeys.ListAPIKeys(ctx, request.AccessToken)
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+	response := &authService.ListAPIKeysResponse{Keys: make([]*authService.APIKey, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, apiKeyResponse(key))
+	}
+
+	return response, nil
+}
+
+// RevokeAPIKey revoke personal access token of the user
+func (a *Auth) RevokeAPIKey(ctx context.Context, request *authService.RevokeAPIKeyRequest) (*authService.RevokeAPIKeyResponse, error) {
+	err := a.apiKeys.RevokeAPIKey(ctx, request.AccessToken, request.Id)
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+
+	return &authService.RevokeAPIKeyResponse{}, nil
+}
+
+func apiKeyResponse(key *model.APIKey) *authService.APIKey {
+	return &authService.APIKey{
+		Id:         key.ID,
+		Name:       key.Name,
+		Scope:      key.Scope,
+		AllowedIps: key.AllowedCIDRs,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
+
+// clientIP returns address API key was presented from, defaults to the caller address
+func clientIP(ctx context.Context, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func apiKeyError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidAPIKeyName) || errors.Is(err, service.ErrInvalidAPIKeyExpiration) ||
+		errors.Is(err, service.ErrInvalidAllowedAddress) || errors.Is(err, service.ErrInvalidScope):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrTooManyAPIKeys):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrAuthenticationTooOld):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case isStatusError(err):
+		return err
+	default:
+		log.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
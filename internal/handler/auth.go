@@ -4,7 +4,9 @@ package handler
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/Entetry/authService/internal/model"
 	"github.com/Entetry/authService/internal/service"
 	"github.com/Entetry/authService/protocol/authService"
 	log "github.com/sirupsen/logrus"
@@ -15,17 +17,60 @@ import (
 // Auth handler struct
 type Auth struct {
 	authService.UnsafeAuthGRPCServiceServer
-	auth *service.Auth
+	auth              *service.Auth
+	tokenExchange     *service.TokenExchange
+	rbac              *service.RBAC
+	authorizer        *service.Authorizer
+	stepUp            *service.StepUp
+	totp              *service.TOTP
+	emailVerification *service.EmailVerification
+	passwordless      *service.Passwordless
+	federation        *service.Federation
+	saml              *service.SAML
+	apiKeys           *service.APIKeys
+	dpop              *service.DPoP
+}
+
+// Services services behind auth handler RPCs besides Auth
+type Services struct {
+	TokenExchange     *service.TokenExchange
+	RBAC              *service.RBAC
+	Authorizer        *service.Authorizer
+	StepUp            *service.StepUp
+	TOTP              *service.TOTP
+	EmailVerification *service.EmailVerification
+	Passwordless      *service.Passwordless
+	Federation        *service.Federation
+	SAML              *service.SAML
+	APIKeys           *service.APIKeys
+	DPoP              *service.DPoP
 }
 
 // NewAuth creates new auth handler
-func NewAuth(auth *service.Auth) *Auth {
-	return &Auth{auth: auth}
+func NewAuth(auth *service.Auth, services Services) *Auth {
+	return &Auth{auth: auth, tokenExchange: services.TokenExchange, rbac: services.RBAC,
+		authorizer: services.Authorizer, stepUp: services.StepUp, totp: services.TOTP,
+		emailVerification: services.EmailVerification, passwordless: services.Passwordless,
+		federation: services.Federation, saml: services.SAML, apiKeys: services.APIKeys, dpop: services.DPoP}
 }
 
-// ValidateTokens validate jwt tokens endpoint
-func (a *Auth) ValidateTokens(_ context.Context, request *authService.ValidateTokensRequest) (*authService.ValidateTokensResponse, error) {
-	err := a.auth.ValidateToken(request.AccessToken)
+// ValidateTokens validate jwt tokens and personal access tokens endpoint.
+// DPoP bound tokens need the proof of the request they were presented with, it replaces proof of the RPC itself.
+func (a *Auth) ValidateTokens(ctx context.Context, request *authService.ValidateTokensRequest) (*authService.ValidateTokensResponse, error) {
+	opts := service.ValidateOptions{
+		Issuer:     request.Issuer,
+		Audience:   request.Audience,
+		MaxAuthAge: time.Duration(request.MaxAuthAge) * time.Second,
+	}
+	var err error
+	if service.IsAPIKey(request.AccessToken) {
+		_, err = a.apiKeys.ValidateAPIKey(ctx, request.AccessToken, clientIP(ctx, request.ClientIp), opts)
+	} else {
+		ctx, err = a.dpop.WithProof(ctx, request.DpopProof, request.HttpMethod, request.HttpUri)
+		if err == nil {
+			_, err = a.auth.ValidateToken(ctx, request.AccessToken, opts)
+		}
+	}
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
@@ -39,7 +84,14 @@ func (a *Auth) RefreshTokens(ctx context.Context, request *authService.RefreshTo
 	switch {
 	case errors.Is(err, service.ErrRefreshTokenNotFound):
 		return nil, status.Error(codes.NotFound, err.Error())
-	case errors.Is(err, service.ErrRefreshTokenMismatch) || errors.Is(err, service.ErrRefreshTokenIsExpired):
+	case errors.Is(err, service.ErrInvalidClient):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedGrantType):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrRefreshTokenMismatch) || errors.Is(err, service.ErrRefreshTokenIsExpired) ||
+		errors.Is(err, service.ErrSessionIdleTimeout) || errors.Is(err, service.ErrSessionLifetimeExceeded) ||
+		errors.Is(err, service.ErrDPoPKeyMismatch) || errors.Is(err, service.ErrCertificateMismatch) ||
+		errors.Is(err, service.ErrRealmUserNotFound):
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	case err != nil:
 		return nil, status.Error(codes.Internal, err.Error())
@@ -53,8 +105,15 @@ func (a *Auth) RefreshTokens(ctx context.Context, request *authService.RefreshTo
 
 // GenerateTokens generate access and refresh tokens
 func (a *Auth) GenerateTokens(ctx context.Context, request *authService.GenerateTokensRequest) (*authService.GenerateTokensResponse, error) {
-	refreshToken, accessToken, err := a.auth.GenerateTokens(ctx, request.Username)
-	if err != nil {
+	refreshToken, accessToken, err := a.auth.GenerateTokens(ctx, request.Username, request.Scope)
+	switch {
+	case errors.Is(err, service.ErrInvalidClient):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedGrantType) || errors.Is(err, service.ErrNotTenantMember):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidScope):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
 		log.Error(err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -68,20 +127,33 @@ func (a *Auth) GenerateTokens(ctx context.Context, request *authService.Generate
 // SignUp sign up
 func (a *Auth) SignUp(ctx context.Context, request *authService.SignUpRequest) (*authService.SignUpResponse, error) {
 	err := a.auth.SignUp(ctx, request.Username, request.Password, request.Email)
+	if errors.Is(err, service.ErrInvalidUsername) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
+	if err = a.emailVerification.SendVerification(ctx, request.Username); err != nil {
+		log.Errorf("Auth / SignUp / SendVerification err %v ", err)
+	}
 
 	return &authService.SignUpResponse{}, nil
 }
 
 // SignIn sign in
 func (a *Auth) SignIn(ctx context.Context, request *authService.SignInRequest) (*authService.SignInResponse, error) {
-	refreshToken, accessToken, err := a.auth.SignIn(ctx, request.Username, request.Password)
-	if errors.Is(err, service.ErrInvalidPassword) {
+	refreshToken, accessToken, err := a.auth.SignIn(ctx, request.Username, request.Password, request.Scope)
+	switch {
+	case errors.Is(err, service.ErrInvalidPassword) || errors.Is(err, service.ErrInvalidScope):
 		return nil, status.Error(codes.InvalidArgument, err.Error())
-	} else if err != nil {
+	case errors.Is(err, service.ErrEmailNotVerified):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrInvalidClient):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedGrantType) || errors.Is(err, service.ErrNotTenantMember):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	return &authService.SignInResponse{
@@ -89,3 +161,135 @@ func (a *Auth) SignIn(ctx context.Context, request *authService.SignInRequest) (
 		RefreshToken: refreshToken,
 	}, nil
 }
+
+// RevokeAccessToken revoke access token before its expiration
+func (a *Auth) RevokeAccessToken(ctx context.Context, request *authService.RevokeAccessTokenRequest) (*authService.RevokeAccessTokenResponse, error) {
+	err := a.auth.RevokeAccessToken(ctx, request.AccessToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &authService.RevokeAccessTokenResponse{}, nil
+}
+
+// SignOut end current session
+func (a *Auth) SignOut(ctx context.Context, request *authService.SignOutRequest) (*authService.SignOutResponse, error) {
+	err := a.auth.SignOut(ctx, request.AccessToken, request.RevokeAccessToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &authService.SignOutResponse{}, nil
+}
+
+// SignOutEverywhere end all user sessions
+func (a *Auth) SignOutEverywhere(ctx context.Context, request *authService.SignOutEverywhereRequest) (*authService.SignOutEverywhereResponse, error) {
+	err := a.auth.SignOutEverywhere(ctx, request.AccessToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &authService.SignOutEverywhereResponse{}, nil
+}
+
+// ExchangeToken exchange token for a delegated or impersonated one, see RFC 8693
+func (a *Auth) ExchangeToken(ctx context.Context, request *authService.ExchangeTokenRequest) (*authService.ExchangeTokenResponse, error) {
+	result, err := a.tokenExchange.Exchange(ctx, &service.ExchangeRequest{
+		SubjectToken:       request.SubjectToken,
+		SubjectTokenType:   request.SubjectTokenType,
+		ActorToken:         request.ActorToken,
+		ActorTokenType:     request.ActorTokenType,
+		RequestedSubject:   request.RequestedSubject,
+		RequestedTokenType: request.RequestedTokenType,
+		Audience:           request.Audience,
+		Scope:              request.Scope,
+	})
+	switch {
+	case errors.Is(err, service.ErrExchangeNotAllowed) || errors.Is(err, service.ErrNotTenantMember):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &authService.ExchangeTokenResponse{
+		AccessToken:     result.AccessToken,
+		IssuedTokenType: result.IssuedTokenType,
+		TokenType:       "Bearer",
+		ExpiresIn:       result.ExpiresIn,
+		Scope:           result.Scope,
+	}, nil
+}
+
+// Authorize decide whether access token permits actions on resources.
+// Single check fields come first, batch checks follow in request order.
+func (a *Auth) Authorize(ctx context.Context, request *authService.AuthorizeRequest) (*authService.AuthorizeResponse, error) {
+	checks := make([]*model.AccessCheck, 0, len(request.Checks)+1)
+	if request.Action != "" {
+		checks = append(checks, &model.AccessCheck{
+			Action:             request.Action,
+			Resource:           request.Resource,
+			RequestAttributes:  request.RequestAttributes.AsMap(),
+			ResourceAttributes: request.ResourceAttributes.AsMap(),
+		})
+	}
+	for _, check := range request.Checks {
+		checks = append(checks, &model.AccessCheck{
+			Action:             check.Action,
+			Resource:           check.Resource,
+			RequestAttributes:  check.RequestAttributes.AsMap(),
+			ResourceAttributes: check.ResourceAttributes.AsMap(),
+		})
+	}
+	if len(checks) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no access checks")
+	}
+
+	decisions := a.authorizer.Authorize(ctx, request.AccessToken, checks)
+	response := &authService.AuthorizeResponse{
+		Allowed:   true,
+		Reason:    decisions[0].Reason,
+		Decisions: make([]*authService.Decision, 0, len(decisions)),
+	}
+	for _, decision := range decisions {
+		if !decision.Allowed && response.Allowed {
+			response.Allowed = false
+			response.Reason = decision.Reason
+		}
+		response.Decisions = append(response.Decisions, &authService.Decision{
+			Allowed: decision.Allowed,
+			Reason:  decision.Reason,
+		})
+	}
+
+	return response, nil
+}
+
+// StepUp re-authenticate current session and issue short-lived elevated access token
+func (a *Auth) StepUp(ctx context.Context, request *authService.StepUpRequest) (*authService.StepUpResponse, error) {
+	result, err := a.stepUp.StepUp(ctx, request.AccessToken, request.Method, request.Credential)
+	switch {
+	case errors.Is(err, service.ErrInvalidPassword) || errors.Is(err, service.ErrInvalidSecondFactor) ||
+		errors.Is(err, service.ErrUnsupportedAuthMethod):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrStepUpNotElevated):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrTooManyStepUpAttempts):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrSessionNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return &authService.StepUpResponse{
+		AccessToken: result.AccessToken,
+		ExpiresIn:   result.ExpiresIn,
+		Acr:         result.ACR,
+	}, nil
+}
+
+// isStatusError checks if err already carries gRPC status, e.g. one returned by userService
+func isStatusError(err error) bool {
+	_, ok := status.FromError(err)
+	return err != nil && ok
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Entetry/authService/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// CertificateInterceptor puts verified mutual TLS client certificate into context,
+// tokens are bound to it at issuance and checked against it when used
+func CertificateInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			ctx = service.WithClientCertificate(ctx, tlsInfo.State.VerifiedChains[0][0])
+		}
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Entetry/authService/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ClientMetadataKey grpc metadata key carrying client id, requests without it use the default client profile
+	ClientMetadataKey = "x-client-id"
+	// ClientSecretMetadataKey grpc metadata key carrying client secret, clients may use mutual TLS certificate instead
+	ClientSecretMetadataKey = "x-client-secret"
+)
+
+// ClientInterceptor authenticates request client and puts it into context,
+// client is checked against profiles when tokens are issued. Must run after CertificateInterceptor.
+func ClientInterceptor(auth *service.Auth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+		values := md.Get(ClientMetadataKey)
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+		var secret string
+		if secrets := md.Get(ClientSecretMetadataKey); len(secrets) > 0 {
+			secret = secrets[0]
+		}
+		if err := auth.AuthenticateClient(ctx, values[0], secret); err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(service.WithClient(ctx, values[0]), req)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Entetry/authService/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DPoPMetadataKey grpc metadata key carrying DPoP proof of RPCs, see RFC 9449
+const DPoPMetadataKey = "dpop"
+
+// DPoPInterceptor verifies DPoP proof of the request and puts the proven key into context,
+// tokens issued by any RPC are bound to it and bound tokens presented to RPCs are checked against it.
+// Requests without proof get bearer tokens.
+func DPoPInterceptor(dpop *service.DPoP) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+		proofs := md.Get(DPoPMetadataKey)
+		if len(proofs) == 0 {
+			return handler(ctx, req)
+		}
+		if len(proofs) > 1 {
+			return nil, status.Error(codes.InvalidArgument, service.ErrInvalidDPoPProof.Error())
+		}
+		var authority string
+		if values := md.Get(":authority"); len(values) > 0 {
+			authority = values[0]
+		}
+		ctx, err := dpop.WithProof(ctx, proofs[0], http.MethodPost, dpop.RPCURI(authority, info.FullMethod))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
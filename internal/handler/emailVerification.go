@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyEmail confirm user email using verification token
+func (a *Auth) VerifyEmail(ctx context.Context, request *authService.VerifyEmailRequest) (*authService.VerifyEmailResponse, error) {
+	err := a.emailVerification.VerifyEmail(ctx, request.Token)
+	switch {
+	case errors.Is(err, service.ErrInvalidVerificationToken):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrEmailVerificationDisabled):
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	case isStatusError(err):
+		return nil, err
+	case err != nil:
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &authService.VerifyEmailResponse{}, nil
+}
+
+// ResendVerification mail new verification token to user
+func (a *Auth) ResendVerification(ctx context.Context, request *authService.ResendVerificationRequest) (*authService.ResendVerificationResponse, error) {
+	err := a.emailVerification.SendVerification(ctx, request.Username)
+	switch {
+	case isStatusError(err):
+		return nil, err
+	case err != nil:
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &authService.ResendVerificationResponse{}, nil
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BeginFederatedLogin return upstream provider authorization URL
+func (a *Auth) BeginFederatedLogin(ctx context.Context, request *authService.BeginFederatedLoginRequest) (*authService.BeginFederatedLoginResponse, error) {
+	login, err := a.federation.BeginLogin(ctx, request.Provider, request.Scope)
+	if err != nil {
+		return nil, federationError(err)
+	}
+
+	return &authService.BeginFederatedLoginResponse{
+		AuthorizationUrl: login.AuthorizationURL,
+		State:            login.State,
+	}, nil
+}
+
+// CompleteFederatedLogin sign in with authorization code returned by upstream provider
+func (a *Auth) CompleteFederatedLogin(ctx context.Context, request *authService.CompleteFederatedLoginRequest) (*authService.CompleteFederatedLoginResponse, error) {
+	refreshToken, accessToken, err := a.federation.CompleteLogin(ctx, request.State, request.Code)
+	if err != nil {
+		return nil, federationError(err)
+	}
+
+	return &authService.CompleteFederatedLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func federationError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownProvider) || errors.Is(err, service.ErrInvalidFederationState) ||
+		errors.Is(err, service.ErrInvalidScope):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrCodeExchangeFailed) || errors.Is(err, service.ErrInvalidIDToken) ||
+		errors.Is(err, service.ErrInvalidUserInfo) || errors.Is(err, service.ErrInvalidClient):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedGrantType) || errors.Is(err, service.ErrNotTenantMember):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case isStatusError(err):
+		return err
+	default:
+		log.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StartPasswordless mail magic link or sign-in code to user
+func (a *Auth) StartPasswordless(ctx context.Context, request *authService.StartPasswordlessRequest) (*authService.StartPasswordlessResponse, error) {
+	err := a.passwordless.StartPasswordless(ctx, request.Username, request.Mode)
+	if err != nil {
+		return nil, passwordlessError(err)
+	}
+
+	return &authService.StartPasswordlessResponse{}, nil
+}
+
+// CompletePasswordless sign in with magic link token, or with username and code
+func (a *Auth) CompletePasswordless(ctx context.Context, request *authService.CompletePasswordlessRequest) (*authService.CompletePasswordlessResponse, error) {
+	var refreshToken, accessToken string
+	var err error
+	if request.Token != "" {
+		refreshToken, accessToken, err = a.passwordless.SignInWithLink(ctx, request.Token, request.Scope)
+	} else {
+		refreshToken, accessToken, err = a.passwordless.SignInWithCode(ctx, request.Username, request.Code, request.Scope)
+	}
+	if err != nil {
+		return nil, passwordlessError(err)
+	}
+
+	return &authService.CompletePasswordlessResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func passwordlessError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnsupportedPasswordlessMode) || errors.Is(err, service.ErrInvalidSignInCode) ||
+		errors.Is(err, service.ErrInvalidScope):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrInvalidClient):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedGrantType) || errors.Is(err, service.ErrNotTenantMember):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case isStatusError(err):
+		return err
+	default:
+		log.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PutRole create or replace role
+func (a *Auth) PutRole(ctx context.Context, request *authService.PutRoleRequest) (*authService.PutRoleResponse, error) {
+	if request.Role == nil {
+		return nil, status.Error(codes.InvalidArgument, service.ErrInvalidRole.Error())
+	}
+	err := a.rbac.PutRole(ctx, request.AccessToken, &model.Role{
+		Name:        request.Role.Name,
+		Permissions: request.Role.Permissions,
+		Inherits:    request.Role.Inherits,
+	})
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authService.PutRoleResponse{}, nil
+}
+
+// DeleteRole delete role
+func (a *Auth) DeleteRole(ctx context.Context, request *authService.DeleteRoleRequest) (*authService.DeleteRoleResponse, error) {
+	err := a.rbac.DeleteRole(ctx, request.AccessToken, request.Name)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authService.DeleteRoleResponse{}, nil
+}
+
+// ListRoles list roles
+func (a *Auth) ListRoles(ctx context.Context, request *authService.ListRolesRequest) (*authService.ListRolesResponse, error) {
+	roles, err := a.rbac.ListRoles(ctx, request.AccessToken)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+	response := &authService.ListRolesResponse{Roles: make([]*authService.Role, 0, len(roles))}
+	for _, role := range roles {
+		response.Roles = append(response.Roles, &authService.Role{
+			Name:        role.Name,
+			Permissions: role.Permissions,
+			Inherits:    role.Inherits,
+		})
+	}
+
+	return response, nil
+}
+
+// AssignRole assign role to user
+func (a *Auth) AssignRole(ctx context.Context, request *authService.AssignRoleRequest) (*authService.AssignRoleResponse, error) {
+	err := a.rbac.AssignRole(ctx, request.AccessToken, request.Username, request.Role)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authService.AssignRoleResponse{}, nil
+}
+
+// UnassignRole remove role from user
+func (a *Auth) UnassignRole(ctx context.Context, request *authService.UnassignRoleRequest) (*authService.UnassignRoleResponse, error) {
+	err := a.rbac.UnassignRole(ctx, request.AccessToken, request.Username, request.Role)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authService.UnassignRoleResponse{}, nil
+}
+
+// ListUserRoles list user roles and effective permissions
+func (a *Auth) ListUserRoles(ctx context.Context, request *authService.ListUserRolesRequest) (*authService.ListUserRolesResponse, error) {
+	roles, permissions, err := a.rbac.ListUserRoles(ctx, request.AccessToken, request.Username)
+	if err != nil {
+		return nil, rbacError(err)
+	}
+
+	return &authService.ListUserRolesResponse{
+		Roles:       roles,
+		Permissions: permissions,
+	}, nil
+}
+
+func rbacError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrRoleNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidRole) || errors.Is(err, service.ErrRoleCycle):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrRoleInUse):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	log.Error(err)
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetSAMLMetadata return service provider metadata to register with identity providers
+func (a *Auth) GetSAMLMetadata(_ context.Context, _ *authService.GetSAMLMetadataRequest) (*authService.GetSAMLMetadataResponse, error) {
+	metadata, err := a.saml.Metadata()
+	if err != nil {
+		return nil, samlError(err)
+	}
+
+	return &authService.GetSAMLMetadataResponse{Metadata: string(metadata)}, nil
+}
+
+// BeginSAMLLogin return identity provider SSO URL carrying authentication request
+func (a *Auth) BeginSAMLLogin(ctx context.Context, request *authService.BeginSAMLLoginRequest) (*authService.BeginSAMLLoginResponse, error) {
+	login, err := a.saml.BeginLogin(ctx, request.Provider, request.Scope)
+	if err != nil {
+		return nil, samlError(err)
+	}
+
+	return &authService.BeginSAMLLoginResponse{
+		RedirectUrl: login.RedirectURL,
+		RelayState:  login.RelayState,
+	}, nil
+}
+
+// CompleteSAMLLogin sign in with SAML response posted by identity provider
+func (a *Auth) CompleteSAMLLogin(ctx context.Context, request *authService.CompleteSAMLLoginRequest) (*authService.CompleteSAMLLoginResponse, error) {
+	refreshToken, accessToken, err := a.saml.CompleteLogin(ctx, request.SamlResponse, request.RelayState)
+	if err != nil {
+		return nil, samlError(err)
+	}
+
+	return &authService.CompleteSAMLLoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func samlError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownProvider) || errors.Is(err, service.ErrInvalidSAMLRelayState) ||
+		errors.Is(err, service.ErrInvalidScope):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrInvalidSAMLResponse) || errors.Is(err, service.ErrSAMLAssertionReplayed) ||
+		errors.Is(err, service.ErrInvalidClient):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUnsupportedSAMLBinding):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrUnauthorizedGrantType) || errors.Is(err, service.ErrNotTenantMember):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case isStatusError(err):
+		return err
+	default:
+		log.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Entetry/authService/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantMetadataKey grpc metadata key carrying tenant id, requests without it belong to the default tenant
+const TenantMetadataKey = "x-tenant-id"
+
+// TenantInterceptor puts request tenant into context and rejects unknown tenants
+func TenantInterceptor(auth *service.Auth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		var tenantID string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(TenantMetadataKey); len(values) > 0 {
+				tenantID = values[0]
+			}
+		}
+		if !auth.HasTenant(tenantID) {
+			return nil, status.Error(codes.InvalidArgument, service.ErrUnknownTenant.Error())
+		}
+		return handler(service.WithTenant(ctx, tenantID), req)
+	}
+}
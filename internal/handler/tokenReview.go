@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/service"
+	log "github.com/sirupsen/logrus"
+)
+
+// TokenReview API version and kind the webhook accepts and answers with
+const (
+	TokenReviewAPIVersion = "authentication.k8s.io/v1"
+	TokenReviewKind       = "TokenReview"
+)
+
+// ErrSenderConstrainedToken godoc
+var ErrSenderConstrainedToken = errors.New("sender-constrained tokens can't be reviewed")
+
+// maxTokenReviewSize limits request body, reviews only carry a token and audiences
+const maxTokenReviewSize = 1 << 20
+
+// TokenReview authentication.k8s.io/v1 TokenReview object
+type TokenReview struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Spec       TokenReviewSpec   `json:"spec"`
+	Status     TokenReviewStatus `json:"status"`
+}
+
+// TokenReviewSpec token to review and audiences it must be valid for, any of them is enough
+type TokenReviewSpec struct {
+	Token     string   `json:"token,omitempty"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// TokenReviewStatus review result, Audiences are the requested audiences the token is valid for
+type TokenReviewStatus struct {
+	Authenticated bool     `json:"authenticated"`
+	User          UserInfo `json:"user,omitempty"`
+	Audiences     []string `json:"audiences,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// UserInfo authenticated user as seen by Kubernetes
+type UserInfo struct {
+	Username string              `json:"username,omitempty"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// TokenReviewWebhook Kubernetes webhook token authenticator, see
+// https://kubernetes.io/docs/reference/access-authn-authz/authentication/#webhook-token-authentication
+type TokenReviewWebhook struct {
+	cfg  *config.TokenReviewConfig
+	auth *service.Auth
+}
+
+// NewTokenReviewWebhook creates new TokenReview webhook handler
+func NewTokenReviewWebhook(cfg *config.TokenReviewConfig, auth *service.Auth) *TokenReviewWebhook {
+	return &TokenReviewWebhook{cfg: cfg, auth: auth}
+}
+
+// ServeHTTP answers TokenReview with its status, the reviewed token is not echoed back.
+// Rejected tokens are reported in review status rather than by HTTP status.
+func (w *TokenReviewWebhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if !w.authorized(r) {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	review := &TokenReview{}
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxTokenReviewSize)).Decode(review); err != nil {
+		http.Error(rw, "malformed TokenReview", http.StatusBadRequest)
+		return
+	}
+	if review.APIVersion != TokenReviewAPIVersion || review.Kind != TokenReviewKind {
+		http.Error(rw, "unsupported TokenReview version", http.StatusBadRequest)
+		return
+	}
+	response := &TokenReview{APIVersion: TokenReviewAPIVersion, Kind: TokenReviewKind, Status: w.review(r, review.Spec)}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(response); err != nil {
+		log.Errorf("TokenReviewWebhook / ServeHTTP / Encode err %v ", err)
+	}
+}
+
+// authorized checks caller bearer token when cfg.BearerToken is set,
+// client certificates are verified by the webhook TLS listener
+func (w *TokenReviewWebhook) authorized(r *http.Request) bool {
+	if w.cfg.BearerToken == "" {
+		return true
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(w.cfg.BearerToken)) == 1
+}
+
+// review validates token for the first requested audience it is valid for, reviews without audiences need
+// tokens issued for cfg.Audience. Tokens bound to DPoP key or client certificate are rejected whatever
+// the audience, Kubernetes can't present their proof.
+func (w *TokenReviewWebhook) review(r *http.Request, spec TokenReviewSpec) TokenReviewStatus {
+	ctx := service.WithTenant(r.Context(), w.cfg.Tenant)
+	audiences := spec.Audiences
+	if len(audiences) == 0 {
+		audiences = []string{w.cfg.Audience}
+	}
+	var claims *service.Claim
+	var err error
+	for _, audience := range audiences {
+		claims, err = w.auth.ValidateToken(ctx, spec.Token, service.ValidateOptions{Audience: audience})
+		if err == nil && claims.Cnf != nil ||
+			errors.Is(err, service.ErrDPoPProofRequired) || errors.Is(err, service.ErrCertificateMismatch) {
+			err = ErrSenderConstrainedToken
+		}
+		if err == nil || errors.Is(err, ErrSenderConstrainedToken) {
+			break
+		}
+	}
+	if err != nil {
+		return TokenReviewStatus{Error: err.Error()}
+	}
+
+	status := TokenReviewStatus{
+		Authenticated: true,
+		User: UserInfo{
+			Username: w.cfg.UsernamePrefix + claims.Username,
+			UID:      claims.Subject,
+			Groups:   make([]string, 0, len(claims.Roles)),
+			Extra:    map[string][]string{"tenant": {claims.TenantID}},
+		},
+	}
+	for _, role := range claims.Roles {
+		status.User.Groups = append(status.User.Groups, w.cfg.GroupPrefix+role)
+	}
+	if claims.Scope != "" {
+		status.User.Extra["scopes"] = strings.Fields(claims.Scope)
+	}
+	if len(spec.Audiences) > 0 {
+		status.Audiences = []string{claims.Audience}
+	}
+	return status
+}
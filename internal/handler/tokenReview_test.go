@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mockUsername      = "test_user"
+	mockUserID        = "5d5a4f4a-3c2b-4e8f-9d1a-2b3c4d5e6f70"
+	mockAuthAudience  = "authService"
+	mockReviewAud     = "kubernetes"
+	mockOtherAudience = "orders-service"
+	mockTokenURI      = "https://auth.example.com/authService.AuthGRPCService/SignIn"
+)
+
+// tokenReviewFixture webhook in front of Auth service issuing tokens of mockUsername with roles admin and viewer
+type tokenReviewFixture struct {
+	webhook  *TokenReviewWebhook
+	auth     *service.Auth
+	exchange *service.TokenExchange
+	dpop     *service.DPoP
+}
+
+func newTokenReviewFixture(t *testing.T) *tokenReviewFixture {
+	cfg := &config.JwtConfig{
+		AccessTokenKey:         "test-access-token-key",
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Audience:               mockAuthAudience,
+	}
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername}, nil).Maybe()
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return().Maybe()
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false).Maybe()
+	mockTokenDenylist.On("IsSubjectRevoked", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+		Return(false).Maybe()
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"admin", "viewer"}).Maybe()
+	mockRoleStorage.On("LoadRole", "", mock.AnythingOfType("string")).Return(&model.Role{}, true).Maybe()
+	mockAuditLogger := mocks.NewAuditLogger(t)
+	mockAuditLogger.On("Emit", mock.AnythingOfType("*model.AuditEvent")).Return().Maybe()
+	auth, err := service.NewAuthService(cfg, mockSessionStorage, mockUserServiceClient, service.AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+		AuditLogger:   mockAuditLogger,
+	})
+	assert.NoError(t, err, "Expected no error when creating Auth service")
+	mockReplayCache := mocks.NewDPoPReplayCache(t)
+	mockReplayCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true).Maybe()
+	reviewCfg := &config.TokenReviewConfig{Audience: mockReviewAud, UsernamePrefix: "auth:", GroupPrefix: "auth:"}
+
+	return &tokenReviewFixture{
+		webhook: NewTokenReviewWebhook(reviewCfg, auth),
+		auth:    auth,
+		exchange: service.NewTokenExchangeService(&config.TokenExchangeConfig{
+			AllowedAudiences: []string{mockReviewAud, mockOtherAudience},
+			TokenExpiration:  5 * time.Minute,
+		}, auth),
+		dpop: service.NewDPoPService(&config.DPoPConfig{ProofLifetime: time.Minute, ClockSkew: 30 * time.Second},
+			mockReplayCache),
+	}
+}
+
+// accessToken issues access token of mockUsername for audience, empty audience keeps the auth service one
+func (f *tokenReviewFixture) accessToken(t *testing.T, ctx context.Context, audience string) string {
+	_, accessToken, err := f.auth.GenerateTokens(ctx, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	if audience == "" {
+		return accessToken
+	}
+	result, err := f.exchange.Exchange(context.Background(), &service.ExchangeRequest{
+		SubjectToken:     accessToken,
+		SubjectTokenType: service.TokenTypeAccessToken,
+		Audience:         audience,
+	})
+	assert.NoError(t, err, "Expected no error when exchanging token for %s", audience)
+	return result.AccessToken
+}
+
+// dpopContext returns context carrying verified DPoP proof of new key
+func (f *tokenReviewFixture) dpopContext(t *testing.T) context.Context {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err, "Expected no error when generating DPoP key")
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType("dpop+jwt"))
+	assert.NoError(t, err, "Expected no error when creating signer")
+	payload, err := json.Marshal(map[string]interface{}{
+		"jti": uuid.New().String(), "htm": http.MethodPost, "htu": mockTokenURI, "iat": time.Now().Unix(),
+	})
+	assert.NoError(t, err, "Expected no error when encoding proof claims")
+	signed, err := signer.Sign(payload)
+	assert.NoError(t, err, "Expected no error when signing proof")
+	proof, err := signed.CompactSerialize()
+	assert.NoError(t, err, "Expected no error when serializing proof")
+	ctx, err := f.dpop.WithProof(context.Background(), proof, http.MethodPost, mockTokenURI)
+	assert.NoError(t, err, "Expected no error when verifying proof")
+	return ctx
+}
+
+// postReview sends review to webhook and returns HTTP status and decoded response
+func (f *tokenReviewFixture) postReview(t *testing.T, review interface{}) (int, *TokenReview) {
+	body, err := json.Marshal(review)
+	assert.NoError(t, err, "Expected no error when encoding review")
+	recorder := httptest.NewRecorder()
+	f.webhook.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/tokenreview", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, nil
+	}
+	response := &TokenReview{}
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(response), "Expected TokenReview response")
+	return recorder.Code, response
+}
+
+func newTokenReview(token string, audiences ...string) *TokenReview {
+	return &TokenReview{APIVersion: TokenReviewAPIVersion, Kind: TokenReviewKind,
+		Spec: TokenReviewSpec{Token: token, Audiences: audiences}}
+}
+
+func TestTokenReviewWebhook_Version(t *testing.T) {
+	f := newTokenReviewFixture(t)
+	token := f.accessToken(t, context.Background(), mockReviewAud)
+
+	code, response := f.postReview(t, newTokenReview(token))
+	assert.Equal(t, http.StatusOK, code, "Expected review of supported version to be answered")
+	assert.Equal(t, TokenReviewAPIVersion, response.APIVersion, "Response version mismatch")
+	assert.Equal(t, TokenReviewKind, response.Kind, "Response kind mismatch")
+	assert.Empty(t, response.Spec.Token, "Expected reviewed token not to be echoed back")
+
+	t.Log("other versions and kinds are refused")
+	review := newTokenReview(token)
+	review.APIVersion = "authentication.k8s.io/v1beta1"
+	code, _ = f.postReview(t, review)
+	assert.Equal(t, http.StatusBadRequest, code, "Expected bad request for v1beta1 review")
+	review = newTokenReview(token)
+	review.Kind = "SubjectAccessReview"
+	code, _ = f.postReview(t, review)
+	assert.Equal(t, http.StatusBadRequest, code, "Expected bad request for other kind")
+
+	recorder := httptest.NewRecorder()
+	f.webhook.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/tokenreview", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code, "Expected GET to be refused")
+}
+
+func TestTokenReviewWebhook_Audiences(t *testing.T) {
+	f := newTokenReviewFixture(t)
+	reviewToken := f.accessToken(t, context.Background(), mockReviewAud)
+	otherToken := f.accessToken(t, context.Background(), mockOtherAudience)
+	authToken := f.accessToken(t, context.Background(), "")
+
+	t.Log("reviews without audiences need tokens issued for the configured audience")
+	_, response := f.postReview(t, newTokenReview(reviewToken))
+	assert.True(t, response.Status.Authenticated, "Expected token of review audience to be authenticated")
+	assert.Empty(t, response.Status.Audiences, "Expected no audiences without requested ones")
+	_, response = f.postReview(t, newTokenReview(authToken))
+	assert.False(t, response.Status.Authenticated, "Expected auth service token to be rejected")
+	assert.Equal(t, service.ErrInvalidTokenAudience.Error(), response.Status.Error, "Error mismatch")
+
+	t.Log("token valid for any requested audience is authenticated for it")
+	_, response = f.postReview(t, newTokenReview(otherToken, "billing-service", mockOtherAudience))
+	assert.True(t, response.Status.Authenticated, "Expected token of second audience to be authenticated")
+	assert.Equal(t, []string{mockOtherAudience}, response.Status.Audiences, "Audiences mismatch")
+	_, response = f.postReview(t, newTokenReview(otherToken, "billing-service", mockReviewAud))
+	assert.False(t, response.Status.Authenticated, "Expected token of other audience to be rejected")
+	assert.Equal(t, service.ErrInvalidTokenAudience.Error(), response.Status.Error, "Error mismatch")
+
+	t.Log("malformed token is rejected")
+	_, response = f.postReview(t, newTokenReview("not-a-token", mockReviewAud))
+	assert.False(t, response.Status.Authenticated, "Expected malformed token to be rejected")
+	assert.NotEmpty(t, response.Status.Error, "Expected error of malformed token")
+}
+
+func TestTokenReviewWebhook_SenderConstrainedTokens(t *testing.T) {
+	f := newTokenReviewFixture(t)
+
+	t.Log("DPoP bound token is rejected for every audience")
+	_, response := f.postReview(t, newTokenReview(f.accessToken(t, f.dpopContext(t), ""), mockReviewAud, mockAuthAudience))
+	assert.False(t, response.Status.Authenticated, "Expected DPoP bound token to be rejected")
+	assert.Equal(t, ErrSenderConstrainedToken.Error(), response.Status.Error, "Error mismatch")
+
+	t.Log("certificate bound token is rejected")
+	ctx := service.WithClientCertificate(context.Background(), &x509.Certificate{Raw: []byte("client certificate")})
+	_, response = f.postReview(t, newTokenReview(f.accessToken(t, ctx, ""), mockAuthAudience))
+	assert.False(t, response.Status.Authenticated, "Expected certificate bound token to be rejected")
+	assert.Equal(t, ErrSenderConstrainedToken.Error(), response.Status.Error, "Error mismatch")
+
+	t.Log("bearer token is accepted")
+	_, response = f.postReview(t, newTokenReview(f.accessToken(t, context.Background(), ""), mockAuthAudience))
+	assert.True(t, response.Status.Authenticated, "Expected bearer token to be authenticated")
+}
+
+func TestTokenReviewWebhook_User(t *testing.T) {
+	f := newTokenReviewFixture(t)
+	_, response := f.postReview(t, newTokenReview(f.accessToken(t, context.Background(), mockReviewAud)))
+
+	assert.True(t, response.Status.Authenticated, "Expected token to be authenticated")
+	assert.Empty(t, response.Status.Error, "Expected no error")
+	assert.Equal(t, "auth:"+mockUsername, response.Status.User.Username, "Username mismatch")
+	assert.Equal(t, mockUserID, response.Status.User.UID, "UID mismatch")
+	assert.Equal(t, []string{"auth:admin", "auth:viewer"}, response.Status.User.Groups, "Expected roles as prefixed groups")
+	assert.Equal(t, []string{""}, response.Status.User.Extra["tenant"], "Tenant mismatch")
+	assert.NotContains(t, response.Status.User.Extra, "scopes", "Expected no scopes of token without scope")
+}
+
+func TestTokenReviewWebhook_BearerToken(t *testing.T) {
+	f := newTokenReviewFixture(t)
+	f.webhook.cfg.BearerToken = "webhook-secret"
+	body, err := json.Marshal(newTokenReview(f.accessToken(t, context.Background(), mockReviewAud)))
+	assert.NoError(t, err, "Expected no error when encoding review")
+	post := func(authorization string) int {
+		request := httptest.NewRequest(http.MethodPost, "/tokenreview", bytes.NewReader(body))
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		f.webhook.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	t.Log("caller without configured bearer token is refused")
+	assert.Equal(t, http.StatusUnauthorized, post(""), "Expected unauthorized without bearer token")
+	assert.Equal(t, http.StatusUnauthorized, post("Bearer other-secret"), "Expected unauthorized with other token")
+	assert.Equal(t, http.StatusUnauthorized, post("webhook-secret"), "Expected unauthorized without Bearer scheme")
+
+	t.Log("caller with configured bearer token gets review")
+	assert.Equal(t, http.StatusOK, post("Bearer webhook-secret"), "Expected review with bearer token")
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/service"
+	"github.com/Entetry/authService/protocol/authService"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EnrollTOTP generate authenticator app secret, it is stored once ActivateTOTP confirms a code
+func (a *Auth) EnrollTOTP(ctx context.Context, request *authService.EnrollTOTPRequest) (*authService.EnrollTOTPResponse, error) {
+	enrollment, err := a.totp.Enroll(ctx, request.AccessToken)
+	if err != nil {
+		return nil, totpError(err)
+	}
+
+	return &authService.EnrollTOTPResponse{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
+}
+
+// ActivateTOTP store authenticator app secret once code generated by the app matches it
+func (a *Auth) ActivateTOTP(ctx context.Context, request *authService.ActivateTOTPRequest) (*authService.ActivateTOTPResponse, error) {
+	err := a.totp.Activate(ctx, request.AccessToken, request.Secret, request.Code)
+	if err != nil {
+		return nil, totpError(err)
+	}
+
+	return &authService.ActivateTOTPResponse{}, nil
+}
+
+func totpError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidTOTPSecret) || errors.Is(err, service.ErrInvalidSecondFactor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrTOTPAlreadyEnrolled):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrAuthenticationTooOld):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case isStatusError(err):
+		return err
+	default:
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+// APIKey long-lived personal access token, stored by SHA-256 hash of the secret.
+// Zero ExpiresAt never expires, empty AllowedCIDRs accepts the key from any address.
+type APIKey struct {
+	ID           string
+	Hash         string
+	TenantID     string
+	UserID       string
+	Username     string
+	Name         string
+	Scope        string
+	AllowedCIDRs []string
+	CreatedAt    int64
+	ExpiresAt    int64
+	LastUsedAt   int64
+}
@@ -0,0 +1,7 @@
+package model
+
+// Attempts failed attempts counted within fixed window ending at ExpiresAt
+type Attempts struct {
+	Count     int
+	ExpiresAt int64
+}
@@ -0,0 +1,21 @@
+package model
+
+// Audit event types
+const (
+	AuditEventSignOut           = "sign_out"
+	AuditEventSignOutEverywhere = "sign_out_everywhere"
+	AuditEventPasswordReset     = "password_reset"
+	AuditEventPasswordChange    = "password_change"
+	AuditEventEmailVerified     = "email_verified"
+	AuditEventAPIKeyCreated     = "api_key_created"
+	AuditEventAPIKeyRevoked     = "api_key_revoked"
+)
+
+// AuditEvent security relevant event struct
+type AuditEvent struct {
+	Type      string
+	TenantID  string
+	Username  string
+	SessionID string
+	Time      int64
+}
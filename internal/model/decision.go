@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// AccessCheck action on resource to authorize
+type AccessCheck struct {
+	Action             string
+	Resource           string
+	RequestAttributes  map[string]interface{}
+	ResourceAttributes map[string]interface{}
+}
+
+// Decision authorization decision with reason
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// PolicyInput attributes available to policy conditions
+type PolicyInput struct {
+	Token              map[string]interface{}
+	Action             string
+	Resource           string
+	RequestAttributes  map[string]interface{}
+	ResourceAttributes map[string]interface{}
+	Now                time.Time
+}
@@ -0,0 +1,12 @@
+package model
+
+// FederationState pending upstream login, stored by state hash until provider redirects back
+type FederationState struct {
+	ProviderID   string
+	TenantID     string
+	ClientID     string
+	Scope        string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    int64
+}
@@ -0,0 +1,10 @@
+package model
+
+// Identity user authenticated by a credential realm. Realm is empty for userService users,
+// Roles are granted by the realm in addition to tenant role assignments.
+type Identity struct {
+	UserID   string
+	Username string
+	Realm    string
+	Roles    []string
+}
@@ -0,0 +1,8 @@
+package model
+
+// Message notification sent to user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
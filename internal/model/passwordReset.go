@@ -0,0 +1,8 @@
+package model
+
+// PasswordReset pending password reset, stored by reset token hash
+type PasswordReset struct {
+	UserID    string
+	Username  string
+	ExpiresAt int64
+}
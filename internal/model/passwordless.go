@@ -0,0 +1,10 @@
+package model
+
+// PasswordlessChallenge pending passwordless sign-in, stored by magic link token hash or by username for codes
+type PasswordlessChallenge struct {
+	TenantID  string
+	Username  string
+	CodeHash  string
+	Attempts  int
+	ExpiresAt int64
+}
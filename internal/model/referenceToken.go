@@ -0,0 +1,8 @@
+package model
+
+// ReferenceToken signed access token kept server side, clients only hold an opaque handle stored by its hash
+type ReferenceToken struct {
+	TenantID    string
+	AccessToken string
+	ExpiresAt   int64
+}
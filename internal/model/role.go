@@ -0,0 +1,8 @@
+package model
+
+// Role named set of permissions, inherits permissions of parent roles
+type Role struct {
+	Name        string
+	Permissions []string
+	Inherits    []string
+}
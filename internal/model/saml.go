@@ -0,0 +1,12 @@
+package model
+
+// SAMLRequest pending SP-initiated SAML login, stored by relay state hash until IdP posts the response back.
+// RequestID is the AuthnRequest ID the assertion must be issued in response to.
+type SAMLRequest struct {
+	ProviderID string
+	TenantID   string
+	ClientID   string
+	Scope      string
+	RequestID  string
+	ExpiresAt  int64
+}
@@ -1,9 +1,23 @@
 // Package model provides domain models
 package model
 
-// Session refresh session token struct
+// Session refresh session token struct, RealmRoles are granted by external credential realm and resolved again on refresh.
+// JKT is the thumbprint of the DPoP key session tokens are bound to, X5T the one of mutual TLS client certificate.
 type Session struct {
+	ID           string
+	TenantID     string
+	ClientID     string
 	RefreshToken string
 	Username     string
+	UserID       string
+	Realm        string
+	RealmRoles   []string
+	Scope        string
+	AuthMethods  []string
+	ACR          string
+	AuthTime     int64
+	RefreshedAt  int64
 	ExpiresAt    int64
+	JKT          string
+	X5T          string
 }
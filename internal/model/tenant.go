@@ -0,0 +1,6 @@
+package model
+
+// TenantKey scopes key to tenant, same username or role name may exist in several tenants
+func TenantKey(tenantID, key string) string {
+	return tenantID + "/" + key
+}
@@ -0,0 +1,7 @@
+package model
+
+// TOTPSecret authenticator app secret of user, LastStep is the latest accepted time step so codes can't be replayed
+type TOTPSecret struct {
+	Secret   string `json:"secret"`
+	LastStep int64  `json:"lastStep"`
+}
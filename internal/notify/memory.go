@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Entetry/authService/internal/model"
+)
+
+// MemoryMailer keeps sent messages in memory, used in tests and local development
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []*model.Message
+}
+
+// NewMemoryMailer creates new MemoryMailer
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send records message
+func (m *MemoryMailer) Send(_ context.Context, message *model.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns messages sent to recipient in send order
+func (m *MemoryMailer) Messages(to string) []*model.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := make([]*model.Message, 0)
+	for _, message := range m.messages {
+		if message.To == to {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
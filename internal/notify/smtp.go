@@ -0,0 +1,48 @@
+// Package notify contains user notification senders
+package notify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+)
+
+// SMTPMailer sends messages as plain text emails through SMTP server
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates new SMTPMailer, credentials are optional
+func NewSMTPMailer(cfg *config.SMTPConfig) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from: cfg.From,
+	}
+	if cfg.Username != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return mailer
+}
+
+// Send sends message, ctx is not used as net/smtp does not support cancellation
+func (m *SMTPMailer) Send(_ context.Context, message *model.Message) error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return errors.New("invalid message header")
+	}
+	body := strings.ReplaceAll(message.Body, "\n", "\r\n")
+	msg := "From: " + m.from + "\r\n" +
+		"To: " + message.To + "\r\n" +
+		"Subject: " + message.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body + "\r\n"
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{message.To}, []byte(msg))
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer accepts one SMTP session and sends received DATA to channel
+func fakeSMTPServer(t *testing.T) (port int, data <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, "Expected no error when listening")
+	t.Cleanup(func() { _ = listener.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var body strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- body.String()
+					reply("250 OK")
+					continue
+				}
+				body.WriteString(line)
+				continue
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+// TestSMTPMailerSend tests that message is delivered to SMTP server
+func TestSMTPMailerSend(t *testing.T) {
+	port, data := fakeSMTPServer(t)
+	mailer := NewSMTPMailer(&config.SMTPConfig{Host: "127.0.0.1", Port: port, From: "no-reply@example.com"})
+
+	t.Log("Send the message")
+	err := mailer.Send(context.Background(), &model.Message{To: "user@example.com", Subject: "Hello", Body: "line one\nline two"})
+	assert.NoError(t, err, "Expected no error when sending message")
+
+	t.Log("Verify that the SMTP server received the message")
+	received := <-data
+	assert.Contains(t, received, "To: user@example.com\r\n", "Recipient header mismatch")
+	assert.Contains(t, received, "Subject: Hello\r\n", "Subject header mismatch")
+	assert.Contains(t, received, "line one\r\nline two", "Body mismatch")
+}
+
+// TestSMTPMailerSend_HeaderInjection tests that header injection is rejected
+func TestSMTPMailerSend_HeaderInjection(t *testing.T) {
+	mailer := NewSMTPMailer(&config.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "no-reply@example.com"})
+
+	err := mailer.Send(context.Background(), &model.Message{To: "user@example.com\r\nBcc: other@example.com", Subject: "Hello"})
+
+	assert.Error(t, err, "Expected error for recipient with line break")
+}
+
+// TestMemoryMailerSend tests that MemoryMailer records messages per recipient
+func TestMemoryMailerSend(t *testing.T) {
+	mailer := NewMemoryMailer()
+	message := &model.Message{To: "user@example.com", Subject: "Hello"}
+
+	err := mailer.Send(context.Background(), message)
+
+	assert.NoError(t, err, "Expected no error when sending message")
+	assert.Equal(t, []*model.Message{message}, mailer.Messages("user@example.com"), "Recorded messages mismatch")
+	assert.Empty(t, mailer.Messages("other@example.com"), "Unexpected messages for other recipient")
+}
@@ -0,0 +1,235 @@
+// Package paseto implements PASETO v4 local and public tokens and PASERK key identifiers
+package paseto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// Token headers
+const (
+	HeaderLocal  = "v4.local."
+	HeaderPublic = "v4.public."
+)
+
+const (
+	nonceSize = 32
+	macSize   = 32
+	// KeySize size of v4.local symmetric key
+	KeySize = 32
+)
+
+var (
+	// ErrInvalidToken godoc
+	ErrInvalidToken = errors.New("invalid paseto token")
+	// ErrInvalidKey godoc
+	ErrInvalidKey = errors.New("invalid paseto key")
+)
+
+// Encrypt returns v4.local token carrying message encrypted with key. Footer is authenticated but readable,
+// implicit assertion is authenticated without being part of the token.
+func Encrypt(key, message, footer, implicit []byte) (string, error) {
+	if len(key) != KeySize {
+		return "", ErrInvalidKey
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return encrypt(key, nonce, message, footer, implicit)
+}
+
+func encrypt(key, nonce, message, footer, implicit []byte) (string, error) {
+	encryptionKey, counterNonce, authKey, err := splitKey(key, nonce)
+	if err != nil {
+		return "", err
+	}
+	cipher, err := chacha20.NewUnauthenticatedCipher(encryptionKey, counterNonce)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := make([]byte, len(message))
+	cipher.XORKeyStream(ciphertext, message)
+	tag, err := mac(authKey, pae([]byte(HeaderLocal), nonce, ciphertext, footer, implicit))
+	if err != nil {
+		return "", err
+	}
+
+	payload := make([]byte, 0, nonceSize+len(ciphertext)+macSize)
+	payload = append(append(append(payload, nonce...), ciphertext...), tag...)
+	return encode(HeaderLocal, payload, footer), nil
+}
+
+// Decrypt authenticates v4.local token with key and implicit assertion and returns its message and footer
+func Decrypt(key []byte, token string, implicit []byte) (message, footer []byte, err error) {
+	if len(key) != KeySize {
+		return nil, nil, ErrInvalidKey
+	}
+	payload, footer, err := decode(HeaderLocal, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(payload) < nonceSize+macSize {
+		return nil, nil, ErrInvalidToken
+	}
+	nonce := payload[:nonceSize]
+	ciphertext := payload[nonceSize : len(payload)-macSize]
+	tag := payload[len(payload)-macSize:]
+	encryptionKey, counterNonce, authKey, err := splitKey(key, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	expected, err := mac(authKey, pae([]byte(HeaderLocal), nonce, ciphertext, footer, implicit))
+	if err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare(tag, expected) != 1 {
+		return nil, nil, ErrInvalidToken
+	}
+	cipher, err := chacha20.NewUnauthenticatedCipher(encryptionKey, counterNonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	message = make([]byte, len(ciphertext))
+	cipher.XORKeyStream(message, ciphertext)
+
+	return message, footer, nil
+}
+
+// Sign returns v4.public token carrying message signed with key.
+// Footer and implicit assertion are covered by the signature, only footer is part of the token.
+func Sign(key ed25519.PrivateKey, message, footer, implicit []byte) (string, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return "", ErrInvalidKey
+	}
+	signature := ed25519.Sign(key, pae([]byte(HeaderPublic), message, footer, implicit))
+	payload := make([]byte, 0, len(message)+ed25519.SignatureSize)
+	payload = append(append(payload, message...), signature...)
+	return encode(HeaderPublic, payload, footer), nil
+}
+
+// Verify checks v4.public token signature with key and implicit assertion and returns its message and footer
+func Verify(key ed25519.PublicKey, token string, implicit []byte) (message, footer []byte, err error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, nil, ErrInvalidKey
+	}
+	payload, footer, err := decode(HeaderPublic, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(payload) < ed25519.SignatureSize {
+		return nil, nil, ErrInvalidToken
+	}
+	message = payload[:len(payload)-ed25519.SignatureSize]
+	signature := payload[len(payload)-ed25519.SignatureSize:]
+	if !ed25519.Verify(key, pae([]byte(HeaderPublic), message, footer, implicit), signature) {
+		return nil, nil, ErrInvalidToken
+	}
+
+	return message, footer, nil
+}
+
+// Footer returns unverified token footer, so the verification key can be picked by its id
+func Footer(token string) ([]byte, error) {
+	for _, header := range []string{HeaderLocal, HeaderPublic} {
+		if strings.HasPrefix(token, header) {
+			_, footer, err := decode(header, token)
+			return footer, err
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// LocalKeyID returns PASERK k4.lid identifier of symmetric key
+func LocalKeyID(key []byte) string {
+	return keyID("k4.lid.", "k4.local."+base64.RawURLEncoding.EncodeToString(key))
+}
+
+// PublicKeyID returns PASERK k4.pid identifier of public key
+func PublicKeyID(key ed25519.PublicKey) string {
+	return keyID("k4.pid.", "k4.public."+base64.RawURLEncoding.EncodeToString(key))
+}
+
+func keyID(header, paserk string) string {
+	hash, _ := blake2b.New(33, nil)
+	hash.Write([]byte(header + paserk))
+	return header + base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// splitKey derives encryption key, XChaCha20 nonce and authentication key from key and token nonce
+func splitKey(key, nonce []byte) (encryptionKey, counterNonce, authKey []byte, err error) {
+	hash, err := blake2b.New(56, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hash.Write([]byte("paseto-encryption-key"))
+	hash.Write(nonce)
+	derived := hash.Sum(nil)
+
+	authKey, err = mac(key, append([]byte("paseto-auth-key-for-aead"), nonce...))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return derived[:32], derived[32:], authKey, nil
+}
+
+// mac returns 32 byte keyed BLAKE2b of message
+func mac(key, message []byte) ([]byte, error) {
+	hash, err := blake2b.New(macSize, key)
+	if err != nil {
+		return nil, err
+	}
+	hash.Write(message)
+	return hash.Sum(nil), nil
+}
+
+// pae pre-authentication encoding, every piece is prefixed with its little endian length
+func pae(pieces ...[]byte) []byte {
+	size := 8
+	for _, piece := range pieces {
+		size += 8 + len(piece)
+	}
+	out := make([]byte, 0, size)
+	out = binary.LittleEndian.AppendUint64(out, uint64(len(pieces)))
+	for _, piece := range pieces {
+		out = binary.LittleEndian.AppendUint64(out, uint64(len(piece)))
+		out = append(out, piece...)
+	}
+	return out
+}
+
+func encode(header string, payload, footer []byte) string {
+	token := header + base64.RawURLEncoding.EncodeToString(payload)
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token
+}
+
+func decode(header, token string) (payload, footer []byte, err error) {
+	if !strings.HasPrefix(token, header) {
+		return nil, nil, ErrInvalidToken
+	}
+	parts := strings.Split(token[len(header):], ".")
+	if len(parts) > 2 {
+		return nil, nil, ErrInvalidToken
+	}
+	payload, err = base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	if len(parts) == 2 {
+		if footer, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil || len(footer) == 0 {
+			return nil, nil, ErrInvalidToken
+		}
+	}
+	return payload, footer, nil
+}
@@ -0,0 +1,196 @@
+package paseto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	vectorKey         = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+	vectorNonce       = "df654812bac492663825520ba2f6e67cf5ca5bdc13d4e7507a98cc4c2fcc3ad8"
+	vectorSecret      = `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`
+	vectorHidden      = `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`
+	vectorFooter      = `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+	vectorFooterNoKID = "arbitrary-string-that-isn't-json"
+	vectorZeroNonce   = "0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+func TestPAE(t *testing.T) {
+	assert.Equal(t, []byte("\x00\x00\x00\x00\x00\x00\x00\x00"), pae(), "PAE of no pieces mismatch")
+	assert.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), pae([]byte("")),
+		"PAE of empty piece mismatch")
+	assert.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"), pae([]byte("test")),
+		"PAE of single piece mismatch")
+}
+
+// TestEncrypt_Vectors checks v4.local against test vectors 4-E-1 to 4-E-9 of the specification
+func TestEncrypt_Vectors(t *testing.T) {
+	key, err := hex.DecodeString(vectorKey)
+	assert.NoError(t, err, "Expected hex encoded key")
+	for _, vector := range []struct {
+		name     string
+		nonce    string
+		message  string
+		footer   string
+		implicit string
+		token    string
+	}{
+		{"4-E-1", vectorZeroNonce, vectorSecret, "", "",
+			"v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOA" +
+				"bY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg"},
+		{"4-E-2", vectorZeroNonce, vectorHidden, "", "",
+			"v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOA" +
+				"bY9V7WU6abu74MmcUE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A"},
+		{"4-E-3", vectorNonce, vectorSecret, "", "",
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t6-tyebyWG6Ov7kKvBdkrrAJ837lKP3iDag2hzUPHuMKA"},
+		{"4-E-4", vectorNonce, vectorHidden, "", "",
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t4gt6TiLm55vIH8c_lGxxZpE3AWlH4WTR0v45nsWoU3gQ"},
+		{"4-E-5", vectorNonce, vectorSecret, vectorFooter, "",
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t4x-RMNXtQNbz7FvFZ_G-lFpk5RG3EOrwDL6CgDqcerSQ." +
+				"eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"},
+		{"4-E-6", vectorNonce, vectorHidden, vectorFooter, "",
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t6pWSA5HX2wjb3P-xLQg5K5feUCX4P2fpVK3ZLWFbMSxQ." +
+				"eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"},
+		{"4-E-7", vectorNonce, vectorSecret, vectorFooter, `{"test-vector":"4-E-7"}`,
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t40KCCWLA7GYL9KFHzKlwY9_RnIfRrMQpueydLEAZGGcA." +
+				"eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"},
+		{"4-E-8", vectorNonce, vectorHidden, vectorFooter, `{"test-vector":"4-E-8"}`,
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t5uvqQbMGlLLNYBc7A6_x7oqnpUK5WLvj24eE4DVPDZjw." +
+				"eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"},
+		{"4-E-9", vectorNonce, vectorHidden, vectorFooterNoKID, `{"test-vector":"4-E-9"}`,
+			"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2m" +
+				"RvE2IcweP-PRdoHjd5-RHCiExR1IK6t6tybdlmnMwcDMw0YxA_gFSE_IUWl78aMtOepFYSWYfQA.YXJiaXRyYXJ5LXN0cmluZy10aGF0LWlzbid0LWpzb24"},
+	} {
+		nonce, err := hex.DecodeString(vector.nonce)
+		assert.NoError(t, err, "Expected hex encoded nonce of %s", vector.name)
+		footer, implicit := []byte(vector.footer), []byte(vector.implicit)
+
+		token, err := encrypt(key, nonce, []byte(vector.message), footer, implicit)
+		assert.NoError(t, err, "Expected no error when encrypting %s", vector.name)
+		assert.Equal(t, vector.token, token, "Token of %s mismatch", vector.name)
+
+		message, decryptedFooter, err := Decrypt(key, vector.token, implicit)
+		assert.NoError(t, err, "Expected no error when decrypting %s", vector.name)
+		assert.Equal(t, vector.message, string(message), "Message of %s mismatch", vector.name)
+		assert.Equal(t, vector.footer, string(decryptedFooter), "Footer of %s mismatch", vector.name)
+	}
+}
+
+// TestSign_Vector checks v4.public against test vector 4-S-1 of the specification
+func TestSign_Vector(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err, "Expected hex encoded secret key")
+	message := `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+	expected := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+
+	token, err := Sign(secretKey, []byte(message), nil, nil)
+	assert.NoError(t, err, "Expected no error when signing")
+	assert.Equal(t, expected, token, "Token of 4-S-1 mismatch")
+
+	verified, footer, err := Verify(ed25519.PrivateKey(secretKey).Public().(ed25519.PublicKey), token, nil)
+	assert.NoError(t, err, "Expected no error when verifying")
+	assert.Equal(t, message, string(verified), "Message of 4-S-1 mismatch")
+	assert.Empty(t, footer, "Expected no footer")
+}
+
+func TestSignVerify(t *testing.T) {
+	publicKey, secretKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err, "Expected no error when generating key")
+	footer := []byte(`{"kid":"` + PublicKeyID(publicKey) + `"}`)
+
+	token, err := Sign(secretKey, []byte("message"), footer, []byte("acme"))
+	assert.NoError(t, err, "Expected no error when signing")
+	assert.True(t, strings.HasPrefix(token, HeaderPublic), "Expected v4.public token")
+
+	t.Log("footer can be read before verification")
+	unverified, err := Footer(token)
+	assert.NoError(t, err, "Expected no error when reading footer")
+	assert.Equal(t, footer, unverified, "Unverified footer mismatch")
+
+	message, verifiedFooter, err := Verify(publicKey, token, []byte("acme"))
+	assert.NoError(t, err, "Expected no error when verifying")
+	assert.Equal(t, "message", string(message), "Message mismatch")
+	assert.Equal(t, footer, verifiedFooter, "Verified footer mismatch")
+
+	t.Log("implicit assertion must match")
+	_, _, err = Verify(publicKey, token, []byte("globex"))
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expected ErrInvalidToken for other implicit assertion")
+
+	t.Log("footer is covered by the signature")
+	_, _, err = Verify(publicKey, token[:strings.LastIndex(token, ".")], []byte("acme"))
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expected ErrInvalidToken for stripped footer")
+
+	t.Log("local token is not accepted as public one")
+	_, _, err = Verify(publicKey, HeaderLocal+strings.TrimPrefix(token, HeaderPublic), []byte("acme"))
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expected ErrInvalidToken for local header")
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	assert.NoError(t, err, "Expected no error when generating key")
+	footer := []byte(`{"kid":"` + LocalKeyID(key) + `"}`)
+
+	token, err := Encrypt(key, []byte("secret message"), footer, []byte("acme"))
+	assert.NoError(t, err, "Expected no error when encrypting")
+	assert.True(t, strings.HasPrefix(token, HeaderLocal), "Expected v4.local token")
+	assert.NotContains(t, token, "secret", "Expected message to be encrypted")
+
+	message, decryptedFooter, err := Decrypt(key, token, []byte("acme"))
+	assert.NoError(t, err, "Expected no error when decrypting")
+	assert.Equal(t, "secret message", string(message), "Message mismatch")
+	assert.Equal(t, footer, decryptedFooter, "Footer mismatch")
+
+	t.Log("every token uses a fresh nonce")
+	other, err := Encrypt(key, []byte("secret message"), footer, []byte("acme"))
+	assert.NoError(t, err, "Expected no error when encrypting again")
+	assert.NotEqual(t, token, other, "Expected different tokens for same message")
+
+	t.Log("implicit assertion must match")
+	_, _, err = Decrypt(key, token, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expected ErrInvalidToken for missing implicit assertion")
+
+	t.Log("modified ciphertext is rejected")
+	tampered := []byte(token)
+	index := len(HeaderLocal) + 50
+	if tampered[index] == 'A' {
+		tampered[index] = 'B'
+	} else {
+		tampered[index] = 'A'
+	}
+	_, _, err = Decrypt(key, string(tampered), []byte("acme"))
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expected ErrInvalidToken for modified ciphertext")
+
+	t.Log("other key is rejected")
+	otherKey := make([]byte, KeySize)
+	_, _, err = Decrypt(otherKey, token, []byte("acme"))
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expected ErrInvalidToken for other key")
+	_, _, err = Decrypt(key[:16], token, []byte("acme"))
+	assert.ErrorIs(t, err, ErrInvalidKey, "Expected ErrInvalidKey for short key")
+}
+
+// TestKeyID checks PASERK identifiers against k4.lid and k4.pid test vectors
+func TestKeyID(t *testing.T) {
+	zero := make([]byte, KeySize)
+	key, err := hex.DecodeString(vectorKey)
+	assert.NoError(t, err, "Expected hex encoded key")
+
+	assert.Equal(t, "k4.lid.bqltbNc4JLUAmc9Xtpok-fBuI0dQN5_m3CD9W_nbh559", LocalKeyID(zero), "k4.lid of zero key mismatch")
+	assert.Equal(t, "k4.lid.iVtYQDjr5gEijCSjJC3fQaJm7nCeQSeaty0Jixy8dbsk", LocalKeyID(key), "k4.lid of test key mismatch")
+	assert.Equal(t, "k4.pid.S_XQmeEwHbbvRmiyfXfHYpLGjXGzjTRSDoT1YtTakWFE", PublicKeyID(zero), "k4.pid of zero key mismatch")
+	assert.Equal(t, "k4.pid.9ShR3xc8-qVJ_di0tc9nx0IDIqbatdeM2mqLFBJsKRHs", PublicKeyID(key), "k4.pid of test key mismatch")
+	assert.NotEqual(t, LocalKeyID(zero), LocalKeyID(append([]byte{1}, zero[1:]...)), "Expected different ids for different keys")
+}
@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Engine policy engine with hot reloadable policy set
+type Engine struct {
+	dir         string
+	mu          sync.RWMutex
+	set         *Set
+	fingerprint string
+	revision    uint64
+}
+
+// NewEngine creates policy engine and loads policies of dir, empty dir disables policies
+func NewEngine(dir string) (*Engine, error) {
+	e := &Engine{dir: dir, set: &Set{}}
+	if dir == "" {
+		return e, nil
+	}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Evaluate evaluates input against currently loaded policies
+func (e *Engine) Evaluate(input *model.PolicyInput) (decision *model.Decision, matched bool) {
+	e.mu.RLock()
+	set := e.set
+	e.mu.RUnlock()
+	return set.Evaluate(input)
+}
+
+// Revision returns number of policy set reloads, decisions cached under other revision may be stale
+func (e *Engine) Revision() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.revision
+}
+
+// Reload loads policies of dir, current policies are kept when any policy fails validation
+func (e *Engine) Reload() error {
+	fingerprint, err := dirFingerprint(e.dir)
+	if err != nil {
+		return err
+	}
+	set, err := LoadDir(e.dir)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.set = set
+	e.fingerprint = fingerprint
+	e.revision++
+	e.mu.Unlock()
+	log.Infof("Engine / Reload / loaded policies %v", set.Names())
+	return nil
+}
+
+// RunReload periodically reloads policies when policy files change until ctx is done
+func (e *Engine) RunReload(ctx context.Context, interval time.Duration) {
+	if e.dir == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fingerprint, err := dirFingerprint(e.dir)
+			if err != nil {
+				log.Errorf("Engine / RunReload / dirFingerprint error %v", err)
+				continue
+			}
+			e.mu.RLock()
+			changed := fingerprint != e.fingerprint
+			e.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err = e.Reload(); err != nil {
+				log.Errorf("Engine / RunReload / keeping previous policies, reload error %v", err)
+			}
+		}
+	}
+}
+
+// dirFingerprint summarizes names, sizes and modification times of policy files
+func dirFingerprint(dir string) (string, error) {
+	files, err := policyFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	fingerprint := ""
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			return "", err
+		}
+		fingerprint += fmt.Sprintf("%s:%d:%d;", filepath.Base(name), info.Size(), info.ModTime().UnixNano())
+	}
+	return fingerprint, nil
+}
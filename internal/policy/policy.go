@@ -0,0 +1,252 @@
+// Package policy contains attribute based access control policy engine
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/google/cel-go/cel"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy effects
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+var (
+	// ErrInvalidPolicy godoc
+	ErrInvalidPolicy = errors.New("invalid policy")
+)
+
+// File policy file layout
+type File struct {
+	Policies []*Policy `yaml:"policies"`
+}
+
+// Policy declarative access rule, condition is a CEL expression evaluated for matching actions and resources
+type Policy struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Effect      string   `yaml:"effect"`
+	Actions     []string `yaml:"actions"`
+	Resources   []string `yaml:"resources"`
+	Condition   string   `yaml:"condition"`
+}
+
+// Set compiled policies
+type Set struct {
+	policies []*compiledPolicy
+}
+
+type compiledPolicy struct {
+	*Policy
+	program cel.Program
+}
+
+// newEnv creates CEL environment exposing policy input
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("token", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("request", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("now", cel.TimestampType),
+		cel.CrossTypeNumericComparisons(true),
+	)
+}
+
+// LoadDir loads and compiles all *.yaml and *.yml policy files of dir
+func LoadDir(dir string) (*Set, error) {
+	files, err := policyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	set := &Set{}
+	names := make(map[string]string)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Clean(name))
+		if err != nil {
+			return nil, err
+		}
+		file := &File{}
+		if err = yaml.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, name, err)
+		}
+		for _, policy := range file.Policies {
+			if other, ok := names[policy.Name]; ok {
+				return nil, fmt.Errorf("%w: %s: policy %q already defined in %s", ErrInvalidPolicy, name, policy.Name, other)
+			}
+			names[policy.Name] = name
+			compiled, err := compile(env, policy)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			set.policies = append(set.policies, compiled)
+		}
+	}
+	return set, nil
+}
+
+// compile validates policy and compiles its condition
+func compile(env *cel.Env, policy *Policy) (*compiledPolicy, error) {
+	if policy.Name == "" {
+		return nil, fmt.Errorf("%w: policy without name", ErrInvalidPolicy)
+	}
+	if policy.Effect != EffectAllow && policy.Effect != EffectDeny {
+		return nil, fmt.Errorf("%w: policy %q: unknown effect %q", ErrInvalidPolicy, policy.Name, policy.Effect)
+	}
+	for _, pattern := range append(append([]string(nil), policy.Actions...), policy.Resources...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: policy %q: pattern %q: %v", ErrInvalidPolicy, policy.Name, pattern, err)
+		}
+	}
+	condition := strings.TrimSpace(policy.Condition)
+	if condition == "" {
+		condition = "true"
+	}
+	ast, iss := env.Compile(condition)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("%w: policy %q: %v", ErrInvalidPolicy, policy.Name, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("%w: policy %q: condition must be bool, got %v", ErrInvalidPolicy, policy.Name, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("%w: policy %q: %v", ErrInvalidPolicy, policy.Name, err)
+	}
+	return &compiledPolicy{Policy: policy, program: program}, nil
+}
+
+// Evaluate evaluates policies matching input action and resource, deny policies take precedence and a policy
+// failing to evaluate denies. Returned decision is nil when no matching policy condition holds, matched reports whether any policy applied.
+func (s *Set) Evaluate(input *model.PolicyInput) (decision *model.Decision, matched bool) {
+	activation := map[string]interface{}{
+		"token": input.Token,
+		"request": map[string]interface{}{
+			"action":     input.Action,
+			"resource":   input.Resource,
+			"attributes": attributes(input.RequestAttributes),
+		},
+		"resource": resourceActivation(input.Resource, input.ResourceAttributes),
+		"now":      input.Now,
+	}
+	for _, effect := range []string{EffectDeny, EffectAllow} {
+		for _, policy := range s.policies {
+			if policy.Effect != effect || !policy.matches(input) {
+				continue
+			}
+			matched = true
+			holds, err := policy.eval(activation)
+			if err != nil {
+				log.Errorf("Set / Evaluate / policy %q failed, denying: %v", policy.Name, err)
+				return &model.Decision{Reason: fmt.Sprintf("policy %q failed, denying: %v", policy.Name, err)}, true
+			}
+			if holds {
+				return &model.Decision{
+					Allowed: effect == EffectAllow,
+					Reason:  fmt.Sprintf("%s by policy %q", effectPastTense(effect), policy.Name),
+				}, true
+			}
+		}
+	}
+	return nil, matched
+}
+
+// Names returns names of loaded policies
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.policies))
+	for _, policy := range s.policies {
+		names = append(names, policy.Name)
+	}
+	return names
+}
+
+func (p *compiledPolicy) matches(input *model.PolicyInput) bool {
+	return matchesAny(p.Actions, input.Action) && matchesAny(p.Resources, input.Resource)
+}
+
+func (p *compiledPolicy) eval(activation map[string]interface{}) (bool, error) {
+	out, _, err := p.program.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+	holds, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition result %v is not bool", out.Value())
+	}
+	return holds, nil
+}
+
+// matchesAny matches value against path.Match patterns, no patterns match everything
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func resourceActivation(resource string, resourceAttributes map[string]interface{}) map[string]interface{} {
+	resourceType, id, _ := strings.Cut(resource, "/")
+	return map[string]interface{}{
+		"name":       resource,
+		"type":       resourceType,
+		"id":         id,
+		"attributes": attributes(resourceAttributes),
+	}
+}
+
+func attributes(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return map[string]interface{}{}
+	}
+	return values
+}
+
+func effectPastTense(effect string) string {
+	if effect == EffectDeny {
+		return "denied"
+	}
+	return "allowed"
+}
+
+// policyFiles lists policy files of dir in stable order
+func policyFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || ext != ".yaml" && ext != ".yml" || isTestFile(entry.Name()) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isTestFile reports whether file holds policy test cases rather than policies
+func isTestFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.HasSuffix(base, "_test")
+}
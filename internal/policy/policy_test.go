@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const testdataDir = "testdata"
+
+func TestLoadDir(t *testing.T) {
+	set, err := LoadDir(testdataDir)
+
+	assert.NoError(t, err, "Expected no error when loading policies")
+	assert.Equal(t, []string{"owners-edit-own-documents-during-business-hours", "archived-documents-are-read-only"},
+		set.Names(), "Loaded policies mismatch")
+}
+
+func TestLoadDir_InvalidCondition(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "invalid.yaml", `policies:
+  - name: invalid
+    effect: allow
+    condition: token.sub +
+`)
+
+	_, err := LoadDir(dir)
+
+	assert.ErrorIs(t, err, ErrInvalidPolicy, "Expected ErrInvalidPolicy for condition with syntax error")
+}
+
+func TestLoadDir_NonBoolCondition(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "invalid.yaml", `policies:
+  - name: invalid
+    effect: allow
+    condition: now.getHours()
+`)
+
+	_, err := LoadDir(dir)
+
+	assert.ErrorIs(t, err, ErrInvalidPolicy, "Expected ErrInvalidPolicy for non bool condition")
+}
+
+func TestSet_Evaluate(t *testing.T) {
+	set, err := LoadDir(testdataDir)
+	assert.NoError(t, err, "Expected no error when loading policies")
+	input := &model.PolicyInput{
+		Token:              map[string]interface{}{"sub": "user-1"},
+		Action:             "edit",
+		Resource:           "documents/42",
+		ResourceAttributes: map[string]interface{}{"owner": "user-1"},
+		Now:                time.Date(2023, 7, 3, 10, 0, 0, 0, time.UTC),
+	}
+
+	decision, matched := set.Evaluate(input)
+
+	assert.True(t, matched, "Expected policies to match")
+	assert.True(t, decision.Allowed, "Expected owner to edit document")
+
+	input.ResourceAttributes["archived"] = true
+	decision, _ = set.Evaluate(input)
+
+	assert.False(t, decision.Allowed, "Expected deny policy to take precedence")
+}
+
+func TestRunTests(t *testing.T) {
+	set, err := LoadDir(testdataDir)
+	assert.NoError(t, err, "Expected no error when loading policies")
+
+	results, err := RunTests(set, testdataDir)
+
+	assert.NoError(t, err, "Expected no error when running policy tests")
+	assert.NotEmpty(t, results, "Expected policy test results")
+	for _, result := range results {
+		assert.True(t, result.Passed, "Policy test %q failed: got %s (%s)", result.Name, result.Got, result.Reason)
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "policies.yaml", `policies:
+  - name: allow-all
+    effect: allow
+`)
+	engine, err := NewEngine(dir)
+	assert.NoError(t, err, "Expected no error when creating engine")
+	revision := engine.Revision()
+
+	writePolicy(t, dir, "policies.yaml", `policies:
+  - name: broken
+    effect: maybe
+`)
+	err = engine.Reload()
+
+	assert.ErrorIs(t, err, ErrInvalidPolicy, "Expected ErrInvalidPolicy for unknown effect")
+	decision, _ := engine.Evaluate(&model.PolicyInput{Action: "read", Resource: "documents/42", Now: time.Now()})
+	assert.True(t, decision.Allowed, "Expected previous policies to be kept after failed reload")
+	assert.Equal(t, revision, engine.Revision(), "Expected revision to be kept after failed reload")
+
+	writePolicy(t, dir, "policies.yaml", `policies:
+  - name: deny-all
+    effect: deny
+`)
+	err = engine.Reload()
+	assert.NoError(t, err, "Expected no error when reloading policies")
+	assert.Greater(t, engine.Revision(), revision, "Expected new revision after reload")
+}
+
+func TestSet_Evaluate_FailingPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "policies.yaml", `policies:
+  - name: owners-read
+    effect: allow
+    condition: resource.attributes.owner == token.sub
+`)
+	set, err := LoadDir(dir)
+	assert.NoError(t, err, "Expected no error when loading policies")
+
+	decision, matched := set.Evaluate(&model.PolicyInput{Action: "read", Resource: "documents/42", Now: time.Now()})
+
+	assert.True(t, matched, "Expected policy to match")
+	assert.False(t, decision.Allowed, "Expected failing allow policy to deny")
+}
+
+func writePolicy(t *testing.T, dir, name, content string) {
+	err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+	assert.NoError(t, err, "Expected no error when writing policy file")
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Expected test case outcomes
+const (
+	ExpectAllow         = "allow"
+	ExpectDeny          = "deny"
+	ExpectNotApplicable = "not_applicable"
+)
+
+// TestFile policy test cases file layout
+type TestFile struct {
+	Tests []*TestCase `yaml:"tests"`
+}
+
+// TestCase policy evaluation input with expected outcome
+type TestCase struct {
+	Name               string                 `yaml:"name"`
+	Token              map[string]interface{} `yaml:"token"`
+	Action             string                 `yaml:"action"`
+	Resource           string                 `yaml:"resource"`
+	RequestAttributes  map[string]interface{} `yaml:"requestAttributes"`
+	ResourceAttributes map[string]interface{} `yaml:"resourceAttributes"`
+	Now                time.Time              `yaml:"now"`
+	Expect             string                 `yaml:"expect"`
+}
+
+// TestResult outcome of policy test case
+type TestResult struct {
+	File   string
+	Name   string
+	Passed bool
+	Got    string
+	Reason string
+}
+
+// RunTests evaluates test cases of all *_test.yaml and *_test.yml files of dir against policy set
+func RunTests(set *Set, dir string) ([]*TestResult, error) {
+	files, err := testFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*TestResult, 0)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Clean(name))
+		if err != nil {
+			return nil, err
+		}
+		file := &TestFile{}
+		if err = yaml.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, test := range file.Tests {
+			results = append(results, runTest(set, name, test))
+		}
+	}
+	return results, nil
+}
+
+func runTest(set *Set, file string, test *TestCase) *TestResult {
+	now := test.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	decision, matched := set.Evaluate(&model.PolicyInput{
+		Token:              test.Token,
+		Action:             test.Action,
+		Resource:           test.Resource,
+		RequestAttributes:  test.RequestAttributes,
+		ResourceAttributes: test.ResourceAttributes,
+		Now:                now,
+	})
+	result := &TestResult{File: file, Name: test.Name, Got: ExpectNotApplicable}
+	switch {
+	case decision != nil && decision.Allowed:
+		result.Got, result.Reason = ExpectAllow, decision.Reason
+	case decision != nil:
+		result.Got, result.Reason = ExpectDeny, decision.Reason
+	case matched:
+		result.Got, result.Reason = ExpectDeny, "no matching policy condition holds"
+	}
+	result.Passed = result.Got == test.Expect
+	return result
+}
+
+func testFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") && isTestFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AccessTokenDenylist revoked access tokens storage, keyed by token jti
+type AccessTokenDenylist struct {
+	revokedTokens     *sync.Map
+	revokedSubjects   *sync.Map
+	mu                sync.RWMutex
+	filter            *bloomFilter
+	expectedItems     uint
+	falsePositiveRate float64
+}
+
+type subjectRevocation struct {
+	revokedAt int64
+	expiresAt int64
+}
+
+// NewAccessTokenDenylist creates new access token denylist
+func NewAccessTokenDenylist(
+	revokedTokens, revokedSubjects *sync.Map, expectedItems uint, falsePositiveRate float64) *AccessTokenDenylist {
+	return &AccessTokenDenylist{
+		revokedTokens:     revokedTokens,
+		revokedSubjects:   revokedSubjects,
+		filter:            newBloomFilter(expectedItems, falsePositiveRate),
+		expectedItems:     expectedItems,
+		falsePositiveRate: falsePositiveRate,
+	}
+}
+
+// Revoke adds token jti to denylist until token expiration time
+func (d *AccessTokenDenylist) Revoke(jti string, expiresAt int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.revokedTokens.Store(jti, expiresAt)
+	d.filter.Add(jti)
+}
+
+// IsRevoked checks if token jti is in denylist
+func (d *AccessTokenDenylist) IsRevoked(jti string) bool {
+	d.mu.RLock()
+	mayContain := d.filter.MayContain(jti)
+	d.mu.RUnlock()
+	if !mayContain {
+		return false
+	}
+	expiresAt, ok := d.revokedTokens.Load(jti)
+	if !ok {
+		return false
+	}
+	return expiresAt.(int64) > time.Now().Unix()
+}
+
+// RevokeSubject revokes all subject tokens issued until revokedAt Unix nanoseconds, entry is kept until expiresAt
+func (d *AccessTokenDenylist) RevokeSubject(subject string, revokedAt, expiresAt int64) {
+	d.revokedSubjects.Store(subject, &subjectRevocation{revokedAt: revokedAt, expiresAt: expiresAt})
+}
+
+// IsSubjectRevoked checks if subject token issued at issuedAt Unix nanoseconds is revoked,
+// tokens issued the very nanosecond of revocation are revoked too
+func (d *AccessTokenDenylist) IsSubjectRevoked(subject string, issuedAt int64) bool {
+	revocation, ok := d.revokedSubjects.Load(subject)
+	if !ok {
+		return false
+	}
+	return issuedAt <= revocation.(*subjectRevocation).revokedAt
+}
+
+// Cleanup removes expired tokens from denylist and rebuilds bloom filter
+func (d *AccessTokenDenylist) Cleanup() {
+	now := time.Now().Unix()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	filter := newBloomFilter(d.expectedItems, d.falsePositiveRate)
+	d.revokedTokens.Range(func(jti, expiresAt interface{}) bool {
+		if expiresAt.(int64) <= now {
+			d.revokedTokens.Delete(jti)
+			return true
+		}
+		filter.Add(jti.(string))
+		return true
+	})
+	d.filter = filter
+	d.revokedSubjects.Range(func(subject, revocation interface{}) bool {
+		if revocation.(*subjectRevocation).expiresAt <= now {
+			d.revokedSubjects.Delete(subject)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired tokens until ctx is done
+func (d *AccessTokenDenylist) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Cleanup()
+			log.Debug("AccessTokenDenylist / RunCleanup / expired tokens removed")
+		}
+	}
+}
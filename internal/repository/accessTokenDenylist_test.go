@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mockJti = "example_jti"
+
+// TestRevoke tests the Revoke and IsRevoked methods
+func TestRevoke(t *testing.T) {
+	storage := &sync.Map{}
+	denylist := NewAccessTokenDenylist(storage, &sync.Map{}, 100, 0.01)
+
+	t.Log("Verify that unknown token is not revoked")
+	assert.False(t, denylist.IsRevoked(mockJti), "Unknown token is revoked")
+
+	t.Log("Revoke the token")
+	denylist.Revoke(mockJti, time.Now().Add(time.Hour).Unix())
+
+	t.Log("Verify that the token is revoked")
+	assert.True(t, denylist.IsRevoked(mockJti), "Token was not revoked")
+	_, loaded := storage.Load(mockJti)
+	assert.True(t, loaded, "Token was not stored in the storage")
+}
+
+// TestIsRevokedExpired tests that expired tokens are not reported as revoked
+func TestIsRevokedExpired(t *testing.T) {
+	storage := &sync.Map{}
+	denylist := NewAccessTokenDenylist(storage, &sync.Map{}, 100, 0.01)
+
+	t.Log("Revoke already expired token")
+	denylist.Revoke(mockJti, time.Now().Add(-time.Minute).Unix())
+
+	t.Log("Verify that the expired token is not reported as revoked")
+	assert.False(t, denylist.IsRevoked(mockJti), "Expired token is reported as revoked")
+}
+
+// TestCleanup tests the Cleanup method
+func TestCleanup(t *testing.T) {
+	const activeJti = "active_jti"
+	storage := &sync.Map{}
+	denylist := NewAccessTokenDenylist(storage, &sync.Map{}, 100, 0.01)
+	denylist.Revoke(mockJti, time.Now().Add(-time.Minute).Unix())
+	denylist.Revoke(activeJti, time.Now().Add(time.Hour).Unix())
+
+	t.Log("Cleanup the denylist")
+	denylist.Cleanup()
+
+	t.Log("Verify that only the expired token is removed from the storage")
+	_, loaded := storage.Load(mockJti)
+	assert.False(t, loaded, "Expired token was not removed from storage")
+	assert.True(t, denylist.IsRevoked(activeJti), "Active token was removed from denylist")
+}
+
+// TestRevokeSubject tests the RevokeSubject and IsSubjectRevoked methods
+func TestRevokeSubject(t *testing.T) {
+	now := time.Now().UnixNano()
+	storage := &sync.Map{}
+	denylist := NewAccessTokenDenylist(&sync.Map{}, storage, 100, 0.01)
+
+	t.Log("Revoke all subject tokens issued until now")
+	denylist.RevokeSubject(mockUsername, now, time.Now().Add(time.Hour).Unix())
+
+	t.Log("Verify that only tokens issued until now are revoked")
+	assert.True(t, denylist.IsSubjectRevoked(mockUsername, now-1), "Token issued before revocation is not revoked")
+	assert.True(t, denylist.IsSubjectRevoked(mockUsername, now), "Token issued at revocation is not revoked")
+	assert.False(t, denylist.IsSubjectRevoked(mockUsername, now+1), "Token issued after revocation is revoked")
+	assert.False(t, denylist.IsSubjectRevoked(mockUsername, now+int64(time.Millisecond)),
+		"Token issued later in the second of revocation is revoked")
+	assert.False(t, denylist.IsSubjectRevoked("other", now-1), "Token of another subject is revoked")
+
+	t.Log("Cleanup the denylist and verify that active revocation is kept")
+	denylist.Cleanup()
+	_, loaded := storage.Load(mockUsername)
+	assert.True(t, loaded, "Active subject revocation was removed from storage")
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Entetry/authService/internal/model"
+)
+
+// APIKeyStorage personal access tokens storage, keyed by secret hash
+type APIKeyStorage struct {
+	keys *sync.Map
+	mu   sync.Mutex
+}
+
+// NewAPIKeyStorage creates new API key storage
+func NewAPIKeyStorage(keys *sync.Map) *APIKeyStorage {
+	return &APIKeyStorage{keys: keys}
+}
+
+// Save stores API key by its hash
+func (s *APIKeyStorage) Save(key *model.APIKey) {
+	s.keys.Store(key.Hash, key)
+}
+
+// Load gets API key by secret hash
+func (s *APIKeyStorage) Load(hash string) (*model.APIKey, bool) {
+	key, ok := s.keys.Load(hash)
+	if !ok {
+		return nil, ok
+	}
+	return key.(*model.APIKey), ok
+}
+
+// List gets tenant user API keys sorted by creation time
+func (s *APIKeyStorage) List(tenantID, userID string) []*model.APIKey {
+	keys := make([]*model.APIKey, 0)
+	s.keys.Range(func(_, value interface{}) bool {
+		key := value.(*model.APIKey)
+		if key.TenantID == tenantID && key.UserID == userID {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt != keys[j].CreatedAt {
+			return keys[i].CreatedAt < keys[j].CreatedAt
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// Delete removes tenant user API key by id, reports whether the key existed
+func (s *APIKeyStorage) Delete(tenantID, userID, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := false
+	s.keys.Range(func(hash, value interface{}) bool {
+		key := value.(*model.APIKey)
+		if key.ID == id && key.TenantID == tenantID && key.UserID == userID {
+			s.keys.Delete(hash)
+			deleted = true
+			return false
+		}
+		return true
+	})
+	return deleted
+}
+
+// Touch records key use time. Stored keys are replaced rather than modified so readers never race with it,
+// the lock keeps a concurrent Delete from being undone.
+func (s *APIKeyStorage) Touch(hash string, usedAt int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.keys.Load(hash)
+	if !ok {
+		return
+	}
+	key := *value.(*model.APIKey)
+	key.LastUsedAt = usedAt
+	s.keys.Store(hash, &key)
+}
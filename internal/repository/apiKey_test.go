@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockKeyHash = "example_key_hash"
+
+// TestAPIKeyStorage tests the Save, Load, List and Delete methods
+func TestAPIKeyStorage(t *testing.T) {
+	storage := NewAPIKeyStorage(&sync.Map{})
+	now := time.Now().Unix()
+	key := &model.APIKey{ID: "first", Hash: mockKeyHash, TenantID: mockTenantID, UserID: mockUsername, CreatedAt: now}
+	storage.Save(&model.APIKey{ID: "second", Hash: "second_hash", TenantID: mockTenantID, UserID: mockUsername,
+		CreatedAt: now + 1})
+	storage.Save(&model.APIKey{ID: "other", Hash: "other_hash", TenantID: mockTenantID, UserID: "other",
+		CreatedAt: now})
+
+	t.Log("Save the key to the storage")
+	storage.Save(key)
+
+	t.Log("Verify that the key can be loaded by hash")
+	loadedKey, loaded := storage.Load(mockKeyHash)
+	assert.True(t, loaded, "Key was not stored in the storage")
+	assert.Equal(t, key, loadedKey, "Loaded key mismatch")
+
+	t.Log("Verify that only user keys are listed in creation order")
+	keys := storage.List(mockTenantID, mockUsername)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, "first", keys[0].ID)
+	assert.Equal(t, "second", keys[1].ID)
+	assert.Empty(t, storage.List("other_tenant", mockUsername), "Keys leaked across tenants")
+
+	t.Log("Verify that key of another user can't be deleted")
+	assert.False(t, storage.Delete(mockTenantID, "other", "first"))
+
+	t.Log("Verify that the key is removed once deleted")
+	assert.True(t, storage.Delete(mockTenantID, mockUsername, "first"))
+	_, loaded = storage.Load(mockKeyHash)
+	assert.False(t, loaded, "Key was not deleted")
+	assert.False(t, storage.Delete(mockTenantID, mockUsername, "first"), "Key was deleted twice")
+}
+
+// TestAPIKeyTouch tests that Touch records last use without modifying loaded keys
+func TestAPIKeyTouch(t *testing.T) {
+	storage := NewAPIKeyStorage(&sync.Map{})
+	key := &model.APIKey{ID: "first", Hash: mockKeyHash}
+	storage.Save(key)
+
+	t.Log("Touch the key and verify that last use is recorded")
+	storage.Touch(mockKeyHash, 42)
+	loadedKey, _ := storage.Load(mockKeyHash)
+	assert.Equal(t, int64(42), loadedKey.LastUsedAt)
+	assert.Zero(t, key.LastUsedAt, "Previously loaded key was modified")
+
+	t.Log("Verify that touching a deleted key doesn't restore it")
+	storage.Delete("", "", "first")
+	storage.Touch(mockKeyHash, 43)
+	_, loaded := storage.Load(mockKeyHash)
+	assert.False(t, loaded, "Deleted key was restored")
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// AttemptCounter counts failed attempts per key within fixed windows
+type AttemptCounter struct {
+	mu       sync.Mutex
+	attempts *sync.Map
+}
+
+// NewAttemptCounter creates new attempt counter
+func NewAttemptCounter(attempts *sync.Map) *AttemptCounter {
+	return &AttemptCounter{attempts: attempts}
+}
+
+// Add records attempt for key and returns attempts made in the window opened by the first of them
+func (c *AttemptCounter) Add(key string, window time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	attempts, ok := c.load(key, now.Unix())
+	if !ok {
+		attempts = &model.Attempts{ExpiresAt: now.Add(window).Unix()}
+	}
+	attempts.Count++
+	c.attempts.Store(key, attempts)
+	return attempts.Count
+}
+
+// Count returns attempts made for key in the current window
+func (c *AttemptCounter) Count(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	attempts, ok := c.load(key, time.Now().Unix())
+	if !ok {
+		return 0
+	}
+	return attempts.Count
+}
+
+// Reset forgets attempts made for key
+func (c *AttemptCounter) Reset(key string) {
+	c.attempts.Delete(key)
+}
+
+func (c *AttemptCounter) load(key string, now int64) (*model.Attempts, bool) {
+	attempts, ok := c.attempts.Load(key)
+	if !ok || attempts.(*model.Attempts).ExpiresAt <= now {
+		return nil, false
+	}
+	return attempts.(*model.Attempts), true
+}
+
+// Cleanup removes expired windows
+func (c *AttemptCounter) Cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now().Unix()
+	c.attempts.Range(func(key, attempts interface{}) bool {
+		if attempts.(*model.Attempts).ExpiresAt <= now {
+			c.attempts.Delete(key)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired windows until ctx is done
+func (c *AttemptCounter) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Cleanup()
+			log.Debug("AttemptCounter / RunCleanup / expired attempt windows removed")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockAttemptsKey = "example_attempts_key"
+
+// TestAttemptCounter tests the Add, Count and Reset methods
+func TestAttemptCounter(t *testing.T) {
+	counter := NewAttemptCounter(&sync.Map{})
+
+	t.Log("Attempts are counted within the window")
+	assert.Equal(t, 1, counter.Add(mockAttemptsKey, time.Minute), "First attempt count mismatch")
+	assert.Equal(t, 2, counter.Add(mockAttemptsKey, time.Minute), "Second attempt count mismatch")
+	assert.Equal(t, 2, counter.Count(mockAttemptsKey), "Attempt count mismatch")
+	assert.Equal(t, 0, counter.Count("other_attempts_key"), "Attempts of other key are counted")
+
+	t.Log("Reset forgets attempts")
+	counter.Reset(mockAttemptsKey)
+	assert.Equal(t, 0, counter.Count(mockAttemptsKey), "Attempts were not reset")
+}
+
+// TestAttemptCounterExpiredWindow tests that attempts of expired window are not counted and cleaned up
+func TestAttemptCounterExpiredWindow(t *testing.T) {
+	attempts := &sync.Map{}
+	counter := NewAttemptCounter(attempts)
+	attempts.Store(mockAttemptsKey, &model.Attempts{Count: 5, ExpiresAt: time.Now().Add(-time.Second).Unix()})
+	counter.Add("other_attempts_key", time.Minute)
+
+	assert.Equal(t, 0, counter.Count(mockAttemptsKey), "Attempts of expired window are counted")
+	assert.Equal(t, 1, counter.Add(mockAttemptsKey, time.Minute), "Expired window was not restarted")
+
+	t.Log("Run cleanup and verify that only the expired window is removed")
+	attempts.Store(mockAttemptsKey, &model.Attempts{Count: 5, ExpiresAt: time.Now().Add(-time.Second).Unix()})
+	counter.Cleanup()
+	_, loaded := attempts.Load(mockAttemptsKey)
+	assert.False(t, loaded, "Expired window was not removed")
+	assert.Equal(t, 1, counter.Count("other_attempts_key"), "Active window was removed")
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomFilter probabilistic set used as a fast negative lookup in front of a map
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// newBloomFilter creates bloom filter sized for expectedItems with the given false positive rate
+func newBloomFilter(expectedItems uint, falsePositiveRate float64) *bloomFilter {
+	if expectedItems == 0 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+	n := float64(expectedItems)
+	size := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Max(1, math.Round(float64(size)/n*math.Ln2)))
+
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// Add adds key to the filter
+func (b *bloomFilter) Add(key string) {
+	h1, h2 := b.hash(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		pos := (h1 + i*h2) % b.size
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// MayContain reports whether key may be in the filter, false means it is definitely absent
+func (b *bloomFilter) MayContain(key string) bool {
+	h1, h2 := b.hash(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		pos := (h1 + i*h2) % b.size
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bloomFilter) hash(key string) (h1, h2 uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	return sum & math.MaxUint32, sum>>32 | 1
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// DecisionCache authorization decisions cache, keyed by token jti
+type DecisionCache struct {
+	tokens *sync.Map
+}
+
+type tokenDecisions struct {
+	expiresAt int64
+	decisions sync.Map
+}
+
+// NewDecisionCache creates new decision cache
+func NewDecisionCache(tokens *sync.Map) *DecisionCache {
+	return &DecisionCache{tokens: tokens}
+}
+
+// Load gets cached decision for token jti and check key
+func (c *DecisionCache) Load(jti, key string) (*model.Decision, bool) {
+	cached, ok := c.tokens.Load(jti)
+	if !ok {
+		return nil, false
+	}
+	entry := cached.(*tokenDecisions)
+	if entry.expiresAt <= time.Now().Unix() {
+		return nil, false
+	}
+	decision, ok := entry.decisions.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return decision.(*model.Decision), true
+}
+
+// Save caches decision for token jti and check key until expiresAt
+func (c *DecisionCache) Save(jti string, expiresAt int64, key string, decision *model.Decision) {
+	cached, _ := c.tokens.LoadOrStore(jti, &tokenDecisions{expiresAt: expiresAt})
+	cached.(*tokenDecisions).decisions.Store(key, decision)
+}
+
+// Cleanup removes expired decisions
+func (c *DecisionCache) Cleanup() {
+	now := time.Now().Unix()
+	c.tokens.Range(func(jti, cached interface{}) bool {
+		if cached.(*tokenDecisions).expiresAt <= now {
+			c.tokens.Delete(jti)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired decisions until ctx is done
+func (c *DecisionCache) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Cleanup()
+			log.Debug("DecisionCache / RunCleanup / expired decisions removed")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockDecisionKey = "read\x00documents/42"
+
+// TestDecisionCacheSave tests the Save and Load methods
+func TestDecisionCacheSave(t *testing.T) {
+	storage := &sync.Map{}
+	cache := NewDecisionCache(storage)
+	decision := &model.Decision{Allowed: true, Reason: "granted"}
+
+	t.Log("Save the decision to the cache")
+	cache.Save(mockJti, time.Now().Add(time.Minute).Unix(), mockDecisionKey, decision)
+
+	t.Log("Verify that the decision is loaded from the cache")
+	loadedDecision, loaded := cache.Load(mockJti, mockDecisionKey)
+	assert.True(t, loaded, "Decision was not stored in the cache")
+	assert.Equal(t, decision, loadedDecision, "Cached decision mismatch")
+	_, loaded = cache.Load(mockJti, "write\x00documents/42")
+	assert.False(t, loaded, "Unexpected decision for another check")
+}
+
+// TestDecisionCacheCleanup tests that expired decisions are not loaded and removed by Cleanup
+func TestDecisionCacheCleanup(t *testing.T) {
+	storage := &sync.Map{}
+	cache := NewDecisionCache(storage)
+	cache.Save(mockJti, time.Now().Add(-time.Minute).Unix(), mockDecisionKey, &model.Decision{Allowed: true})
+
+	t.Log("Verify that the expired decision is not loaded")
+	_, loaded := cache.Load(mockJti, mockDecisionKey)
+	assert.False(t, loaded, "Expired decision was loaded")
+
+	t.Log("Cleanup the cache and verify that the expired decision is removed")
+	cache.Cleanup()
+	_, loaded = storage.Load(mockJti)
+	assert.False(t, loaded, "Expired decision was not removed from storage")
+}
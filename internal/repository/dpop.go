@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DPoPReplayCache remembers used DPoP proof IDs until proofs expire
+type DPoPReplayCache struct {
+	proofs *sync.Map
+}
+
+// NewDPoPReplayCache creates new DPoP replay cache
+func NewDPoPReplayCache(proofs *sync.Map) *DPoPReplayCache {
+	return &DPoPReplayCache{proofs: proofs}
+}
+
+// Remember records proof ID until expiresAt, false means the proof was already used
+func (c *DPoPReplayCache) Remember(proofID string, expiresAt int64) bool {
+	_, loaded := c.proofs.LoadOrStore(proofID, expiresAt)
+	return !loaded
+}
+
+// Cleanup removes expired proof IDs
+func (c *DPoPReplayCache) Cleanup() {
+	now := time.Now().Unix()
+	c.proofs.Range(func(proofID, expiresAt interface{}) bool {
+		if expiresAt.(int64) <= now {
+			c.proofs.Delete(proofID)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired proof IDs until ctx is done
+func (c *DPoPReplayCache) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Cleanup()
+			log.Debug("DPoPReplayCache / RunCleanup / expired DPoP proofs removed")
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDPoPReplayRemember tests that proof IDs are accepted once until they expire
+func TestDPoPReplayRemember(t *testing.T) {
+	cache := NewDPoPReplayCache(&sync.Map{})
+
+	t.Log("Verify that the proof is accepted once")
+	assert.True(t, cache.Remember("proof-1", time.Now().Add(time.Minute).Unix()), "Proof was not accepted")
+	assert.False(t, cache.Remember("proof-1", time.Now().Add(time.Minute).Unix()), "Proof was accepted twice")
+
+	t.Log("Run cleanup and verify that only the expired proof is forgotten")
+	assert.True(t, cache.Remember("proof-2", time.Now().Add(-time.Minute).Unix()), "Proof was not accepted")
+	cache.Cleanup()
+	assert.True(t, cache.Remember("proof-2", time.Now().Add(time.Minute).Unix()), "Expired proof was not forgotten")
+	assert.False(t, cache.Remember("proof-1", time.Now().Add(time.Minute).Unix()), "Active proof was forgotten")
+}
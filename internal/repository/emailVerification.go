@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"sync"
+)
+
+// EmailVerificationStorage verified users storage keyed by user id, verified users are kept in file
+// so they stay verified across restarts, empty file name keeps them in memory
+type EmailVerificationStorage struct {
+	mu       sync.RWMutex
+	name     string
+	verified map[string]bool
+}
+
+// NewEmailVerificationStorage creates new email verification storage loading users verified in file
+func NewEmailVerificationStorage(name string) (*EmailVerificationStorage, error) {
+	s := &EmailVerificationStorage{name: name, verified: make(map[string]bool)}
+	if name == "" {
+		return s, nil
+	}
+	if err := readJSONFile(name, &s.verified); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MarkVerified records that user confirmed their email
+func (s *EmailVerificationStorage) MarkVerified(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.verified[userID] {
+		return nil
+	}
+	s.verified[userID] = true
+	if s.name == "" {
+		return nil
+	}
+	if err := writeJSONFile(s.name, s.verified); err != nil {
+		delete(s.verified, userID)
+		return err
+	}
+	return nil
+}
+
+// IsVerified checks if user confirmed their email
+func (s *EmailVerificationStorage) IsVerified(userID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.verified[userID]
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEmailVerification tests the MarkVerified and IsVerified methods
+func TestEmailVerification(t *testing.T) {
+	storage, err := NewEmailVerificationStorage("")
+	assert.NoError(t, err, "Expected no error when creating in memory storage")
+
+	t.Log("Verify that users are not verified by default")
+	assert.False(t, storage.IsVerified(mockUserID), "User is verified before confirming email")
+
+	t.Log("Mark the user as verified and verify that only this user is verified")
+	assert.NoError(t, storage.MarkVerified(mockUserID), "Expected no error when marking user as verified")
+	assert.True(t, storage.IsVerified(mockUserID), "User was not marked as verified")
+	assert.False(t, storage.IsVerified("other_user_id"), "Other user was marked as verified")
+}
+
+// TestEmailVerificationFile tests that verified users survive storage restart
+func TestEmailVerificationFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "verified.json")
+	storage, err := NewEmailVerificationStorage(name)
+	assert.NoError(t, err, "Expected no error when creating storage without file")
+	assert.NoError(t, storage.MarkVerified(mockUserID), "Expected no error when marking user as verified")
+
+	t.Log("Reopen storage and verify that the user is still verified")
+	storage, err = NewEmailVerificationStorage(name)
+	assert.NoError(t, err, "Expected no error when loading storage file")
+	assert.True(t, storage.IsVerified(mockUserID), "Verified user was not persisted")
+	assert.False(t, storage.IsVerified("other_user_id"), "Other user was marked as verified")
+
+	t.Log("Storage file that can't be written is reported")
+	storage, err = NewEmailVerificationStorage(filepath.Join(t.TempDir(), "missing", "verified.json"))
+	assert.NoError(t, err, "Expected no error when creating storage without file")
+	assert.Error(t, storage.MarkVerified(mockUserID), "Expected error when storage file can't be written")
+	assert.False(t, storage.IsVerified(mockUserID), "User must not stay verified when it wasn't persisted")
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// FederationStateStorage pending upstream logins storage, keyed by state hash
+type FederationStateStorage struct {
+	states *sync.Map
+}
+
+// NewFederationStateStorage creates new federation state storage
+func NewFederationStateStorage(states *sync.Map) *FederationStateStorage {
+	return &FederationStateStorage{states: states}
+}
+
+// Save stores pending login by state hash
+func (s *FederationStateStorage) Save(stateHash string, state *model.FederationState) {
+	s.states.Store(stateHash, state)
+}
+
+// LoadAndDelete gets pending login by state hash and removes it, so state can be used once
+func (s *FederationStateStorage) LoadAndDelete(stateHash string) (*model.FederationState, bool) {
+	state, ok := s.states.LoadAndDelete(stateHash)
+	if !ok {
+		return nil, ok
+	}
+	return state.(*model.FederationState), ok
+}
+
+// Cleanup removes expired pending logins
+func (s *FederationStateStorage) Cleanup() {
+	now := time.Now().Unix()
+	s.states.Range(func(stateHash, state interface{}) bool {
+		if state.(*model.FederationState).ExpiresAt <= now {
+			s.states.Delete(stateHash)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired pending logins until ctx is done
+func (s *FederationStateStorage) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+			log.Debug("FederationStateStorage / RunCleanup / expired federation states removed")
+		}
+	}
+}
+
+// FederatedIdentityStorage links upstream provider accounts to users
+type FederatedIdentityStorage struct {
+	identities *sync.Map
+}
+
+// NewFederatedIdentityStorage creates new federated identity storage
+func NewFederatedIdentityStorage(identities *sync.Map) *FederatedIdentityStorage {
+	return &FederatedIdentityStorage{identities: identities}
+}
+
+// Link links provider subject to user
+func (s *FederatedIdentityStorage) Link(providerID, subject, userID string) {
+	s.identities.Store(identityKey(providerID, subject), userID)
+}
+
+// LoadUserID gets user linked to provider subject
+func (s *FederatedIdentityStorage) LoadUserID(providerID, subject string) (string, bool) {
+	userID, ok := s.identities.Load(identityKey(providerID, subject))
+	if !ok {
+		return "", ok
+	}
+	return userID.(string), ok
+}
+
+// identityKey scopes subject to provider, subjects are only unique within their issuer
+func identityKey(providerID, subject string) string {
+	return providerID + "|" + subject
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockStateHash = "example_state_hash"
+
+// TestFederationStateLoadAndDelete tests the Save and LoadAndDelete methods
+func TestFederationStateLoadAndDelete(t *testing.T) {
+	storage := NewFederationStateStorage(&sync.Map{})
+	state := &model.FederationState{ProviderID: "example_provider", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	t.Log("Save the federation state to the storage")
+	storage.Save(mockStateHash, state)
+
+	t.Log("Verify that the federation state is loaded once")
+	loadedState, loaded := storage.LoadAndDelete(mockStateHash)
+	assert.True(t, loaded, "Federation state was not stored in the storage")
+	assert.Equal(t, state, loadedState, "Loaded federation state mismatch")
+	_, loaded = storage.LoadAndDelete(mockStateHash)
+	assert.False(t, loaded, "Federation state was loaded twice")
+}
+
+// TestFederationStateCleanup tests that Cleanup removes expired federation states only
+func TestFederationStateCleanup(t *testing.T) {
+	storage := NewFederationStateStorage(&sync.Map{})
+	storage.Save(mockStateHash, &model.FederationState{ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	storage.Save("other_state_hash", &model.FederationState{ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	t.Log("Run cleanup and verify that only the expired federation state is removed")
+	storage.Cleanup()
+	_, loaded := storage.LoadAndDelete(mockStateHash)
+	assert.False(t, loaded, "Expired federation state was not removed")
+	_, loaded = storage.LoadAndDelete("other_state_hash")
+	assert.True(t, loaded, "Active federation state was removed")
+}
+
+// TestFederatedIdentityLink tests the Link and LoadUserID methods
+func TestFederatedIdentityLink(t *testing.T) {
+	storage := NewFederatedIdentityStorage(&sync.Map{})
+
+	t.Log("Link the provider subject to the user")
+	storage.Link("example_provider", "example_subject", mockUserID)
+
+	t.Log("Verify that the link is scoped to the provider")
+	userID, loaded := storage.LoadUserID("example_provider", "example_subject")
+	assert.True(t, loaded, "Federated identity was not linked")
+	assert.Equal(t, mockUserID, userID, "Linked user mismatch")
+	_, loaded = storage.LoadUserID("other_provider", "example_subject")
+	assert.False(t, loaded, "Federated identity leaked to other provider")
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// PasswordResetStorage pending password resets storage, keyed by reset token hash
+type PasswordResetStorage struct {
+	resets *sync.Map
+}
+
+// NewPasswordResetStorage creates new password reset storage
+func NewPasswordResetStorage(resets *sync.Map) *PasswordResetStorage {
+	return &PasswordResetStorage{resets: resets}
+}
+
+// Save stores password reset by reset token hash
+func (s *PasswordResetStorage) Save(tokenHash string, reset *model.PasswordReset) {
+	s.resets.Store(tokenHash, reset)
+}
+
+// LoadAndDelete gets password reset by reset token hash and removes it, so reset token can be used once
+func (s *PasswordResetStorage) LoadAndDelete(tokenHash string) (*model.PasswordReset, bool) {
+	reset, ok := s.resets.LoadAndDelete(tokenHash)
+	if !ok {
+		return nil, ok
+	}
+	return reset.(*model.PasswordReset), ok
+}
+
+// Cleanup removes expired password resets
+func (s *PasswordResetStorage) Cleanup() {
+	now := time.Now().Unix()
+	s.resets.Range(func(tokenHash, reset interface{}) bool {
+		if reset.(*model.PasswordReset).ExpiresAt <= now {
+			s.resets.Delete(tokenHash)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired password resets until ctx is done
+func (s *PasswordResetStorage) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+			log.Debug("PasswordResetStorage / RunCleanup / expired password resets removed")
+		}
+	}
+}
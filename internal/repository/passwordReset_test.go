@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockTokenHash = "example_token_hash"
+
+// TestPasswordResetLoadAndDelete tests the Save and LoadAndDelete methods
+func TestPasswordResetLoadAndDelete(t *testing.T) {
+	storage := NewPasswordResetStorage(&sync.Map{})
+	reset := &model.PasswordReset{UserID: mockUserID, Username: mockUsername, ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	t.Log("Save the password reset to the storage")
+	storage.Save(mockTokenHash, reset)
+
+	t.Log("Verify that the password reset is loaded once")
+	loadedReset, loaded := storage.LoadAndDelete(mockTokenHash)
+	assert.True(t, loaded, "Password reset was not stored in the storage")
+	assert.Equal(t, reset, loadedReset, "Loaded password reset mismatch")
+	_, loaded = storage.LoadAndDelete(mockTokenHash)
+	assert.False(t, loaded, "Password reset was loaded twice")
+}
+
+// TestPasswordResetCleanup tests that Cleanup removes expired password resets only
+func TestPasswordResetCleanup(t *testing.T) {
+	resets := &sync.Map{}
+	storage := NewPasswordResetStorage(resets)
+	storage.Save(mockTokenHash, &model.PasswordReset{ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	storage.Save("other_token_hash", &model.PasswordReset{ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	t.Log("Run cleanup and verify that only the expired password reset is removed")
+	storage.Cleanup()
+	_, loaded := resets.Load(mockTokenHash)
+	assert.False(t, loaded, "Expired password reset was not removed")
+	_, loaded = resets.Load("other_token_hash")
+	assert.True(t, loaded, "Valid password reset was removed")
+}
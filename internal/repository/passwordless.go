@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// PasswordlessStorage pending passwordless sign-in challenges storage
+type PasswordlessStorage struct {
+	challenges *sync.Map
+}
+
+// NewPasswordlessStorage creates new passwordless challenges storage
+func NewPasswordlessStorage(challenges *sync.Map) *PasswordlessStorage {
+	return &PasswordlessStorage{challenges: challenges}
+}
+
+// Save stores challenge, previous challenge with the same key is replaced
+func (s *PasswordlessStorage) Save(key string, challenge *model.PasswordlessChallenge) {
+	s.challenges.Store(key, challenge)
+}
+
+// Load gets challenge by key
+func (s *PasswordlessStorage) Load(key string) (*model.PasswordlessChallenge, bool) {
+	challenge, ok := s.challenges.Load(key)
+	if !ok {
+		return nil, ok
+	}
+	return challenge.(*model.PasswordlessChallenge), ok
+}
+
+// LoadAndDelete gets challenge by key and removes it, so concurrent attempts can't redeem it twice
+func (s *PasswordlessStorage) LoadAndDelete(key string) (*model.PasswordlessChallenge, bool) {
+	challenge, ok := s.challenges.LoadAndDelete(key)
+	if !ok {
+		return nil, ok
+	}
+	return challenge.(*model.PasswordlessChallenge), ok
+}
+
+// Cleanup removes expired challenges
+func (s *PasswordlessStorage) Cleanup() {
+	now := time.Now().Unix()
+	s.challenges.Range(func(key, challenge interface{}) bool {
+		if challenge.(*model.PasswordlessChallenge).ExpiresAt <= now {
+			s.challenges.Delete(key)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired challenges until ctx is done
+func (s *PasswordlessStorage) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+			log.Debug("PasswordlessStorage / RunCleanup / expired passwordless challenges removed")
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockChallengeKey = "example_challenge_key"
+
+// TestPasswordlessLoadAndDelete tests the Save, Load and LoadAndDelete methods
+func TestPasswordlessLoadAndDelete(t *testing.T) {
+	storage := NewPasswordlessStorage(&sync.Map{})
+	challenge := &model.PasswordlessChallenge{Username: mockUsername, ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	t.Log("Save the challenge to the storage")
+	storage.Save(mockChallengeKey, challenge)
+
+	t.Log("Verify that the challenge can be loaded")
+	loadedChallenge, loaded := storage.Load(mockChallengeKey)
+	assert.True(t, loaded, "Challenge was not stored in the storage")
+	assert.Equal(t, challenge, loadedChallenge, "Loaded challenge mismatch")
+
+	t.Log("Verify that the challenge is removed once loaded and deleted")
+	_, loaded = storage.LoadAndDelete(mockChallengeKey)
+	assert.True(t, loaded, "Challenge was not loaded")
+	_, loaded = storage.LoadAndDelete(mockChallengeKey)
+	assert.False(t, loaded, "Challenge was loaded twice")
+}
+
+// TestPasswordlessCleanup tests that Cleanup removes expired challenges only
+func TestPasswordlessCleanup(t *testing.T) {
+	storage := NewPasswordlessStorage(&sync.Map{})
+	storage.Save(mockChallengeKey, &model.PasswordlessChallenge{ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	storage.Save("other_challenge_key", &model.PasswordlessChallenge{ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	t.Log("Run cleanup and verify that only the expired challenge is removed")
+	storage.Cleanup()
+	_, loaded := storage.Load(mockChallengeKey)
+	assert.False(t, loaded, "Expired challenge was not removed")
+	_, loaded = storage.Load("other_challenge_key")
+	assert.True(t, loaded, "Active challenge was removed")
+}
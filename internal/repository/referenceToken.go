@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// ReferenceTokenStorage opaque access tokens storage, keyed by token hash
+type ReferenceTokenStorage struct {
+	tokens *sync.Map
+}
+
+// NewReferenceTokenStorage creates new reference token storage
+func NewReferenceTokenStorage(tokens *sync.Map) *ReferenceTokenStorage {
+	return &ReferenceTokenStorage{tokens: tokens}
+}
+
+// Save stores access token by opaque token hash
+func (s *ReferenceTokenStorage) Save(tokenHash string, token *model.ReferenceToken) {
+	s.tokens.Store(tokenHash, token)
+}
+
+// Load gets access token by opaque token hash
+func (s *ReferenceTokenStorage) Load(tokenHash string) (*model.ReferenceToken, bool) {
+	token, ok := s.tokens.Load(tokenHash)
+	if !ok {
+		return nil, ok
+	}
+	return token.(*model.ReferenceToken), ok
+}
+
+// Delete removes access token by opaque token hash
+func (s *ReferenceTokenStorage) Delete(tokenHash string) {
+	s.tokens.Delete(tokenHash)
+}
+
+// Cleanup removes expired tokens
+func (s *ReferenceTokenStorage) Cleanup() {
+	now := time.Now().Unix()
+	s.tokens.Range(func(tokenHash, token interface{}) bool {
+		if token.(*model.ReferenceToken).ExpiresAt <= now {
+			s.tokens.Delete(tokenHash)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired tokens until ctx is done
+func (s *ReferenceTokenStorage) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+			log.Debug("ReferenceTokenStorage / RunCleanup / expired reference tokens removed")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockReferenceTokenHash = "example_reference_token_hash"
+
+// TestReferenceTokenStorage tests the Save, Load and Delete methods
+func TestReferenceTokenStorage(t *testing.T) {
+	storage := NewReferenceTokenStorage(&sync.Map{})
+	token := &model.ReferenceToken{TenantID: mockTenantID, AccessToken: "jwt", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	t.Log("Save the token to the storage")
+	storage.Save(mockReferenceTokenHash, token)
+
+	t.Log("Verify that the token can be loaded")
+	loadedToken, loaded := storage.Load(mockReferenceTokenHash)
+	assert.True(t, loaded, "Token was not stored in the storage")
+	assert.Equal(t, token, loadedToken, "Loaded token mismatch")
+
+	t.Log("Verify that the token is removed once deleted")
+	storage.Delete(mockReferenceTokenHash)
+	_, loaded = storage.Load(mockReferenceTokenHash)
+	assert.False(t, loaded, "Token was not deleted")
+}
+
+// TestReferenceTokenCleanup tests that Cleanup removes expired tokens only
+func TestReferenceTokenCleanup(t *testing.T) {
+	storage := NewReferenceTokenStorage(&sync.Map{})
+	storage.Save(mockReferenceTokenHash, &model.ReferenceToken{ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	storage.Save("other_token_hash", &model.ReferenceToken{ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	t.Log("Run cleanup and verify that only the expired token is removed")
+	storage.Cleanup()
+	_, loaded := storage.Load(mockReferenceTokenHash)
+	assert.False(t, loaded, "Expired token was not removed")
+	_, loaded = storage.Load("other_token_hash")
+	assert.True(t, loaded, "Active token was removed")
+}
@@ -19,8 +19,8 @@ func NewRefreshSessionStorage(refreshTokenStorage *sync.Map) *RefreshSessionStor
 }
 
 // LoadAndDelete gets refresh session and removes it from cash
-func (r *RefreshSessionStorage) LoadAndDelete(username string) (*model.Session, bool) {
-	session, ok := r.refreshTokenStorage.LoadAndDelete(username)
+func (r *RefreshSessionStorage) LoadAndDelete(tenantID, username string) (*model.Session, bool) {
+	session, ok := r.refreshTokenStorage.LoadAndDelete(model.TenantKey(tenantID, username))
 	if !ok {
 		return nil, ok
 	}
@@ -29,17 +29,17 @@ func (r *RefreshSessionStorage) LoadAndDelete(username string) (*model.Session,
 
 // SaveSession save refresh session to db
 func (r *RefreshSessionStorage) SaveSession(session *model.Session) {
-	r.refreshTokenStorage.Store(session.Username, session)
+	r.refreshTokenStorage.Store(model.TenantKey(session.TenantID, session.Username), session)
 }
 
 // Delete delete refresh session by token
-func (r *RefreshSessionStorage) Delete(username string) {
-	r.refreshTokenStorage.Delete(username)
+func (r *RefreshSessionStorage) Delete(tenantID, username string) {
+	r.refreshTokenStorage.Delete(model.TenantKey(tenantID, username))
 }
 
 // Load gets refresh session and removes it from cash
-func (r *RefreshSessionStorage) Load(username string) (*model.Session, bool) {
-	session, ok := r.refreshTokenStorage.Load(username)
+func (r *RefreshSessionStorage) Load(tenantID, username string) (*model.Session, bool) {
+	session, ok := r.refreshTokenStorage.Load(model.TenantKey(tenantID, username))
 	if !ok {
 		return nil, ok
 	}
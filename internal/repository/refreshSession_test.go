@@ -11,6 +11,7 @@ import (
 const (
 	mockRefreshToken = "example_refresh_token"
 	mockUsername     = "test"
+	mockTenantID     = "example_tenant"
 )
 
 // TestLoadAndDelete tests the LoadAndDelete method
@@ -18,6 +19,7 @@ func TestLoadAndDelete(t *testing.T) {
 	mockExpiresAt := int64(2280000)
 	storage := &sync.Map{}
 	session := &model.Session{
+		TenantID:     mockTenantID,
 		RefreshToken: mockRefreshToken,
 		Username:     mockUsername,
 		ExpiresAt:    mockExpiresAt,
@@ -30,12 +32,12 @@ func TestLoadAndDelete(t *testing.T) {
 	refreshSession.SaveSession(session)
 
 	t.Log("Test loading and deleting the session")
-	loadedSession, loaded := refreshSession.LoadAndDelete(session.Username)
+	loadedSession, loaded := refreshSession.LoadAndDelete(mockTenantID, session.Username)
 	assert.True(t, loaded, "Unexpected error")
 	assert.Equal(t, session, loadedSession, "Loaded session mismatch")
 
 	t.Log("Verify that the session is deleted from the storage")
-	_, loaded = storage.Load(mockRefreshToken)
+	_, loaded = storage.Load(model.TenantKey(mockTenantID, mockUsername))
 	assert.False(t, loaded, "Session was not deleted from storage")
 }
 
@@ -44,6 +46,7 @@ func TestSaveSession(t *testing.T) {
 	mockExpiresAt := int64(2280000)
 	storage := &sync.Map{}
 	session := &model.Session{
+		TenantID:     mockTenantID,
 		RefreshToken: mockRefreshToken,
 		Username:     mockUsername,
 		ExpiresAt:    mockExpiresAt,
@@ -56,7 +59,7 @@ func TestSaveSession(t *testing.T) {
 	refreshSession.SaveSession(session)
 
 	t.Log(" Verify that the session is stored in the storage")
-	storedSession, loaded := storage.Load(mockUsername)
+	storedSession, loaded := storage.Load(model.TenantKey(mockTenantID, mockUsername))
 	assert.True(t, loaded, "Session was not stored in the storage")
 	assert.Equal(t, session, storedSession, "Stored session mismatch")
 }
@@ -66,6 +69,7 @@ func TestDelete(t *testing.T) {
 	mockExpiresAt := int64(2280000)
 	storage := &sync.Map{}
 	session := &model.Session{
+		TenantID:     mockTenantID,
 		RefreshToken: mockRefreshToken,
 		Username:     mockUsername,
 		ExpiresAt:    mockExpiresAt,
@@ -78,9 +82,35 @@ func TestDelete(t *testing.T) {
 	refreshSession.SaveSession(session)
 
 	t.Log("Delete the session")
-	refreshSession.Delete(mockRefreshToken)
+	refreshSession.Delete(mockTenantID, mockUsername)
 
 	t.Log("Verify that the session is deleted from the storage")
-	_, loaded := storage.Load(mockRefreshToken)
+	_, loaded := storage.Load(model.TenantKey(mockTenantID, mockUsername))
 	assert.False(t, loaded, "Session was not deleted from storage")
 }
+
+// TestLoadTenantScoped tests that sessions of the same username in different tenants do not collide
+func TestLoadTenantScoped(t *testing.T) {
+	refreshSession := NewRefreshSessionStorage(&sync.Map{})
+	session := &model.Session{TenantID: mockTenantID, RefreshToken: mockRefreshToken, Username: mockUsername}
+	otherSession := &model.Session{TenantID: "other_tenant", RefreshToken: "other_refresh_token", Username: mockUsername}
+
+	t.Log("Save sessions of the same username in two tenants")
+	refreshSession.SaveSession(session)
+	refreshSession.SaveSession(otherSession)
+
+	t.Log("Verify that each tenant loads its own session")
+	loadedSession, loaded := refreshSession.Load(mockTenantID, mockUsername)
+	assert.True(t, loaded, "Session was not stored in the storage")
+	assert.Equal(t, session, loadedSession, "Loaded session mismatch")
+	loadedSession, loaded = refreshSession.Load("other_tenant", mockUsername)
+	assert.True(t, loaded, "Session was not stored in the storage")
+	assert.Equal(t, otherSession, loadedSession, "Loaded session mismatch")
+
+	t.Log("Delete the session in one tenant and verify that the other one is kept")
+	refreshSession.Delete(mockTenantID, mockUsername)
+	_, loaded = refreshSession.Load(mockTenantID, mockUsername)
+	assert.False(t, loaded, "Session was not deleted from storage")
+	_, loaded = refreshSession.Load("other_tenant", mockUsername)
+	assert.True(t, loaded, "Session of other tenant was deleted")
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Entetry/authService/internal/model"
+)
+
+// RoleStorage tenant scoped roles and user role assignments storage
+type RoleStorage struct {
+	roles       *sync.Map
+	assignments *sync.Map
+	mu          sync.Mutex
+}
+
+// NewRoleStorage creates new role storage
+func NewRoleStorage(roles, assignments *sync.Map) *RoleStorage {
+	return &RoleStorage{roles: roles, assignments: assignments}
+}
+
+// SaveRole creates or replaces tenant role
+func (r *RoleStorage) SaveRole(tenantID string, role *model.Role) {
+	r.roles.Store(model.TenantKey(tenantID, role.Name), role)
+}
+
+// LoadRole gets tenant role by name
+func (r *RoleStorage) LoadRole(tenantID, name string) (*model.Role, bool) {
+	role, ok := r.roles.Load(model.TenantKey(tenantID, name))
+	if !ok {
+		return nil, ok
+	}
+	return role.(*model.Role), ok
+}
+
+// DeleteRole deletes tenant role by name and unassigns it from all tenant users
+func (r *RoleStorage) DeleteRole(tenantID, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles.Delete(model.TenantKey(tenantID, name))
+	prefix := model.TenantKey(tenantID, "")
+	r.assignments.Range(func(key, roles interface{}) bool {
+		if !strings.HasPrefix(key.(string), prefix) {
+			return true
+		}
+		remaining := make([]string, 0, len(roles.([]string)))
+		for _, assigned := range roles.([]string) {
+			if assigned != name {
+				remaining = append(remaining, assigned)
+			}
+		}
+		r.assignments.Store(key, remaining)
+		return true
+	})
+}
+
+// ListRoles gets all tenant roles sorted by name
+func (r *RoleStorage) ListRoles(tenantID string) []*model.Role {
+	prefix := model.TenantKey(tenantID, "")
+	roles := make([]*model.Role, 0)
+	r.roles.Range(func(key, role interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			roles = append(roles, role.(*model.Role))
+		}
+		return true
+	})
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles
+}
+
+// AssignRole assigns tenant role to user
+func (r *RoleStorage) AssignRole(tenantID, userID, role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	roles := r.LoadUserRoles(tenantID, userID)
+	for _, assigned := range roles {
+		if assigned == role {
+			return
+		}
+	}
+	r.assignments.Store(model.TenantKey(tenantID, userID), append(roles, role))
+}
+
+// UnassignRole removes tenant role from user
+func (r *RoleStorage) UnassignRole(tenantID, userID, role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	roles := r.LoadUserRoles(tenantID, userID)
+	remaining := make([]string, 0, len(roles))
+	for _, assigned := range roles {
+		if assigned != role {
+			remaining = append(remaining, assigned)
+		}
+	}
+	r.assignments.Store(model.TenantKey(tenantID, userID), remaining)
+}
+
+// LoadUserRoles gets tenant roles directly assigned to user
+func (r *RoleStorage) LoadUserRoles(tenantID, userID string) []string {
+	roles, ok := r.assignments.Load(model.TenantKey(tenantID, userID))
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), roles.([]string)...)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockRole   = "editor"
+	mockUserID = "example_user_id"
+)
+
+// TestSaveRole tests the SaveRole, LoadRole and DeleteRole methods
+func TestSaveRole(t *testing.T) {
+	storage := &sync.Map{}
+	role := &model.Role{Name: mockRole, Permissions: []string{"documents:write"}}
+	roleStorage := NewRoleStorage(storage, &sync.Map{})
+
+	t.Log("Save the role to the storage")
+	roleStorage.SaveRole(mockTenantID, role)
+
+	t.Log("Verify that the role is stored in the storage")
+	loadedRole, loaded := roleStorage.LoadRole(mockTenantID, mockRole)
+	assert.True(t, loaded, "Role was not stored in the storage")
+	assert.Equal(t, role, loadedRole, "Stored role mismatch")
+	assert.Equal(t, []*model.Role{role}, roleStorage.ListRoles(mockTenantID), "Listed roles mismatch")
+	assert.Empty(t, roleStorage.ListRoles("other_tenant"), "Role leaked to other tenant")
+
+	t.Log("Delete the role and verify that it is removed from the storage")
+	roleStorage.DeleteRole(mockTenantID, mockRole)
+	_, loaded = storage.Load(model.TenantKey(mockTenantID, mockRole))
+	assert.False(t, loaded, "Role was not deleted from storage")
+}
+
+// TestAssignRole tests the AssignRole, UnassignRole and LoadUserRoles methods
+func TestAssignRole(t *testing.T) {
+	roleStorage := NewRoleStorage(&sync.Map{}, &sync.Map{})
+
+	t.Log("Assign the role twice")
+	roleStorage.AssignRole(mockTenantID, mockUserID, mockRole)
+	roleStorage.AssignRole(mockTenantID, mockUserID, mockRole)
+
+	t.Log("Verify that the role is assigned once")
+	assert.Equal(t, []string{mockRole}, roleStorage.LoadUserRoles(mockTenantID, mockUserID), "Assigned roles mismatch")
+	assert.Empty(t, roleStorage.LoadUserRoles("other_tenant", mockUserID), "Role assignment leaked to other tenant")
+
+	t.Log("Unassign the role and verify that it is removed")
+	roleStorage.UnassignRole(mockTenantID, mockUserID, mockRole)
+	assert.Empty(t, roleStorage.LoadUserRoles(mockTenantID, mockUserID), "Role was not unassigned")
+
+	t.Log("Delete the role and verify that its assignments in the tenant are removed")
+	roleStorage.AssignRole(mockTenantID, mockUserID, mockRole)
+	roleStorage.AssignRole(mockTenantID, mockUserID, "viewer")
+	roleStorage.AssignRole("other_tenant", mockUserID, mockRole)
+	roleStorage.DeleteRole(mockTenantID, mockRole)
+	assert.Equal(t, []string{"viewer"}, roleStorage.LoadUserRoles(mockTenantID, mockUserID), "Deleted role is still assigned")
+	assert.Equal(t, []string{mockRole}, roleStorage.LoadUserRoles("other_tenant", mockUserID), "Role of other tenant was unassigned")
+}
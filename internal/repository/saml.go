@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// SAMLRequestStorage pending SAML logins storage, keyed by relay state hash
+type SAMLRequestStorage struct {
+	requests *sync.Map
+}
+
+// NewSAMLRequestStorage creates new SAML request storage
+func NewSAMLRequestStorage(requests *sync.Map) *SAMLRequestStorage {
+	return &SAMLRequestStorage{requests: requests}
+}
+
+// Save stores pending login by relay state hash
+func (s *SAMLRequestStorage) Save(relayStateHash string, request *model.SAMLRequest) {
+	s.requests.Store(relayStateHash, request)
+}
+
+// LoadAndDelete gets pending login by relay state hash and removes it, so relay state can be used once
+func (s *SAMLRequestStorage) LoadAndDelete(relayStateHash string) (*model.SAMLRequest, bool) {
+	request, ok := s.requests.LoadAndDelete(relayStateHash)
+	if !ok {
+		return nil, ok
+	}
+	return request.(*model.SAMLRequest), ok
+}
+
+// Cleanup removes expired pending logins
+func (s *SAMLRequestStorage) Cleanup() {
+	now := time.Now().Unix()
+	s.requests.Range(func(relayStateHash, request interface{}) bool {
+		if request.(*model.SAMLRequest).ExpiresAt <= now {
+			s.requests.Delete(relayStateHash)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired pending logins until ctx is done
+func (s *SAMLRequestStorage) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+			log.Debug("SAMLRequestStorage / RunCleanup / expired SAML requests removed")
+		}
+	}
+}
+
+// SAMLAssertionCache remembers consumed assertion IDs until assertions expire
+type SAMLAssertionCache struct {
+	assertions *sync.Map
+}
+
+// NewSAMLAssertionCache creates new SAML assertion cache
+func NewSAMLAssertionCache(assertions *sync.Map) *SAMLAssertionCache {
+	return &SAMLAssertionCache{assertions: assertions}
+}
+
+// Remember records assertion ID until expiresAt, false means the assertion was already consumed
+func (c *SAMLAssertionCache) Remember(assertionID string, expiresAt int64) bool {
+	_, loaded := c.assertions.LoadOrStore(assertionID, expiresAt)
+	return !loaded
+}
+
+// Cleanup removes expired assertion IDs
+func (c *SAMLAssertionCache) Cleanup() {
+	now := time.Now().Unix()
+	c.assertions.Range(func(assertionID, expiresAt interface{}) bool {
+		if expiresAt.(int64) <= now {
+			c.assertions.Delete(assertionID)
+		}
+		return true
+	})
+}
+
+// RunCleanup periodically removes expired assertion IDs until ctx is done
+func (c *SAMLAssertionCache) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Cleanup()
+			log.Debug("SAMLAssertionCache / RunCleanup / expired SAML assertions removed")
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockRelayStateHash = "example_relay_state_hash"
+
+// TestSAMLRequestLoadAndDelete tests the Save and LoadAndDelete methods
+func TestSAMLRequestLoadAndDelete(t *testing.T) {
+	storage := NewSAMLRequestStorage(&sync.Map{})
+	request := &model.SAMLRequest{ProviderID: "example_provider", RequestID: "id-1",
+		ExpiresAt: time.Now().Add(time.Minute).Unix()}
+
+	t.Log("Save the SAML request to the storage")
+	storage.Save(mockRelayStateHash, request)
+
+	t.Log("Verify that the SAML request is loaded once")
+	loadedRequest, loaded := storage.LoadAndDelete(mockRelayStateHash)
+	assert.True(t, loaded, "SAML request was not stored in the storage")
+	assert.Equal(t, request, loadedRequest, "Loaded SAML request mismatch")
+	_, loaded = storage.LoadAndDelete(mockRelayStateHash)
+	assert.False(t, loaded, "SAML request was loaded twice")
+}
+
+// TestSAMLRequestCleanup tests that Cleanup removes expired SAML requests only
+func TestSAMLRequestCleanup(t *testing.T) {
+	storage := NewSAMLRequestStorage(&sync.Map{})
+	storage.Save(mockRelayStateHash, &model.SAMLRequest{ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	storage.Save("other_relay_state_hash", &model.SAMLRequest{ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	t.Log("Run cleanup and verify that only the expired SAML request is removed")
+	storage.Cleanup()
+	_, loaded := storage.LoadAndDelete(mockRelayStateHash)
+	assert.False(t, loaded, "Expired SAML request was not removed")
+	_, loaded = storage.LoadAndDelete("other_relay_state_hash")
+	assert.True(t, loaded, "Active SAML request was removed")
+}
+
+// TestSAMLAssertionRemember tests that assertion IDs are accepted once until they expire
+func TestSAMLAssertionRemember(t *testing.T) {
+	cache := NewSAMLAssertionCache(&sync.Map{})
+
+	t.Log("Verify that the assertion is accepted once")
+	assert.True(t, cache.Remember("assertion-1", time.Now().Add(time.Minute).Unix()), "Assertion was not accepted")
+	assert.False(t, cache.Remember("assertion-1", time.Now().Add(time.Minute).Unix()), "Assertion was accepted twice")
+
+	t.Log("Run cleanup and verify that only the expired assertion is forgotten")
+	assert.True(t, cache.Remember("assertion-2", time.Now().Add(-time.Minute).Unix()), "Assertion was not accepted")
+	cache.Cleanup()
+	assert.True(t, cache.Remember("assertion-2", time.Now().Add(time.Minute).Unix()), "Expired assertion was not forgotten")
+	assert.False(t, cache.Remember("assertion-1", time.Now().Add(time.Minute).Unix()), "Active assertion was forgotten")
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/Entetry/authService/internal/model"
+)
+
+// TOTPSecretStorage authenticator app secrets storage keyed by user id, secrets are kept in file
+// readable by the service only, empty file name keeps them in memory
+type TOTPSecretStorage struct {
+	mu      sync.Mutex
+	name    string
+	secrets map[string]*model.TOTPSecret
+}
+
+// NewTOTPSecretStorage creates new TOTP secrets storage loading secrets saved in file
+func NewTOTPSecretStorage(name string) (*TOTPSecretStorage, error) {
+	s := &TOTPSecretStorage{name: name, secrets: make(map[string]*model.TOTPSecret)}
+	if name == "" {
+		return s, nil
+	}
+	if err := readJSONFile(name, &s.secrets); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load gets secret of user
+func (s *TOTPSecretStorage) Load(userID string) (*model.TOTPSecret, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[userID]
+	if !ok {
+		return nil, false
+	}
+	loaded := *secret
+	return &loaded, true
+}
+
+// Save stores secret of user
+func (s *TOTPSecretStorage) Save(userID string, secret *model.TOTPSecret) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *secret
+	previous, ok := s.secrets[userID]
+	s.secrets[userID] = &saved
+	if err := s.flush(); err != nil {
+		if ok {
+			s.secrets[userID] = previous
+		} else {
+			delete(s.secrets, userID)
+		}
+		return err
+	}
+	return nil
+}
+
+// UseStep marks time step used by user, false means the step or a later one was already used
+func (s *TOTPSecretStorage) UseStep(userID string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[userID]
+	if !ok || step <= secret.LastStep {
+		return false, nil
+	}
+	previous := secret.LastStep
+	secret.LastStep = step
+	if err := s.flush(); err != nil {
+		secret.LastStep = previous
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *TOTPSecretStorage) flush() error {
+	if s.name == "" {
+		return nil
+	}
+	return writeJSONFile(s.name, s.secrets)
+}
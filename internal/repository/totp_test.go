@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTOTPSecretStorage tests the Save, Load and UseStep methods
+func TestTOTPSecretStorage(t *testing.T) {
+	storage, err := NewTOTPSecretStorage("")
+	assert.NoError(t, err, "Expected no error when creating in memory storage")
+
+	t.Log("Save the secret and verify that it can be loaded")
+	assert.NoError(t, storage.Save(mockUserID, &model.TOTPSecret{Secret: "SECRET", LastStep: 10}),
+		"Expected no error when saving secret")
+	secret, loaded := storage.Load(mockUserID)
+	assert.True(t, loaded, "Secret was not stored")
+	assert.Equal(t, &model.TOTPSecret{Secret: "SECRET", LastStep: 10}, secret, "Loaded secret mismatch")
+
+	t.Log("Verify that each time step is used once")
+	used, err := storage.UseStep(mockUserID, 10)
+	assert.NoError(t, err, "Expected no error when using step")
+	assert.False(t, used, "Step used on save was accepted")
+	used, err = storage.UseStep(mockUserID, 11)
+	assert.NoError(t, err, "Expected no error when using step")
+	assert.True(t, used, "Later step was not accepted")
+	used, err = storage.UseStep("other_user", 11)
+	assert.NoError(t, err, "Expected no error when using step")
+	assert.False(t, used, "Step of user without secret was accepted")
+}
+
+// TestTOTPSecretStorageFile tests that secrets survive storage restart
+func TestTOTPSecretStorageFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "totp.json")
+	storage, err := NewTOTPSecretStorage(name)
+	assert.NoError(t, err, "Expected no error when creating storage without file")
+	assert.NoError(t, storage.Save(mockUserID, &model.TOTPSecret{Secret: "SECRET", LastStep: 10}),
+		"Expected no error when saving secret")
+	_, err = storage.UseStep(mockUserID, 12)
+	assert.NoError(t, err, "Expected no error when using step")
+
+	t.Log("Reopen storage and verify that secret and last step are loaded from file")
+	storage, err = NewTOTPSecretStorage(name)
+	assert.NoError(t, err, "Expected no error when loading storage file")
+	secret, loaded := storage.Load(mockUserID)
+	assert.True(t, loaded, "Secret was not persisted")
+	assert.Equal(t, &model.TOTPSecret{Secret: "SECRET", LastStep: 12}, secret, "Persisted secret mismatch")
+}
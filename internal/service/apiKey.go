@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix marks personal access tokens, so secret scanners and ValidateTokens can recognize them
+const APIKeyPrefix = "eauth_"
+
+const (
+	apiKeySecretLength   = 40
+	apiKeyChecksumLength = 6
+	base62Alphabet       = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	// ErrInvalidAPIKey godoc
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyExpired godoc
+	ErrAPIKeyExpired = errors.New("API key is expired")
+	// ErrAPIKeyNotFound godoc
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrAPIKeyAddressNotAllowed godoc
+	ErrAPIKeyAddressNotAllowed = errors.New("API key is not allowed from this address")
+	// ErrTooManyAPIKeys godoc
+	ErrTooManyAPIKeys = errors.New("too many API keys")
+	// ErrInvalidAPIKeyName godoc
+	ErrInvalidAPIKeyName = errors.New("API key name is required")
+	// ErrInvalidAPIKeyExpiration godoc
+	ErrInvalidAPIKeyExpiration = errors.New("invalid API key expiration")
+	// ErrInvalidAllowedAddress godoc
+	ErrInvalidAllowedAddress = errors.New("invalid allowed address")
+)
+
+// APIKeyStorage used to store personal access tokens by secret hash
+type APIKeyStorage interface {
+	Save(key *model.APIKey)
+	Load(hash string) (*model.APIKey, bool)
+	List(tenantID, userID string) []*model.APIKey
+	Delete(tenantID, userID, id string) bool
+	Touch(hash string, usedAt int64)
+}
+
+// APIKeys personal access tokens service struct
+type APIKeys struct {
+	cfg     *config.APIKeyConfig
+	auth    *Auth
+	storage APIKeyStorage
+}
+
+// NewAPIKeyService creates new APIKeys service
+func NewAPIKeyService(cfg *config.APIKeyConfig, auth *Auth, storage APIKeyStorage) *APIKeys {
+	return &APIKeys{cfg: cfg, auth: auth, storage: storage}
+}
+
+// IsAPIKey checks if token looks like a personal access token rather than a JWT
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// CreateAPIKey creates personal access token for the access token user, the secret is returned only once.
+// Access token must be issued to the user directly, not exchanged, and carry recent authentication.
+// Key scope can't exceed access token scope, zero expiresIn falls back to the maximum key lifetime.
+// Allowed addresses are IPs or CIDR ranges the key is accepted from.
+func (k *APIKeys) CreateAPIKey(ctx context.Context, accessToken, name, scope string, expiresIn time.Duration,
+	allowedAddresses []string) (secret string, key *model.APIKey, err error) {
+	claims, err := k.owner(ctx, accessToken)
+	if err != nil {
+		return "", nil, err
+	}
+	if claims.Act != nil || claims.GrantType == GrantTypeTokenExchange {
+		return "", nil, ErrPermissionDenied
+	}
+	if k.cfg.MaxAuthAge > 0 && time.Since(time.Unix(claims.AuthTime, 0)) > k.cfg.MaxAuthAge {
+		return "", nil, ErrAuthenticationTooOld
+	}
+	if strings.TrimSpace(name) == "" {
+		return "", nil, ErrInvalidAPIKeyName
+	}
+	scope, err = downscope(claims.Scope, scope)
+	if err != nil {
+		return "", nil, err
+	}
+	if expiresIn < 0 || k.cfg.MaxExpiration > 0 && expiresIn > k.cfg.MaxExpiration {
+		return "", nil, ErrInvalidAPIKeyExpiration
+	}
+	if expiresIn == 0 {
+		expiresIn = k.cfg.MaxExpiration
+	}
+	allowedCIDRs, err := parseAllowedAddresses(allowedAddresses)
+	if err != nil {
+		return "", nil, err
+	}
+	if k.cfg.MaxPerUser > 0 && len(k.storage.List(claims.TenantID, claims.Subject)) >= k.cfg.MaxPerUser {
+		return "", nil, ErrTooManyAPIKeys
+	}
+
+	secret, err = newAPIKeySecret()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	key = &model.APIKey{
+		ID:           uuid.New().String(),
+		Hash:         hashToken(secret),
+		TenantID:     claims.TenantID,
+		UserID:       claims.Subject,
+		Username:     claims.Username,
+		Name:         name,
+		Scope:        scope,
+		AllowedCIDRs: allowedCIDRs,
+		CreatedAt:    now.Unix(),
+	}
+	if expiresIn > 0 {
+		key.ExpiresAt = now.Add(expiresIn).Unix()
+	}
+	k.storage.Save(key)
+	k.auth.auditLogger.Emit(&model.AuditEvent{
+		Type:      model.AuditEventAPIKeyCreated,
+		TenantID:  claims.TenantID,
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Time:      now.Unix(),
+	})
+
+	return secret, key, nil
+}
+
+// ListAPIKeys lists personal access tokens of the access token user
+func (k *APIKeys) ListAPIKeys(ctx context.Context, accessToken string) ([]*model.APIKey, error) {
+	claims, err := k.owner(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return k.storage.List(claims.TenantID, claims.Subject), nil
+}
+
+// RevokeAPIKey deletes personal access token of the access token user, it is rejected right away
+func (k *APIKeys) RevokeAPIKey(ctx context.Context, accessToken, id string) error {
+	claims, err := k.owner(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if !k.storage.Delete(claims.TenantID, claims.Subject, id) {
+		return ErrAPIKeyNotFound
+	}
+	k.auth.auditLogger.Emit(&model.AuditEvent{
+		Type:      model.AuditEventAPIKeyRevoked,
+		TenantID:  claims.TenantID,
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Time:      time.Now().Unix(),
+	})
+
+	return nil
+}
+
+// ValidateAPIKey validates personal access token presented from clientIP and returns claims an access token
+// of the key user would carry. Roles are resolved on every use, authentication time is key creation time.
+// Keys don't survive revocation of all user tokens, Auth deletes them along with the sessions.
+func (k *APIKeys) ValidateAPIKey(ctx context.Context, secret, clientIP string, opts ValidateOptions) (*Claim, error) {
+	if !validAPIKeyChecksum(secret) {
+		return nil, ErrInvalidAPIKey
+	}
+	tenant, err := k.auth.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hash := hashToken(secret)
+	key, ok := k.storage.Load(hash)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.TenantID != tenant.ID {
+		return nil, ErrInvalidTokenTenant
+	}
+	now := time.Now()
+	if key.ExpiresAt != 0 && key.ExpiresAt <= now.Unix() {
+		return nil, ErrAPIKeyExpired
+	}
+	if !addressAllowed(key.AllowedCIDRs, clientIP) {
+		return nil, ErrAPIKeyAddressNotAllowed
+	}
+
+	claims := &Claim{
+		StandardClaims: jwt.StandardClaims{
+			Id:        key.ID,
+			Issuer:    k.auth.cfg.Issuer,
+			Subject:   key.UserID,
+			Audience:  k.auth.cfg.Audience,
+			IssuedAt:  key.CreatedAt,
+			ExpiresAt: key.ExpiresAt,
+		},
+		Username:      key.Username,
+		TenantID:      key.TenantID,
+		AuthTime:      key.CreatedAt,
+		Scope:         key.Scope,
+		EmailVerified: k.auth.emailVerified(key.UserID),
+	}
+	claims.Roles, claims.Permissions = resolveRoles(k.auth.roleStorage, key.TenantID, key.UserID)
+	if err = k.auth.checkValidateOptions(claims, opts); err != nil {
+		return nil, err
+	}
+	k.storage.Touch(hash, now.Unix())
+
+	return claims, nil
+}
+
+// owner validates access token managing personal access tokens
+func (k *APIKeys) owner(ctx context.Context, accessToken string) (*Claim, error) {
+	claims, err := k.auth.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return claims, nil
+}
+
+// newAPIKeySecret returns prefixed base62 random secret followed by CRC32 checksum of the random part,
+// so leaked keys can be told from random strings without a lookup
+func newAPIKeySecret() (string, error) {
+	random, err := randomBase62(apiKeySecretLength)
+	if err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + random + apiKeyChecksum(random), nil
+}
+
+func validAPIKeyChecksum(secret string) bool {
+	if !IsAPIKey(secret) || len(secret) != len(APIKeyPrefix)+apiKeySecretLength+apiKeyChecksumLength {
+		return false
+	}
+	random := secret[len(APIKeyPrefix) : len(secret)-apiKeyChecksumLength]
+	return secret[len(secret)-apiKeyChecksumLength:] == apiKeyChecksum(random)
+}
+
+// apiKeyChecksum returns CRC32 of random part as zero padded base62
+func apiKeyChecksum(random string) string {
+	sum := crc32.ChecksumIEEE([]byte(random))
+	checksum := make([]byte, apiKeyChecksumLength)
+	for i := apiKeyChecksumLength - 1; i >= 0; i-- {
+		checksum[i] = base62Alphabet[sum%62]
+		sum /= 62
+	}
+	return string(checksum)
+}
+
+// randomBase62 returns random base62 string, bytes are rejection sampled so every character is equally likely
+func randomBase62(length int) (string, error) {
+	const limit = 256 - 256%len(base62Alphabet)
+	result := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(result) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(result) < length {
+				result = append(result, base62Alphabet[int(b)%len(base62Alphabet)])
+			}
+		}
+	}
+	return string(result), nil
+}
+
+// parseAllowedAddresses normalizes IPs and CIDR ranges to CIDR notation
+func parseAllowedAddresses(addresses []string) ([]string, error) {
+	cidrs := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			address = (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String()
+		}
+		_, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, ErrInvalidAllowedAddress
+		}
+		cidrs = append(cidrs, ipNet.String())
+	}
+	return cidrs, nil
+}
+
+// addressAllowed checks client IP against allow-list, empty allow-list accepts any address
+func addressAllowed(allowedCIDRs []string, clientIP string) bool {
+	if len(allowedCIDRs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range allowedCIDRs {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/repository"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newAPIKeyTestService(t *testing.T) (*APIKeys, *Auth, *mocks.APIKeyStorage) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:        mockAccessTokenKey,
+		AccessTokenExpiration: 30 * time.Minute,
+		Issuer:                mockIssuer}
+	auth := newAuthFixture(t, &cfg, AuthOptions{}).auth
+	mockStorage := mocks.NewAPIKeyStorage(t)
+
+	return NewAPIKeyService(&config.APIKeyConfig{MaxExpiration: 90 * 24 * time.Hour, MaxPerUser: 2,
+		MaxAuthAge: 15 * time.Minute}, auth, mockStorage), auth, mockStorage
+}
+
+func TestAPIKeys_CreateAPIKey(t *testing.T) {
+	apiKeys, auth, mockStorage := newAPIKeyTestService(t)
+	accessToken := newTestAccessToken(t, auth, &Claim{Scope: "documents:read documents:write", AuthTime: time.Now().Unix()})
+	var saved *model.APIKey
+	mockStorage.On("List", "", mockUserID).Return(nil)
+	mockStorage.On("Save", mock.AnythingOfType("*model.APIKey")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*model.APIKey) }).Return()
+
+	secret, key, err := apiKeys.CreateAPIKey(context.Background(), accessToken, "ci", "documents:read",
+		24*time.Hour, []string{"10.0.0.1", "192.168.0.0/16", "2001:db8::/32"})
+	assert.NoError(t, err, "Expected no error when creating API key")
+	assert.True(t, strings.HasPrefix(secret, APIKeyPrefix), "Expected recognizable key prefix")
+	assert.True(t, validAPIKeyChecksum(secret), "Expected valid key checksum")
+	assert.Equal(t, key, saved, "Expected created key to be stored")
+	assert.Equal(t, hashToken(secret), saved.Hash, "Key must be stored hashed")
+	assert.Equal(t, mockUserID, key.UserID, "Key owner mismatch")
+	assert.Equal(t, "documents:read", key.Scope, "Key scope mismatch")
+	assert.Equal(t, []string{"10.0.0.1/32", "192.168.0.0/16", "2001:db8::/32"}, key.AllowedCIDRs)
+	assert.InDelta(t, time.Now().Add(24*time.Hour).Unix(), key.ExpiresAt, 1, "Key expiration mismatch")
+
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), accessToken, "ci", "admin", 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidScope, "Expected key scope not to exceed access token scope")
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), accessToken, "ci", "", 365*24*time.Hour, nil)
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyExpiration, "Expected key lifetime to be capped")
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), accessToken, "ci", "", 0, []string{"10.0.0.0/33"})
+	assert.ErrorIs(t, err, ErrInvalidAllowedAddress, "Expected invalid CIDR to be rejected")
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), accessToken, " ", "", 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyName, "Expected key name to be required")
+
+	_, key, err = apiKeys.CreateAPIKey(context.Background(), accessToken, "ci", "", 0, nil)
+	assert.NoError(t, err, "Expected no error when creating API key")
+	assert.InDelta(t, time.Now().Add(90*24*time.Hour).Unix(), key.ExpiresAt, 1, "Expected maximum lifetime by default")
+
+	impersonated := newTestAccessToken(t, auth, &Claim{Act: &ActorClaim{Subject: "admin"}, AuthTime: time.Now().Unix()})
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), impersonated, "ci", "", 0, nil)
+	assert.ErrorIs(t, err, ErrPermissionDenied, "Expected delegated token not to create keys")
+	exchanged := newTestAccessToken(t, auth, &Claim{GrantType: GrantTypeTokenExchange, AuthTime: time.Now().Unix()})
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), exchanged, "ci", "", 0, nil)
+	assert.ErrorIs(t, err, ErrPermissionDenied, "Expected exchanged token not to create keys")
+	stale := newTestAccessToken(t, auth, &Claim{AuthTime: time.Now().Add(-time.Hour).Unix()})
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), stale, "ci", "", 0, nil)
+	assert.ErrorIs(t, err, ErrAuthenticationTooOld, "Expected recent authentication to create keys")
+
+	_, _, err = apiKeys.CreateAPIKey(context.Background(), "invalid", "ci", "", 0, nil)
+	assert.ErrorIs(t, err, ErrUnauthenticated, "Expected ErrUnauthenticated for invalid access token")
+}
+
+func TestAPIKeys_CreateAPIKey_TooMany(t *testing.T) {
+	apiKeys, auth, mockStorage := newAPIKeyTestService(t)
+	accessToken := newTestAccessToken(t, auth, &Claim{AuthTime: time.Now().Unix()})
+	mockStorage.On("List", "", mockUserID).Return([]*model.APIKey{{ID: "first"}, {ID: "second"}})
+
+	_, _, err := apiKeys.CreateAPIKey(context.Background(), accessToken, "ci", "", 0, nil)
+	assert.ErrorIs(t, err, ErrTooManyAPIKeys, "Expected ErrTooManyAPIKeys")
+}
+
+func TestAPIKeys_ValidateAPIKey(t *testing.T) {
+	apiKeys, _, mockStorage := newAPIKeyTestService(t)
+	secret, err := newAPIKeySecret()
+	assert.NoError(t, err, "Expected no error when generating key secret")
+	hash := hashToken(secret)
+	key := &model.APIKey{
+		ID:           "key",
+		Hash:         hash,
+		UserID:       mockUserID,
+		Username:     mockUsername,
+		Scope:        "documents:read",
+		AllowedCIDRs: []string{"10.0.0.0/8"},
+		CreatedAt:    time.Now().Add(-time.Hour).Unix(),
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}
+	mockStorage.On("Load", hash).Return(key, true)
+	mockStorage.On("Touch", hash, mock.AnythingOfType("int64")).Return().Once()
+
+	claims, err := apiKeys.ValidateAPIKey(context.Background(), secret, "10.1.2.3", ValidateOptions{Issuer: mockIssuer})
+	assert.NoError(t, err, "Expected no error when validating API key")
+	assert.Equal(t, mockUserID, claims.Subject, "Subject mismatch")
+	assert.Equal(t, mockUsername, claims.Username, "Username mismatch")
+	assert.Equal(t, "documents:read", claims.Scope, "Scope mismatch")
+
+	_, err = apiKeys.ValidateAPIKey(context.Background(), secret, "172.16.0.1", ValidateOptions{})
+	assert.ErrorIs(t, err, ErrAPIKeyAddressNotAllowed, "Expected address outside allow-list to be rejected")
+	_, err = apiKeys.ValidateAPIKey(context.Background(), secret, "", ValidateOptions{})
+	assert.ErrorIs(t, err, ErrAPIKeyAddressNotAllowed, "Expected unknown address to be rejected")
+	_, err = apiKeys.ValidateAPIKey(context.Background(), secret, "10.1.2.3", ValidateOptions{MaxAuthAge: time.Minute})
+	assert.ErrorIs(t, err, ErrAuthenticationTooOld, "Expected max auth age to apply to key creation")
+
+	tampered := secret[:len(secret)-1] + string(base62Alphabet[(strings.IndexByte(base62Alphabet, secret[len(secret)-1])+1)%62])
+	_, err = apiKeys.ValidateAPIKey(context.Background(), tampered, "10.1.2.3", ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "Expected checksum mismatch to be rejected without lookup")
+
+	key.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	_, err = apiKeys.ValidateAPIKey(context.Background(), secret, "10.1.2.3", ValidateOptions{})
+	assert.ErrorIs(t, err, ErrAPIKeyExpired, "Expected expired key to be rejected")
+}
+
+func TestAPIKeys_ValidateAPIKey_SubjectRevoked(t *testing.T) {
+	denylist := repository.NewAccessTokenDenylist(&sync.Map{}, &sync.Map{}, 100, 0.01)
+	storage := repository.NewAPIKeyStorage(&sync.Map{})
+	f := newAuthFixture(t, &config.JwtConfig{AccessTokenKey: mockAccessTokenKey, AccessTokenExpiration: time.Second},
+		AuthOptions{TokenDenylist: denylist, APIKeyStorage: storage})
+	apiKeys := NewAPIKeyService(&config.APIKeyConfig{}, f.auth, storage)
+	secret, err := newAPIKeySecret()
+	assert.NoError(t, err, "Expected no error when generating key secret")
+	createdAt := time.Now().Add(-time.Hour)
+	storage.Save(&model.APIKey{ID: "key", Hash: hashToken(secret), UserID: mockUserID, Username: mockUsername,
+		CreatedAt: createdAt.Unix()})
+	_, accessToken, err := f.auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	t.Log("Step 1: signing out everywhere deletes user keys")
+	assert.NoError(t, f.auth.SignOutEverywhere(context.Background(), accessToken), "Expected no error when signing out everywhere")
+	revocationExpiresAt := time.Now().Add(time.Second).Unix()
+	_, err = apiKeys.ValidateAPIKey(context.Background(), secret, "", ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "Expected key of user signed out everywhere to be rejected")
+
+	t.Log("Step 2: key stays rejected once subject revocation is cleaned up after access token lifetime")
+	time.Sleep(time.Until(time.Unix(revocationExpiresAt, 0)))
+	denylist.Cleanup()
+	assert.False(t, denylist.IsSubjectRevoked(model.TenantKey("", mockUsername), createdAt.UnixNano()),
+		"Expected subject revocation to be cleaned up")
+	_, err = apiKeys.ValidateAPIKey(context.Background(), secret, "", ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "Expected old key to stay rejected after cleanup")
+}
+
+func TestAPIKeys_RevokeAPIKey(t *testing.T) {
+	apiKeys, auth, mockStorage := newAPIKeyTestService(t)
+	accessToken := newTestAccessToken(t, auth, &Claim{})
+	mockStorage.On("Delete", "", mockUserID, "key").Return(true).Once()
+	mockStorage.On("Delete", "", mockUserID, "key").Return(false).Once()
+
+	err := apiKeys.RevokeAPIKey(context.Background(), accessToken, "key")
+	assert.NoError(t, err, "Expected no error when revoking API key")
+	err = apiKeys.RevokeAPIKey(context.Background(), accessToken, "key")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound, "Expected ErrAPIKeyNotFound for revoked key")
+}
@@ -4,6 +4,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Entetry/authService/internal/config"
@@ -11,8 +13,8 @@ import (
 	"github.com/Entetry/userService/protocol/userService"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	lru "github.com/hashicorp/golang-lru/v2"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -28,37 +30,168 @@ var (
 	ErrInvalidTokenClaims = errors.New("invalid token claims")
 	// ErrInvalidPassword godoc
 	ErrInvalidPassword = errors.New("invalid token claims")
+	// ErrAccessTokenRevoked godoc
+	ErrAccessTokenRevoked = errors.New("access token is revoked")
+	// ErrAccessTokenExpired godoc
+	ErrAccessTokenExpired = errors.New("access token is expired")
+	// ErrAccessTokenNotValidYet godoc
+	ErrAccessTokenNotValidYet = errors.New("access token is not valid yet")
+	// ErrInvalidTokenIssuer godoc
+	ErrInvalidTokenIssuer = errors.New("invalid token issuer")
+	// ErrInvalidTokenAudience godoc
+	ErrInvalidTokenAudience = errors.New("invalid token audience")
+	// ErrSessionIdleTimeout godoc
+	ErrSessionIdleTimeout = errors.New("session idle timeout exceeded")
+	// ErrSessionLifetimeExceeded godoc
+	ErrSessionLifetimeExceeded = errors.New("session maximum lifetime exceeded")
+	// ErrAuthenticationTooOld godoc
+	ErrAuthenticationTooOld = errors.New("authentication is too old")
+	// ErrInvalidUsername godoc
+	ErrInvalidUsername = errors.New("invalid username")
+	// ErrMissingDependency godoc
+	ErrMissingDependency = errors.New("missing required dependency")
 )
 
 // SessionStorage used to store sessions
 type SessionStorage interface {
-	LoadAndDelete(refreshToken string) (*model.Session, bool)
-	Load(username string) (*model.Session, bool)
+	LoadAndDelete(tenantID, username string) (*model.Session, bool)
+	Load(tenantID, username string) (*model.Session, bool)
 	SaveSession(session *model.Session)
-	Delete(refreshToken string)
+	Delete(tenantID, username string)
+}
+
+// TokenDenylist used to store revoked access tokens, subject revocation and issue times are Unix nanoseconds
+type TokenDenylist interface {
+	Revoke(jti string, expiresAt int64)
+	IsRevoked(jti string) bool
+	RevokeSubject(subject string, revokedAt, expiresAt int64)
+	IsSubjectRevoked(subject string, issuedAt int64) bool
+}
+
+// AuditLogger used to emit audit events
+type AuditLogger interface {
+	Emit(event *model.AuditEvent)
 }
 
 // Claim Jwt Claim struct
 type Claim struct {
-	Username string
+	Username      string
+	TenantID      string        `json:"tid"`
+	ClientID      string        `json:"client_id,omitempty"`
+	AuthTime      int64         `json:"auth_time,omitempty"`
+	AMR           []string      `json:"amr,omitempty"`
+	ACR           string        `json:"acr,omitempty"`
+	SessionID     string        `json:"sid,omitempty"`
+	Scope         string        `json:"scope,omitempty"`
+	Roles         []string      `json:"roles,omitempty"`
+	Permissions   []string      `json:"perms,omitempty"`
+	EmailVerified bool          `json:"email_verified"`
+	Act           *ActorClaim   `json:"act,omitempty"`
+	Cnf           *Confirmation `json:"cnf,omitempty"`
+	GrantType     string        `json:"gty,omitempty"`
+	IssuedAtNano  int64         `json:"iat_ns,omitempty"`
 	jwt.StandardClaims
 }
 
+// issuedAt returns token issue time in Unix nanoseconds, subject revocations are compared against it.
+// Tokens without iat_ns count as issued at the start of their iat second.
+func (c *Claim) issuedAt() int64 {
+	if c.IssuedAtNano != 0 {
+		return c.IssuedAtNano
+	}
+	return time.Unix(c.IssuedAt, 0).UnixNano()
+}
+
+// Confirmation key the token is bound to, see RFC 7800. JKT is the JWK SHA-256 thumbprint of DPoP key,
+// X5T the SHA-256 thumbprint of mutual TLS client certificate.
+type Confirmation struct {
+	JKT string `json:"jkt,omitempty"`
+	X5T string `json:"x5t#S256,omitempty"`
+}
+
+// ActorClaim acting party of delegated or impersonated token, see RFC 8693 section 4.1
+type ActorClaim struct {
+	Subject string      `json:"sub"`
+	Act     *ActorClaim `json:"act,omitempty"`
+}
+
+// ValidateOptions expected token properties, empty values fall back to service defaults or are not checked.
+// MaxAuthAge demands user authenticated no longer than given duration ago.
+type ValidateOptions struct {
+	Issuer     string
+	Audience   string
+	MaxAuthAge time.Duration
+}
+
 // Auth service struct
 type Auth struct {
-	cfg               *config.JwtConfig
-	sessionStorage    SessionStorage
-	userServiceClient userService.UserServiceClient
+	cfg                      *config.JwtConfig
+	sessionStorage           SessionStorage
+	tokenDenylist            TokenDenylist
+	roleStorage              RoleStorage
+	emailVerificationStorage EmailVerificationStorage
+	auditLogger              AuditLogger
+	userServiceClient        userService.UserServiceClient
+	credentialVerifier       CredentialVerifier
+	referenceTokenStorage    ReferenceTokenStorage
+	referenceTokenCache      *lru.Cache[string, *Claim]
+	apiKeyStorage            APIKeyStorage
+}
+
+// AuthOptions dependencies of Auth service. TokenDenylist, RoleStorage and AuditLogger are required,
+// the rest are optional: nil CredentialVerifier checks passwords against userService only, nil ReferenceTokenStorage
+// disables opaque access tokens, nil EmailVerificationStorage treats every email as unverified
+// and nil APIKeyStorage means there are no API keys to delete on revocation.
+type AuthOptions struct {
+	TokenDenylist            TokenDenylist
+	RoleStorage              RoleStorage
+	EmailVerificationStorage EmailVerificationStorage
+	AuditLogger              AuditLogger
+	CredentialVerifier       CredentialVerifier
+	ReferenceTokenStorage    ReferenceTokenStorage
+	APIKeyStorage            APIKeyStorage
 }
 
-// NewAuthService creates new Auth service
-func NewAuthService(
-	cfg *config.JwtConfig, sessionStorage SessionStorage, userServiceClient userService.UserServiceClient) *Auth {
-	return &Auth{cfg: cfg, sessionStorage: sessionStorage, userServiceClient: userServiceClient}
+// NewAuthService creates new Auth service, fails if a required dependency is missing
+func NewAuthService(cfg *config.JwtConfig, sessionStorage SessionStorage,
+	userServiceClient userService.UserServiceClient, opts AuthOptions) (*Auth, error) {
+	switch {
+	case opts.TokenDenylist == nil:
+		return nil, fmt.Errorf("%w: TokenDenylist", ErrMissingDependency)
+	case opts.RoleStorage == nil:
+		return nil, fmt.Errorf("%w: RoleStorage", ErrMissingDependency)
+	case opts.AuditLogger == nil:
+		return nil, fmt.Errorf("%w: AuditLogger", ErrMissingDependency)
+	}
+	credentialVerifier := opts.CredentialVerifier
+	if credentialVerifier == nil {
+		credentialVerifier = NewUserServiceVerifier(userServiceClient)
+	}
+	var referenceTokenCache *lru.Cache[string, *Claim]
+	if opts.ReferenceTokenStorage != nil && cfg.ReferenceTokenCacheSize > 0 {
+		// size is positive, so New can't fail
+		referenceTokenCache, _ = lru.New[string, *Claim](cfg.ReferenceTokenCacheSize)
+	}
+	return &Auth{
+		cfg:                      cfg,
+		sessionStorage:           sessionStorage,
+		tokenDenylist:            opts.TokenDenylist,
+		roleStorage:              opts.RoleStorage,
+		emailVerificationStorage: opts.EmailVerificationStorage,
+		auditLogger:              opts.AuditLogger,
+		userServiceClient:        userServiceClient,
+		credentialVerifier:       credentialVerifier,
+		referenceTokenStorage:    opts.ReferenceTokenStorage,
+		referenceTokenCache:      referenceTokenCache,
+		apiKeyStorage:            opts.APIKeyStorage,
+	}, nil
 }
 
-// SignUp sign up user
+// SignUp sign up user, usernames reserved for federated users are refused
 func (a *Auth) SignUp(ctx context.Context, username, pwd, email string) error {
+	if strings.Contains(username, federatedUsernameSeparator) {
+		return ErrInvalidUsername
+	}
 	_, err := a.userServiceClient.Create(ctx, &userService.CreateRequest{
 		Username: username,
 		Email:    email,
@@ -67,71 +200,170 @@ func (a *Auth) SignUp(ctx context.Context, username, pwd, email string) error {
 	return err
 }
 
-// SignIn sign in user
-func (a *Auth) SignIn(ctx context.Context, username, pwd string) (refreshToken, accessToken string, err error) {
-	user, err := a.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
-		Username: username,
-	})
+// SignIn sign in user, requested scope is narrowed to scopes allowed for the client
+func (a *Auth) SignIn(ctx context.Context, username, pwd, scope string) (refreshToken, accessToken string, err error) {
+	client, err := a.client(ctx, GrantTypePassword)
+	if err != nil {
+		return "", "", err
+	}
+	identity, err := a.verifyPassword(ctx, username, pwd)
 	if err != nil {
-		log.Errorf("Auth / SignIn /GetByUsername err %v ", err)
 		return "", "", err
 	}
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(pwd))
-	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-		return "", "", ErrInvalidPassword
-	} else if err != nil {
-		log.Errorf("SignIn / CompareHashAndPassword / error %v", err)
+	if a.cfg.RequireVerifiedEmail && identity.Realm == "" && !a.emailVerified(identity.UserID) {
+		return "", "", ErrEmailNotVerified
+	}
+	session, err := a.newSession(ctx, client, username, identity.UserID, scope)
+	if err != nil {
 		return "", "", err
 	}
+	session.Realm = identity.Realm
+	session.RealmRoles = identity.Roles
+	session.AuthMethods = []string{AuthMethodPassword}
+	session.ACR = assuranceLevel(session.AuthMethods)
 
-	return a.GenerateTokens(ctx, username)
+	return a.generateTokens(session, client)
 }
 
-// ValidateToken validate token
-func (a *Auth) ValidateToken(accessToken string) error {
-	token, err := jwt.ParseWithClaims(
-		accessToken,
-		&Claim{},
-		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				return nil, ErrUnexpectedTokenSigningMethod
-			}
+// verifyPassword checks user password with the credential realm responsible for username
+func (a *Auth) verifyPassword(ctx context.Context, username, pwd string) (*model.Identity, error) {
+	return a.credentialVerifier.VerifyCredentials(ctx, username, pwd)
+}
 
-			return []byte(a.cfg.AccessTokenKey), nil
-		},
-	)
+// ValidateToken validate token issued for the context tenant and return its claims
+func (a *Auth) ValidateToken(ctx context.Context, accessToken string, opts ValidateOptions) (*Claim, error) {
+	claims, err := a.verifyAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.checkValidateOptions(claims, opts); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// checkValidateOptions verifies claims against properties expected by the caller, audience defaults to cfg.Audience
+func (a *Auth) checkValidateOptions(claims *Claim, opts ValidateOptions) error {
+	if opts.Issuer != "" && claims.Issuer != opts.Issuer {
+		return ErrInvalidTokenIssuer
+	}
+	audience := opts.Audience
+	if audience == "" {
+		audience = a.cfg.Audience
+	}
+	if audience != "" && claims.Audience != audience {
+		return ErrInvalidTokenAudience
+	}
+	if opts.MaxAuthAge > 0 && time.Since(time.Unix(claims.AuthTime, 0)) > opts.MaxAuthAge+a.cfg.Leeway {
+		return ErrAuthenticationTooOld
+	}
+	return nil
+}
+
+// RevokeAccessToken adds access token to denylist until its expiration
+func (a *Auth) RevokeAccessToken(ctx context.Context, accessToken string) error {
+	claims, err := a.parseAccessToken(ctx, accessToken)
 	if err != nil {
-		log.Errorf("invalid token: %v", err)
 		return err
 	}
-	_, ok := token.Claims.(*Claim)
-	if !ok {
-		return ErrInvalidTokenClaims
+	a.tokenDenylist.Revoke(claims.Id, claims.ExpiresAt)
+	a.forgetReferenceToken(accessToken)
+
+	return nil
+}
+
+// SignOut ends access token session and optionally revokes the access token
+func (a *Auth) SignOut(ctx context.Context, accessToken string, revokeAccessToken bool) error {
+	claims, err := a.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	session, ok := a.sessionStorage.Load(claims.TenantID, claims.Username)
+	if ok && session.ID == claims.SessionID {
+		a.sessionStorage.Delete(claims.TenantID, claims.Username)
+	}
+	if revokeAccessToken {
+		a.tokenDenylist.Revoke(claims.Id, claims.ExpiresAt)
+		a.forgetReferenceToken(accessToken)
 	}
+	a.auditLogger.Emit(&model.AuditEvent{
+		Type:      model.AuditEventSignOut,
+		TenantID:  claims.TenantID,
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Time:      time.Now().Unix(),
+	})
 
 	return nil
 }
 
-// GenerateTokens generate token
-func (a *Auth) GenerateTokens(_ context.Context, username string) (refreshToken, accessToken string, err error) {
-	refreshToken = uuid.New().String()
-	a.sessionStorage.SaveSession(&model.Session{
-		RefreshToken: refreshToken,
-		Username:     username,
-		ExpiresAt:    time.Now().Add(a.cfg.RefreshTokenExpiration).Unix(),
+// SignOutEverywhere ends all user sessions and revokes all previously issued user access tokens
+func (a *Auth) SignOutEverywhere(ctx context.Context, accessToken string) error {
+	tenant, err := a.tenant(ctx)
+	if err != nil {
+		return err
+	}
+	claims, err := a.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	a.sessionStorage.Delete(claims.TenantID, claims.Username)
+	a.tokenDenylist.Revoke(claims.Id, claims.ExpiresAt)
+	a.tokenDenylist.RevokeSubject(model.TenantKey(claims.TenantID, claims.Username), now.UnixNano(),
+		now.Add(a.maxAccessTokenExpiration(tenant)).Unix())
+	a.deleteAPIKeys(claims.TenantID, claims.Subject)
+	a.auditLogger.Emit(&model.AuditEvent{
+		Type:      model.AuditEventSignOutEverywhere,
+		TenantID:  claims.TenantID,
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Time:      now.Unix(),
 	})
-	accessToken, err = a.generateAccessToken(username, a.cfg.AccessTokenKey, time.Now().Add(a.cfg.AccessTokenExpiration).Unix())
+
+	return nil
+}
+
+// GenerateTokens generate token, requested scope is narrowed to scopes allowed for the client
+func (a *Auth) GenerateTokens(ctx context.Context, username, scope string) (refreshToken, accessToken string, err error) {
+	return a.generateUserTokens(ctx, GrantTypeTrusted, username, scope, nil, nil)
+}
+
+// generateUserTokens starts new session for user the caller already authenticated with given methods,
+// realmRoles are granted by the upstream identity provider in addition to tenant role assignments
+func (a *Auth) generateUserTokens(ctx context.Context, grantType, username, scope string,
+	authMethods, realmRoles []string) (refreshToken, accessToken string, err error) {
+	client, err := a.client(ctx, grantType)
+	if err != nil {
+		return "", "", err
+	}
+	user, err := a.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: username,
+	})
+	if err != nil {
+		log.Errorf("Auth / generateUserTokens /GetByUsername err %v ", err)
+		return "", "", err
+	}
+	session, err := a.newSession(ctx, client, username, user.Uuid, scope)
 	if err != nil {
 		return "", "", err
 	}
+	session.RealmRoles = realmRoles
+	session.AuthMethods = authMethods
+	session.ACR = assuranceLevel(session.AuthMethods)
 
-	return refreshToken, accessToken, nil
+	return a.generateTokens(session, client)
 }
 
-// RefreshTokens refresh tokens
+// RefreshTokens refresh tokens, session can only be refreshed by the client it was started for.
+// Session bound to DPoP key or client certificate can only be refreshed with the same key or certificate.
 func (a *Auth) RefreshTokens(ctx context.Context, refreshToken, username string) (newRefreshToken, accessToken string, err error) {
-	session, loaded := a.sessionStorage.LoadAndDelete(username)
+	client, err := a.client(ctx, GrantTypeRefreshToken)
+	if err != nil {
+		return "", "", err
+	}
+	session, loaded := a.sessionStorage.LoadAndDelete(client.TenantID, username)
 	if !loaded {
 		return "", "", ErrRefreshTokenNotFound
 	}
@@ -140,25 +372,321 @@ func (a *Auth) RefreshTokens(ctx context.Context, refreshToken, username string)
 		return "", "", ErrRefreshTokenMismatch
 	}
 
-	if session.ExpiresAt <= time.Now().Unix() {
+	if session.ClientID != client.ID {
+		return "", "", ErrInvalidClient
+	}
+
+	if session.JKT != "" && session.JKT != dpopKeyFromContext(ctx) {
+		return "", "", ErrDPoPKeyMismatch
+	}
+	if session.X5T != "" && session.X5T != certificateFromContext(ctx) {
+		return "", "", ErrCertificateMismatch
+	}
+
+	now := time.Now()
+	if expiresAt := sessionExpiration(session, client); expiresAt > 0 && expiresAt <= now.Unix() {
+		return "", "", ErrSessionLifetimeExceeded
+	}
+	if client.IdleTimeout > 0 && !now.Before(time.Unix(session.RefreshedAt, 0).Add(client.IdleTimeout)) {
+		return "", "", ErrSessionIdleTimeout
+	}
+	if session.ExpiresAt <= now.Unix() {
 		return "", "", ErrRefreshTokenIsExpired
 	}
+	if session.Realm != "" {
+		if session.RealmRoles, err = a.realmRoles(ctx, session); err != nil {
+			return "", "", err
+		}
+	}
 
-	return a.GenerateTokens(ctx, username)
+	return a.generateTokens(session, client)
 }
 
-func (a *Auth) generateAccessToken(username, key string, expiresAt int64) (string, error) {
-	claims := Claim{
+// realmRoleResolver implemented by credential verifiers able to look up roles of realm users
+type realmRoleResolver interface {
+	RealmRoles(ctx context.Context, realmName, username string) ([]string, error)
+}
+
+// realmRoles resolves roles of realm session again so directory group changes apply on refresh,
+// roles are kept when credential verifier has no realms
+func (a *Auth) realmRoles(ctx context.Context, session *model.Session) ([]string, error) {
+	resolver, ok := a.credentialVerifier.(realmRoleResolver)
+	if !ok {
+		return session.RealmRoles, nil
+	}
+	roles, err := resolver.RealmRoles(ctx, session.Realm, session.Username)
+	if err != nil {
+		log.Errorf("Auth / realmRoles / RealmRoles realm %s err %v ", session.Realm, err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+// newSession creates session for client with requested scope narrowed to client scopes,
+// session is bound to DPoP key or client certificate the request proved possession of
+func (a *Auth) newSession(ctx context.Context, client *config.ClientProfile, username, userID, scope string) (*model.Session, error) {
+	scope, err := clientScope(client, scope)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.checkTenantMember(client.TenantID, userID); err != nil {
+		return nil, err
+	}
+	return &model.Session{
+		ID:       uuid.New().String(),
+		TenantID: client.TenantID,
+		ClientID: client.ID,
+		Username: username,
+		UserID:   userID,
+		Scope:    scope,
+		AuthTime: time.Now().Unix(),
+		JKT:      dpopKeyFromContext(ctx),
+		X5T:      certificateFromContext(ctx),
+	}, nil
+}
+
+// generateTokens rotates session refresh token and issues access token for it using client lifetimes and format.
+// Idle timeout shortens refresh token lifetime, both tokens never outlive absolute session expiration.
+func (a *Auth) generateTokens(session *model.Session, client *config.ClientProfile) (refreshToken, accessToken string, err error) {
+	tenant, err := a.tenantByID(session.TenantID)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	refreshTokenExpiration := client.RefreshTokenExpiration
+	if client.IdleTimeout > 0 && client.IdleTimeout < refreshTokenExpiration {
+		refreshTokenExpiration = client.IdleTimeout
+	}
+	absoluteExpiresAt := sessionExpiration(session, client)
+	refreshToken = uuid.New().String()
+	session.RefreshToken = refreshToken
+	session.RefreshedAt = now.Unix()
+	session.ExpiresAt = capExpiration(now.Add(refreshTokenExpiration).Unix(), absoluteExpiresAt)
+	a.sessionStorage.SaveSession(session)
+	accessToken, err = a.issueAccessToken(session, tenant, client.AccessTokenFormat,
+		capExpiration(now.Add(client.AccessTokenExpiration).Unix(), absoluteExpiresAt))
+	if err != nil {
+		return "", "", err
+	}
+
+	return refreshToken, accessToken, nil
+}
+
+// issueAccessToken issues session access token in given format
+func (a *Auth) issueAccessToken(session *model.Session, tenant *config.Tenant, format string,
+	expiresAt int64) (string, error) {
+	switch format {
+	case config.AccessTokenFormatPasetoLocal, config.AccessTokenFormatPasetoPublic:
+		return signPasetoToken(tenant, a.newAccessTokenClaims(session, expiresAt), format)
+	case config.AccessTokenFormatOpaque:
+		accessToken, err := a.generateAccessToken(session, tenant.AccessTokenKey, expiresAt)
+		if err != nil {
+			return "", err
+		}
+		return a.issueReferenceToken(session.TenantID, accessToken, expiresAt)
+	case config.AccessTokenFormatJWE:
+		accessToken, err := a.generateAccessToken(session, tenant.AccessTokenKey, expiresAt)
+		if err != nil {
+			return "", err
+		}
+		return a.encryptAccessToken(accessToken, a.cfg.Audience)
+	default:
+		return a.generateAccessToken(session, tenant.AccessTokenKey, expiresAt)
+	}
+}
+
+// validateAccessToken validates token presented to this service, it must be issued for cfg.Audience.
+// Tokens exchanged for other audiences are only good for the services they were issued for.
+func (a *Auth) validateAccessToken(ctx context.Context, accessToken string) (*Claim, error) {
+	claims, err := a.verifyAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Audience != a.cfg.Audience {
+		return nil, ErrInvalidTokenAudience
+	}
+
+	return claims, nil
+}
+
+// verifyAccessToken parses token of any audience and checks it is neither revoked nor presented over connection
+// with other client certificate or without proof of the DPoP key it is bound to
+func (a *Auth) verifyAccessToken(ctx context.Context, accessToken string) (*Claim, error) {
+	claims, err := a.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if a.tokenDenylist.IsRevoked(claims.Id) ||
+		a.tokenDenylist.IsSubjectRevoked(model.TenantKey(claims.TenantID, claims.Username), claims.issuedAt()) {
+		return nil, ErrAccessTokenRevoked
+	}
+	if err = verifyCertificateBinding(ctx, claims); err != nil {
+		return nil, err
+	}
+	if err = verifyDPoPBinding(ctx, claims, accessToken); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// parseAccessToken verifies token with the context tenant key, token must be issued for that tenant.
+// Token format is detected by its prefix, opaque reference tokens are resolved to the access token they stand for
+// and encrypted tokens are decrypted to the nested JWT.
+func (a *Auth) parseAccessToken(ctx context.Context, accessToken string) (*Claim, error) {
+	tenant, err := a.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var claims *Claim
+	switch {
+	case isPasetoToken(accessToken):
+		claims, err = parsePasetoToken(tenant, accessToken)
+	case isReferenceToken(accessToken):
+		claims, err = a.resolveReferenceToken(tenant, accessToken)
+	case isEncryptedToken(accessToken):
+		claims, err = a.parseEncryptedToken(tenant, accessToken)
+	default:
+		claims, err = a.parseJWT(tenant, accessToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims.TenantID != tenant.ID {
+		return nil, ErrInvalidTokenTenant
+	}
+	if err = a.verifyRegisteredClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// parseJWT verifies JWT signature with the tenant key
+func (a *Auth) parseJWT(tenant *config.Tenant, accessToken string) (*Claim, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(
+		accessToken,
+		&Claim{},
+		func(token *jwt.Token) (interface{}, error) {
+			_, ok := token.Method.(*jwt.SigningMethodHMAC)
+			if !ok {
+				return nil, ErrUnexpectedTokenSigningMethod
+			}
+
+			return []byte(tenant.AccessTokenKey), nil
+		},
+	)
+	if err != nil {
+		log.Errorf("invalid token: %v", err)
+		return nil, err
+	}
+	claims, ok := token.Claims.(*Claim)
+	if !ok || claims.Id == "" {
+		return nil, ErrInvalidTokenClaims
+	}
+
+	return claims, nil
+}
+
+// verifyRegisteredClaims verifies time based claims with configured leeway and token issuer
+func (a *Auth) verifyRegisteredClaims(claims *Claim) error {
+	now := time.Now()
+	leeway := int64(a.cfg.Leeway.Seconds())
+	if claims.ExpiresAt <= now.Unix()-leeway {
+		return ErrAccessTokenExpired
+	}
+	if claims.NotBefore > now.Unix()+leeway || claims.IssuedAt > now.Unix()+leeway {
+		return ErrAccessTokenNotValidYet
+	}
+	if claims.Issuer != a.cfg.Issuer {
+		return ErrInvalidTokenIssuer
+	}
+
+	return nil
+}
+
+func (a *Auth) generateAccessToken(session *model.Session, key string, expiresAt int64) (string, error) {
+	return a.signAccessToken(a.newAccessTokenClaims(session, expiresAt), key)
+}
+
+// newAccessTokenClaims returns claims of session access token, roles are resolved at issuance
+func (a *Auth) newAccessTokenClaims(session *model.Session, expiresAt int64) *Claim {
+	now := time.Now()
+	claims := &Claim{
 		StandardClaims: jwt.StandardClaims{
-			IssuedAt:  time.Now().Unix(),
+			Id:        uuid.New().String(),
+			Issuer:    a.cfg.Issuer,
+			Subject:   session.UserID,
+			Audience:  a.cfg.Audience,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
 			ExpiresAt: expiresAt,
 		},
-		Username: username,
+		IssuedAtNano:  now.UnixNano(),
+		Username:      session.Username,
+		TenantID:      session.TenantID,
+		ClientID:      session.ClientID,
+		AuthTime:      session.AuthTime,
+		AMR:           session.AuthMethods,
+		ACR:           session.ACR,
+		SessionID:     session.ID,
+		Scope:         session.Scope,
+		EmailVerified: a.emailVerified(session.UserID),
 	}
+	claims.Roles, claims.Permissions = resolveRoles(a.roleStorage, session.TenantID, session.UserID, session.RealmRoles...)
+	if session.JKT != "" || session.X5T != "" {
+		claims.Cnf = &Confirmation{JKT: session.JKT, X5T: session.X5T}
+	}
+
+	return claims
+}
+
+// revokeUserSessions ends user sessions in every tenant, revokes user access tokens issued until now
+// and deletes user API keys
+func (a *Auth) revokeUserSessions(userID, username string) {
+	now := time.Now()
+	tenantIDs := []string{a.cfg.DefaultTenant}
+	for tenantID := range a.cfg.Tenants {
+		if tenantID != a.cfg.DefaultTenant {
+			tenantIDs = append(tenantIDs, tenantID)
+		}
+	}
+	for _, tenantID := range tenantIDs {
+		tenant, err := a.tenantByID(tenantID)
+		if err != nil {
+			continue
+		}
+		a.sessionStorage.Delete(tenantID, username)
+		a.tokenDenylist.RevokeSubject(model.TenantKey(tenantID, username), now.UnixNano(),
+			now.Add(a.maxAccessTokenExpiration(tenant)).Unix())
+		a.deleteAPIKeys(tenantID, userID)
+	}
+}
+
+// deleteAPIKeys deletes tenant user API keys when all user tokens are revoked. Subject revocation is forgotten
+// once access tokens it covers expire, keys live longer, so they are deleted rather than checked against it.
+func (a *Auth) deleteAPIKeys(tenantID, userID string) {
+	if a.apiKeyStorage == nil {
+		return
+	}
+	for _, key := range a.apiKeyStorage.List(tenantID, userID) {
+		a.apiKeyStorage.Delete(tenantID, userID, key.ID)
+	}
+}
+
+// capExpiration returns expiresAt limited by optional maximum, zero maximum means no limit
+func capExpiration(expiresAt, maxExpiresAt int64) int64 {
+	if maxExpiresAt != 0 && maxExpiresAt < expiresAt {
+		return maxExpiresAt
+	}
+	return expiresAt
+}
 
+func (a *Auth) signAccessToken(claims *Claim, key string) (string, error) {
 	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
 	if err != nil {
-		log.Errorf("auth/ generateAccessToken/ error in SignedString for username %s: %v", username, err)
+		log.Errorf("auth/ signAccessToken/ error in SignedString for username %s: %v", claims.Username, err)
 		return "", err
 	}
 
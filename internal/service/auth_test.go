@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"crypto/x509"
 	"testing"
 	"time"
 
 	"github.com/Entetry/authService/internal/config"
 	"github.com/Entetry/authService/internal/model"
 	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,17 +23,189 @@ const (
 	mockAccessTokenKey = "mock-access-token-key"
 	mockRefreshToken   = "mock-refresh-token"
 	mockUsername       = "test_user"
+	mockUserID         = "6c4b1ad2-8d5f-4a41-9a3e-1b2f0d8f6a11"
+	mockIssuer         = "mock-issuer"
+	mockTokenAudience  = "mock-audience"
+	mockEmail          = "test_user@example.com"
 )
 
+// newMockUserServiceClient creates userService client mock expecting mockUsername to be loaded
+func newMockUserServiceClient(t *testing.T) *mocks.UserServiceClient {
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername}, nil)
+	return mockUserServiceClient
+}
+
+// newMockAttemptCounter creates attempt counter mock counting attempts in memory, windows never expire
+func newMockAttemptCounter(t *testing.T) *mocks.AttemptCounter {
+	attempts := make(map[string]int)
+	mockAttemptCounter := mocks.NewAttemptCounter(t)
+	mockAttemptCounter.On("Count", mock.AnythingOfType("string")).
+		Return(func(key string) int { return attempts[key] }).Maybe()
+	mockAttemptCounter.On("Add", mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).
+		Return(func(key string, _ time.Duration) int {
+			attempts[key]++
+			return attempts[key]
+		}).Maybe()
+	mockAttemptCounter.On("Reset", mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { delete(attempts, args.String(0)) }).Return().Maybe()
+	return mockAttemptCounter
+}
+
+// newTestAuthService creates Auth service, required dependencies missing from opts accept every call:
+// nothing is revoked, users have no roles and audit events are dropped
+func newTestAuthService(t *testing.T, cfg *config.JwtConfig, sessionStorage SessionStorage,
+	userServiceClient userService.UserServiceClient, opts AuthOptions) *Auth {
+	if opts.TokenDenylist == nil {
+		mockTokenDenylist := mocks.NewTokenDenylist(t)
+		mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false).Maybe()
+		mockTokenDenylist.On("IsSubjectRevoked", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+			Return(false).Maybe()
+		opts.TokenDenylist = mockTokenDenylist
+	}
+	if opts.RoleStorage == nil {
+		mockRoleStorage := mocks.NewRoleStorage(t)
+		mockRoleStorage.On("LoadUserRoles", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+			Return(nil).Maybe()
+		mockRoleStorage.On("LoadRole", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+			Return(nil, false).Maybe()
+		opts.RoleStorage = mockRoleStorage
+	}
+	if opts.AuditLogger == nil {
+		mockAuditLogger := mocks.NewAuditLogger(t)
+		mockAuditLogger.On("Emit", mock.AnythingOfType("*model.AuditEvent")).Return().Maybe()
+		opts.AuditLogger = mockAuditLogger
+	}
+	auth, err := NewAuthService(cfg, sessionStorage, userServiceClient, opts)
+	assert.NoError(t, err, "Expected no error when creating Auth service")
+	return auth
+}
+
+// authFixture Auth service of tests backed by in-memory users and sessions
+type authFixture struct {
+	auth              *Auth
+	userServiceClient *mocks.UserServiceClient
+	users             map[string]*userService.GetByUsernameResponse
+	sessions          map[string]*model.Session
+}
+
+// newAuthFixture creates Auth service with mockUsername as the only user. Dependencies missing from opts
+// accept every call: nothing is revoked, users have no roles and audit events are dropped.
+func newAuthFixture(t *testing.T, cfg *config.JwtConfig, opts AuthOptions) *authFixture {
+	f := &authFixture{
+		users: map[string]*userService.GetByUsernameResponse{
+			mockUsername: {Uuid: mockUserID, Name: mockUsername, Email: mockEmail},
+		},
+		sessions: make(map[string]*model.Session),
+	}
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, mock.AnythingOfType("*userService.GetByUsernameRequest")).
+		Return(func(_ context.Context, in *userService.GetByUsernameRequest, _ ...grpc.CallOption) *userService.GetByUsernameResponse {
+			return f.users[in.Username]
+		}, func(_ context.Context, in *userService.GetByUsernameRequest, _ ...grpc.CallOption) error {
+			if _, ok := f.users[in.Username]; !ok {
+				return status.Error(codes.NotFound, "user not found")
+			}
+			return nil
+		}).Maybe()
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Run(func(args mock.Arguments) {
+		session := args.Get(0).(*model.Session)
+		f.sessions[model.TenantKey(session.TenantID, session.Username)] = session
+	}).Return().Maybe()
+	mockSessionStorage.On("Load", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(
+		func(tenantID, username string) *model.Session { return f.sessions[model.TenantKey(tenantID, username)] },
+		func(tenantID, username string) bool {
+			_, ok := f.sessions[model.TenantKey(tenantID, username)]
+			return ok
+		}).Maybe()
+	mockSessionStorage.On("LoadAndDelete", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(
+		func(tenantID, username string) *model.Session { return f.sessions[model.TenantKey(tenantID, username)] },
+		func(tenantID, username string) bool {
+			_, ok := f.sessions[model.TenantKey(tenantID, username)]
+			delete(f.sessions, model.TenantKey(tenantID, username))
+			return ok
+		}).Maybe()
+	mockSessionStorage.On("Delete", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { delete(f.sessions, model.TenantKey(args.String(0), args.String(1))) }).Return().Maybe()
+	f.auth = newTestAuthService(t, cfg, mockSessionStorage, mockUserServiceClient, opts)
+	f.userServiceClient = mockUserServiceClient
+
+	return f
+}
+
+// session returns stored session of mockUsername in tenant, nil if there is none
+func (f *authFixture) session(tenantID string) *model.Session {
+	return f.sessions[model.TenantKey(tenantID, mockUsername)]
+}
+
+// newTestAccessToken signs access token of mockUsername with claims, unset registered claims are filled in
+func newTestAccessToken(t *testing.T, auth *Auth, claims *Claim) string {
+	if claims.Username == "" {
+		claims.Username = mockUsername
+		claims.Subject = mockUserID
+	}
+	if claims.Issuer == "" {
+		claims.Issuer = auth.cfg.Issuer
+	}
+	if claims.Id == "" {
+		claims.Id = mockRefreshToken
+	}
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = time.Now().Unix()
+	}
+	if claims.ExpiresAt == 0 {
+		claims.ExpiresAt = time.Now().Add(time.Minute).Unix()
+	}
+	accessToken, err := auth.signAccessToken(claims, mockAccessTokenKey)
+	assert.NoError(t, err, "Expected no error when signing access token")
+	return accessToken
+}
+
+func TestNewAuthService_RequiredDependencies(t *testing.T) {
+	opts := AuthOptions{
+		TokenDenylist: mocks.NewTokenDenylist(t),
+		RoleStorage:   mocks.NewRoleStorage(t),
+		AuditLogger:   mocks.NewAuditLogger(t),
+	}
+	_, err := NewAuthService(&config.JwtConfig{}, nil, nil, opts)
+	assert.NoError(t, err, "Expected no error with required dependencies")
+
+	for name, without := range map[string]func(opts *AuthOptions){
+		"TokenDenylist": func(opts *AuthOptions) { opts.TokenDenylist = nil },
+		"RoleStorage":   func(opts *AuthOptions) { opts.RoleStorage = nil },
+		"AuditLogger":   func(opts *AuthOptions) { opts.AuditLogger = nil },
+	} {
+		missing := opts
+		without(&missing)
+		_, err = NewAuthService(&config.JwtConfig{}, nil, nil, missing)
+		assert.ErrorIs(t, err, ErrMissingDependency, "Expected ErrMissingDependency without %s", name)
+	}
+}
+
+func TestAuth_SignUp_FederatedUsername(t *testing.T) {
+	f := newAuthFixture(t, &config.JwtConfig{AccessTokenKey: mockAccessTokenKey}, AuthOptions{})
+
+	err := f.auth.SignUp(context.Background(), mockProviderID+"|"+mockProviderSubject, "password", mockEmail)
+
+	assert.ErrorIs(t, err, ErrInvalidUsername, "Expected ErrInvalidUsername for username of federated user")
+}
+
 func TestAuth_GenerateTokens(t *testing.T) {
 	cfg := config.JwtConfig{
 		AccessTokenKey:         mockAccessTokenKey,
 		AccessTokenExpiration:  30 * time.Minute,
 		RefreshTokenExpiration: 24 * time.Hour}
 	mockSessionStorage := mocks.NewSessionStorage(t)
-	auth := NewAuthService(&cfg, mockSessionStorage, nil)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		RoleStorage: mockRoleStorage,
+	})
 	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
-	refreshToken, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername)
+	refreshToken, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
 
 	assert.NoError(t, err, "Expected no error when generating tokens")
 	assert.NotEmpty(t, refreshToken, "Expected a non-empty refresh token")
@@ -46,9 +224,11 @@ func TestAuth_RefreshTokens(t *testing.T) {
 		AccessTokenExpiration:  30 * time.Minute,
 		RefreshTokenExpiration: 24 * time.Hour}
 	mockSessionStorage := mocks.NewSessionStorage(t)
-	auth := NewAuthService(&cfg, mockSessionStorage, nil)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", "").Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{RoleStorage: mockRoleStorage})
 
-	mockSessionStorage.On("LoadAndDelete", mockUsername).Return(&session, true)
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(&session, true)
 	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session"))
 
 	newRefreshToken, accessToken, err := auth.RefreshTokens(context.Background(), mockRefreshToken, mockUsername)
@@ -65,13 +245,13 @@ func TestAuth_RefreshTokens_ExpiredRefreshToken(t *testing.T) {
 		AccessTokenExpiration:  30 * time.Minute,
 		RefreshTokenExpiration: 24 * time.Hour}
 	mockSessionStorage := mocks.NewSessionStorage(t)
-	auth := NewAuthService(&cfg, mockSessionStorage, nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{})
 	expiredSession := model.Session{
 		RefreshToken: mockRefreshToken,
 		Username:     mockUsername,
 		ExpiresAt:    time.Now().Add(-1 * time.Hour).Unix(),
 	}
-	mockSessionStorage.On("LoadAndDelete", mockUsername).Return(&expiredSession, true)
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(&expiredSession, true)
 
 	newRefreshToken, accessToken, err := auth.RefreshTokens(context.Background(), mockRefreshToken, mockUsername)
 
@@ -80,3 +260,461 @@ func TestAuth_RefreshTokens_ExpiredRefreshToken(t *testing.T) {
 	assert.Empty(t, accessToken, "Expected an empty access token")
 	mockSessionStorage.AssertExpectations(t)
 }
+
+func TestAuth_ValidateToken_RevokedAccessToken(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	_, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	var revokedJti string
+	mockTokenDenylist.On("Revoke", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).
+		Run(func(args mock.Arguments) { revokedJti = args.String(0) }).Return()
+	err = auth.RevokeAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when revoking access token")
+	assert.NotEmpty(t, revokedJti, "Expected access token jti to be revoked")
+
+	mockTokenDenylist.On("IsRevoked", revokedJti).Return(true)
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+
+	assert.EqualError(t, err, ErrAccessTokenRevoked.Error(), "Expected ErrAccessTokenRevoked for a revoked access token")
+}
+
+func TestAuth_ValidateToken(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	_, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+
+	assert.NoError(t, err, "Expected no error when validating access token")
+}
+
+func TestAuth_SignOut(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockAuditLogger := mocks.NewAuditLogger(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+		AuditLogger:   mockAuditLogger,
+	})
+	var session *model.Session
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	_, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	mockSessionStorage.On("Load", "", mockUsername).Return(session, true)
+	mockSessionStorage.On("Delete", "", mockUsername).Return()
+	mockTokenDenylist.On("Revoke", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return()
+	mockAuditLogger.On("Emit", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEventSignOut && event.SessionID == session.ID
+	})).Return()
+
+	err = auth.SignOut(context.Background(), accessToken, true)
+
+	assert.NoError(t, err, "Expected no error when signing out")
+}
+
+func TestAuth_SignOutEverywhere(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockAuditLogger := mocks.NewAuditLogger(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+		AuditLogger:   mockAuditLogger,
+	})
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	_, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	mockSessionStorage.On("Delete", "", mockUsername).Return()
+	mockTokenDenylist.On("Revoke", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return()
+	mockTokenDenylist.On("RevokeSubject", model.TenantKey("", mockUsername), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return()
+	mockAuditLogger.On("Emit", mock.AnythingOfType("*model.AuditEvent")).Return()
+
+	err = auth.SignOutEverywhere(context.Background(), accessToken)
+
+	assert.NoError(t, err, "Expected no error when signing out everywhere")
+}
+
+func TestAuth_GenerateTokens_AfterSubjectRevocation(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	revokedAt := time.Now().UnixNano()
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).
+		Return(func(_ string, issuedAt int64) bool { return issuedAt <= revokedAt })
+	auth := newAuthFixture(t, &cfg, AuthOptions{TokenDenylist: mockTokenDenylist}).auth
+
+	start := time.Now()
+	_, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "Expected issuance not to wait for the next second")
+	claims, err := auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected token issued in the second of revocation to outlive it")
+	assert.Greater(t, claims.IssuedAtNano, revokedAt, "Expected token issued after revocation")
+
+	t.Log("token issued earlier in the second of revocation is revoked")
+	claims.IssuedAtNano = revokedAt - 1
+	assert.True(t, mockTokenDenylist.IsSubjectRevoked(model.TenantKey("", mockUsername), claims.issuedAt()),
+		"Expected token issued before revocation to be revoked")
+	claims.IssuedAtNano = 0
+	claims.IssuedAt = time.Unix(0, revokedAt).Unix()
+	assert.True(t, mockTokenDenylist.IsSubjectRevoked(model.TenantKey("", mockUsername), claims.issuedAt()),
+		"Expected second precision token of the revocation second to be revoked")
+}
+
+func TestAuth_ValidateToken_RegisteredClaims(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Issuer:                 mockIssuer,
+		Audience:               mockTokenAudience}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	_, accessToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	claims, err := auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, mockIssuer, claims.Issuer, "Access token issuer mismatch")
+	assert.Equal(t, mockUserID, claims.Subject, "Access token subject mismatch")
+	assert.Equal(t, mockTokenAudience, claims.Audience, "Access token audience mismatch")
+	assert.NotZero(t, claims.NotBefore, "Expected access token not before claim")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{Issuer: mockIssuer, Audience: mockTokenAudience})
+	assert.NoError(t, err, "Expected no error when validating access token with expected issuer and audience")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{Issuer: "other-issuer"})
+	assert.ErrorIs(t, err, ErrInvalidTokenIssuer, "Expected ErrInvalidTokenIssuer for unexpected issuer")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{Audience: "other-audience"})
+	assert.ErrorIs(t, err, ErrInvalidTokenAudience, "Expected ErrInvalidTokenAudience for unexpected audience")
+
+	t.Log("audience defaults to the service audience")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating access token of service audience")
+	otherAudience := newTestAccessToken(t, auth, &Claim{StandardClaims: jwt.StandardClaims{Audience: "other-audience"}})
+	_, err = auth.ValidateToken(context.Background(), otherAudience, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidTokenAudience, "Expected ErrInvalidTokenAudience for token of other audience")
+}
+
+func TestAuth_ValidateToken_Leeway(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey: mockAccessTokenKey,
+		Leeway:         time.Minute}
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, nil, nil, AuthOptions{RoleStorage: mockRoleStorage})
+	session := &model.Session{ID: mockRefreshToken, Username: mockUsername, UserID: mockUserID}
+
+	recentlyExpired, err := auth.generateAccessToken(session, mockAccessTokenKey, time.Now().Add(-30*time.Second).Unix())
+	assert.NoError(t, err, "Expected no error when generating access token")
+	expired, err := auth.generateAccessToken(session, mockAccessTokenKey, time.Now().Add(-2*time.Minute).Unix())
+	assert.NoError(t, err, "Expected no error when generating access token")
+
+	_, err = auth.parseAccessToken(context.Background(), recentlyExpired)
+	assert.NoError(t, err, "Expected token expired within leeway to be accepted")
+	_, err = auth.parseAccessToken(context.Background(), expired)
+	assert.ErrorIs(t, err, ErrAccessTokenExpired, "Expected ErrAccessTokenExpired for token expired beyond leeway")
+}
+
+func TestAuth_ValidateToken_Tenant(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		DefaultTenant:          "default",
+		Tenants: map[string]*config.Tenant{
+			"acme":   {ID: "acme", AccessTokenKey: "acme-access-token-key", AccessTokenExpiration: 5 * time.Minute},
+			"globex": {ID: "globex"},
+		}}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "acme", mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadUserRoles", "globex", mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadRole", mock.AnythingOfType("string"), "viewer").Return(nil, false)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	var session *model.Session
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(false)
+	acme := WithTenant(context.Background(), "acme")
+
+	_, accessToken, err := auth.GenerateTokens(acme, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	assert.Equal(t, "acme", session.TenantID, "Session tenant mismatch")
+	claims, err := auth.ValidateToken(acme, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating access token in its tenant")
+	assert.Equal(t, "acme", claims.TenantID, "Access token tenant mismatch")
+	assert.LessOrEqual(t, claims.ExpiresAt, time.Now().Add(5*time.Minute).Unix(), "Tenant token lifetime is not applied")
+
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.Error(t, err, "Expected error when validating access token signed with other tenant key")
+	_, err = auth.ValidateToken(WithTenant(context.Background(), "unknown"), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrUnknownTenant, "Expected ErrUnknownTenant for unknown tenant")
+
+	_, accessToken, err = auth.GenerateTokens(WithTenant(context.Background(), "globex"), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidTokenTenant, "Expected ErrInvalidTokenTenant for token of other tenant sharing signing key")
+}
+
+func TestAuth_GenerateTokens_NotTenantMember(t *testing.T) {
+	cfg := &config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		DefaultTenant:          "default",
+		Tenants:                map[string]*config.Tenant{"acme": {ID: "acme"}},
+	}
+	f := newAuthFixture(t, cfg, AuthOptions{})
+
+	t.Log("Step 1: user without roles in tenant can't start session there")
+	_, _, err := f.auth.GenerateTokens(WithTenant(context.Background(), "acme"), mockUsername, "")
+	assert.ErrorIs(t, err, ErrNotTenantMember, "Expected ErrNotTenantMember for user without tenant roles")
+	assert.Nil(t, f.session("acme"), "Expected no session saved for non member")
+
+	t.Log("Step 2: every user belongs to default tenant")
+	_, _, err = f.auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens in default tenant")
+}
+
+func TestAuth_GenerateTokens_ClientProfile(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Clients: map[string]*config.ClientProfile{
+			"admin-console": {
+				ID:                     "admin-console",
+				AccessTokenExpiration:  5 * time.Minute,
+				RefreshTokenExpiration: 24 * time.Hour,
+				IdleTimeout:            time.Hour,
+				AbsoluteTimeout:        8 * time.Hour,
+				GrantTypes:             []string{GrantTypeTrusted},
+				Scopes:                 []string{"orders:read", "orders:write"},
+			},
+		}}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	var session *model.Session
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	ctx := WithClient(context.Background(), "admin-console")
+
+	_, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "orders:read")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	now := time.Now()
+	assert.Equal(t, "admin-console", session.ClientID, "Session client mismatch")
+	assert.LessOrEqual(t, session.ExpiresAt, now.Add(time.Hour).Unix(), "Client idle timeout is not applied")
+	assert.InDelta(t, now.Unix(), session.AuthTime, 1, "Session auth time mismatch")
+	claims, err := auth.parseAccessToken(ctx, accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, "admin-console", claims.ClientID, "Access token client mismatch")
+	assert.Equal(t, "orders:read", claims.Scope, "Access token scope mismatch")
+	assert.LessOrEqual(t, claims.ExpiresAt, now.Add(5*time.Minute).Unix(), "Client access token lifetime is not applied")
+
+	_, _, err = auth.GenerateTokens(ctx, mockUsername, "users:admin")
+	assert.ErrorIs(t, err, ErrInvalidScope, "Expected ErrInvalidScope for scope not allowed for client")
+	_, _, err = auth.SignIn(ctx, mockUsername, "password", "")
+	assert.ErrorIs(t, err, ErrUnauthorizedGrantType, "Expected ErrUnauthorizedGrantType for grant not allowed for client")
+	_, _, err = auth.GenerateTokens(WithClient(context.Background(), "unknown"), mockUsername, "")
+	assert.ErrorIs(t, err, ErrInvalidClient, "Expected ErrInvalidClient for unknown client")
+}
+
+func TestAuth_AuthenticateClient(t *testing.T) {
+	certificate := &x509.Certificate{Raw: []byte("client certificate")}
+	cfg := &config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		DefaultClient:          "default",
+		Clients: map[string]*config.ClientProfile{
+			"backend": {ID: "backend", SecretHash: hashToken("backend-secret")},
+			"device":  {ID: "device", CertificateThumbprints: []string{CertificateThumbprint(certificate)}},
+			"mobile":  {ID: "mobile"},
+		}}
+	f := newAuthFixture(t, cfg, AuthOptions{})
+	ctx := context.Background()
+	certificateCtx := WithClientCertificate(ctx, certificate)
+
+	t.Log("Step 1: default client needs no credentials")
+	assert.NoError(t, f.auth.AuthenticateClient(ctx, "", ""), "Expected no error for default client")
+	assert.NoError(t, f.auth.AuthenticateClient(ctx, "default", ""), "Expected no error for default client id")
+
+	t.Log("Step 2: client authenticates with its secret")
+	assert.NoError(t, f.auth.AuthenticateClient(ctx, "backend", "backend-secret"), "Expected no error for valid secret")
+	assert.ErrorIs(t, f.auth.AuthenticateClient(ctx, "backend", "wrong"), ErrInvalidClient,
+		"Expected ErrInvalidClient for wrong secret")
+	assert.ErrorIs(t, f.auth.AuthenticateClient(ctx, "backend", ""), ErrInvalidClient,
+		"Expected ErrInvalidClient without credentials")
+
+	t.Log("Step 3: client authenticates with its mutual TLS certificate")
+	assert.NoError(t, f.auth.AuthenticateClient(certificateCtx, "device", ""), "Expected no error for listed certificate")
+	otherCtx := WithClientCertificate(ctx, &x509.Certificate{Raw: []byte("other certificate")})
+	assert.ErrorIs(t, f.auth.AuthenticateClient(otherCtx, "device", ""), ErrInvalidClient,
+		"Expected ErrInvalidClient for other certificate")
+	assert.ErrorIs(t, f.auth.AuthenticateClient(certificateCtx, "backend", ""), ErrInvalidClient,
+		"Expected ErrInvalidClient for certificate not listed for client")
+
+	t.Log("Step 4: clients without credentials and unknown clients are rejected")
+	assert.ErrorIs(t, f.auth.AuthenticateClient(certificateCtx, "mobile", "any"), ErrInvalidClient,
+		"Expected ErrInvalidClient for client without credentials")
+	assert.ErrorIs(t, f.auth.AuthenticateClient(ctx, "unknown", "any"), ErrInvalidClient,
+		"Expected ErrInvalidClient for unknown client")
+}
+
+func TestAuth_RefreshTokens_ClientMismatch(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Clients:                map[string]*config.ClientProfile{"mobile": {ID: "mobile"}}}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{})
+	session := model.Session{
+		ClientID:     "mobile",
+		RefreshToken: mockRefreshToken,
+		Username:     mockUsername,
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(&session, true)
+
+	_, _, err := auth.RefreshTokens(context.Background(), mockRefreshToken, mockUsername)
+
+	assert.ErrorIs(t, err, ErrInvalidClient, "Expected ErrInvalidClient when refreshing session of other client")
+}
+
+func TestAuth_RefreshTokens_SessionTimeouts(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		SessionIdleTimeout:     time.Hour,
+		SessionAbsoluteTimeout: 8 * time.Hour}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{})
+	now := time.Now()
+	idleSession := model.Session{
+		RefreshToken: mockRefreshToken,
+		Username:     mockUsername,
+		AuthTime:     now.Add(-2 * time.Hour).Unix(),
+		RefreshedAt:  now.Add(-2 * time.Hour).Unix(),
+		ExpiresAt:    now.Add(time.Hour).Unix(),
+	}
+	expiredSession := model.Session{
+		RefreshToken: mockRefreshToken,
+		Username:     "expired_user",
+		AuthTime:     now.Add(-9 * time.Hour).Unix(),
+		RefreshedAt:  now.Add(-time.Minute).Unix(),
+		ExpiresAt:    now.Add(time.Hour).Unix(),
+	}
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(&idleSession, true)
+	mockSessionStorage.On("LoadAndDelete", "", "expired_user").Return(&expiredSession, true)
+
+	_, _, err := auth.RefreshTokens(context.Background(), mockRefreshToken, mockUsername)
+	assert.ErrorIs(t, err, ErrSessionIdleTimeout, "Expected ErrSessionIdleTimeout for session not refreshed within idle timeout")
+	_, _, err = auth.RefreshTokens(context.Background(), mockRefreshToken, "expired_user")
+	assert.ErrorIs(t, err, ErrSessionLifetimeExceeded, "Expected ErrSessionLifetimeExceeded for session older than absolute timeout")
+}
+
+func TestAuth_ValidateToken_MaxAuthAge(t *testing.T) {
+	cfg := config.JwtConfig{AccessTokenKey: mockAccessTokenKey}
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, nil, nil, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	session := &model.Session{
+		ID:       mockRefreshToken,
+		Username: mockUsername,
+		UserID:   mockUserID,
+		AuthTime: time.Now().Add(-time.Hour).Unix(),
+	}
+	accessToken, err := auth.generateAccessToken(session, mockAccessTokenKey, time.Now().Add(time.Minute).Unix())
+	assert.NoError(t, err, "Expected no error when generating access token")
+
+	claims, err := auth.ValidateToken(context.Background(), accessToken, ValidateOptions{MaxAuthAge: 2 * time.Hour})
+	assert.NoError(t, err, "Expected no error when authentication is recent enough")
+	assert.Equal(t, session.AuthTime, claims.AuthTime, "Access token auth_time mismatch")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{MaxAuthAge: 10 * time.Minute})
+	assert.ErrorIs(t, err, ErrAuthenticationTooOld, "Expected ErrAuthenticationTooOld for old authentication")
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// DecisionCache used to cache authorization decisions per token
+type DecisionCache interface {
+	Load(jti, key string) (*model.Decision, bool)
+	Save(jti string, expiresAt int64, key string, decision *model.Decision)
+}
+
+// PolicyEvaluator used to evaluate attribute based access policies, Revision changes whenever policies change
+type PolicyEvaluator interface {
+	Evaluate(input *model.PolicyInput) (decision *model.Decision, matched bool)
+	Revision() uint64
+}
+
+// Authorizer authorization decisions service struct
+type Authorizer struct {
+	cfg             *config.AuthorizationConfig
+	decisionCache   DecisionCache
+	policyEvaluator PolicyEvaluator
+	auth            *Auth
+}
+
+// NewAuthorizerService creates new Authorizer service
+func NewAuthorizerService(cfg *config.AuthorizationConfig, decisionCache DecisionCache, policyEvaluator PolicyEvaluator,
+	auth *Auth) *Authorizer {
+	return &Authorizer{cfg: cfg, decisionCache: decisionCache, policyEvaluator: policyEvaluator, auth: auth}
+}
+
+// Authorize decides each check against permissions of the access token and policies, decisions are returned in checks order.
+// Only decisions made without policies are cached, policy conditions may depend on attributes and time.
+// Cached decisions are keyed by policy revision, so reloaded policies apply at once.
+func (a *Authorizer) Authorize(ctx context.Context, accessToken string, checks []*model.AccessCheck) []*model.Decision {
+	decisions := make([]*model.Decision, len(checks))
+	claims, err := a.auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	if err != nil {
+		for i := range decisions {
+			decisions[i] = &model.Decision{Reason: fmt.Sprintf("invalid access token: %v", err)}
+		}
+		return decisions
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.cfg.DecisionCacheTTL).Unix()
+	if claims.ExpiresAt < expiresAt {
+		expiresAt = claims.ExpiresAt
+	}
+	revision := a.policyEvaluator.Revision()
+	var token map[string]interface{}
+	for i, check := range checks {
+		key := fmt.Sprintf("%d\x00%s\x00%s", revision, check.Action, check.Resource)
+		if decision, ok := a.decisionCache.Load(claims.Id, key); ok {
+			decisions[i] = decision
+			continue
+		}
+		if token == nil {
+			token = claimsMap(claims)
+		}
+		policyDecision, matched := a.policyEvaluator.Evaluate(&model.PolicyInput{
+			Token:              token,
+			Action:             check.Action,
+			Resource:           check.Resource,
+			RequestAttributes:  check.RequestAttributes,
+			ResourceAttributes: check.ResourceAttributes,
+			Now:                now,
+		})
+		decisions[i] = decide(claims, check, policyDecision)
+		if !matched {
+			a.decisionCache.Save(claims.Id, expiresAt, key, decisions[i])
+		}
+	}
+
+	return decisions
+}
+
+// decide combines policy decision with token permissions, deny policy overrides permissions, allow policy extends them
+func decide(claims *Claim, check *model.AccessCheck, policyDecision *model.Decision) *model.Decision {
+	if policyDecision != nil && !policyDecision.Allowed {
+		return policyDecision
+	}
+	for _, permission := range claims.Permissions {
+		if permissionMatches(permission, check) {
+			return &model.Decision{Allowed: true, Reason: fmt.Sprintf("granted by permission %q", permission)}
+		}
+	}
+	if policyDecision != nil {
+		return policyDecision
+	}
+	return &model.Decision{Reason: fmt.Sprintf("no permission grants %q on %q", check.Action, check.Resource)}
+}
+
+// claimsMap converts claims to their JSON representation for policy conditions
+func claimsMap(claims *Claim) map[string]interface{} {
+	token := make(map[string]interface{})
+	data, err := json.Marshal(claims)
+	if err != nil {
+		log.Errorf("Authorizer / claimsMap / Marshal error %v", err)
+		return token
+	}
+	if err = json.Unmarshal(data, &token); err != nil {
+		log.Errorf("Authorizer / claimsMap / Unmarshal error %v", err)
+	}
+	return token
+}
+
+// permissionMatches matches permission in "resource:action" form against check.
+// Both parts support path.Match patterns, resource part without slash is matched against the resource type and
+// grants every resource of matching type, e.g. "documents:read" and "*:read" grant read on "documents/42".
+// "*" grants everything.
+func permissionMatches(permission string, check *model.AccessCheck) bool {
+	if permission == Wildcard {
+		return true
+	}
+	separator := strings.LastIndex(permission, ":")
+	if separator < 0 {
+		return false
+	}
+	resourcePattern, actionPattern := permission[:separator], permission[separator+1:]
+	if ok, err := path.Match(actionPattern, check.Action); err != nil || !ok {
+		return false
+	}
+	if ok, err := path.Match(resourcePattern, check.Resource); err == nil && ok {
+		return true
+	}
+	if strings.Contains(resourcePattern, "/") {
+		return false
+	}
+	resourceType, _, _ := strings.Cut(check.Resource, "/")
+	ok, err := path.Match(resourcePattern, resourceType)
+	return err == nil && ok
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPermissionMatches(t *testing.T) {
+	tests := []struct {
+		permission string
+		check      model.AccessCheck
+		want       bool
+	}{
+		{"*", model.AccessCheck{Action: "delete", Resource: "documents/42"}, true},
+		{"documents:read", model.AccessCheck{Action: "read", Resource: "documents/42"}, true},
+		{"documents:read", model.AccessCheck{Action: "read", Resource: "documents"}, true},
+		{"documents:read", model.AccessCheck{Action: "write", Resource: "documents/42"}, false},
+		{"documents:*", model.AccessCheck{Action: "write", Resource: "documents/42"}, true},
+		{"documents/42:write", model.AccessCheck{Action: "write", Resource: "documents/42"}, true},
+		{"documents/42:write", model.AccessCheck{Action: "write", Resource: "documents/43"}, false},
+		{"documents/*:write", model.AccessCheck{Action: "write", Resource: "documents/43"}, true},
+		{"documents/*:write", model.AccessCheck{Action: "write", Resource: "documents/43/comments/1"}, false},
+		{"*:read", model.AccessCheck{Action: "read", Resource: "documents/42"}, true},
+		{"*:read", model.AccessCheck{Action: "read", Resource: "orders"}, true},
+		{"*:read", model.AccessCheck{Action: "write", Resource: "documents/42"}, false},
+		{"doc*:read", model.AccessCheck{Action: "read", Resource: "documents/42"}, true},
+		{"doc*:read", model.AccessCheck{Action: "read", Resource: "orders/1"}, false},
+		{"*/42:read", model.AccessCheck{Action: "read", Resource: "documents/42"}, true},
+		{"orders:read", model.AccessCheck{Action: "read", Resource: "documents/42"}, false},
+		{"invalid", model.AccessCheck{Action: "read", Resource: "invalid"}, false},
+	}
+	for _, tt := range tests {
+		check := tt.check
+		assert.Equal(t, tt.want, permissionMatches(tt.permission, &check),
+			"Unexpected match of permission %q against %s on %s", tt.permission, tt.check.Action, tt.check.Resource)
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:        mockAccessTokenKey,
+		AccessTokenExpiration: 30 * time.Minute}
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(false)
+	mockDecisionCache := mocks.NewDecisionCache(t)
+	mockPolicyEvaluator := mocks.NewPolicyEvaluator(t)
+	auth := newTestAuthService(t, &cfg, nil, nil, AuthOptions{TokenDenylist: mockTokenDenylist})
+	authorizer := NewAuthorizerService(&config.AuthorizationConfig{DecisionCacheTTL: time.Minute}, mockDecisionCache,
+		mockPolicyEvaluator, auth)
+	accessToken := newTestAccessToken(t, auth, &Claim{Permissions: []string{"documents:read"}})
+	cachedDecision := &model.Decision{Allowed: true, Reason: "cached"}
+	mockDecisionCache.On("Load", mockRefreshToken, "1\x00read\x00documents/42").Return(nil, false)
+	mockDecisionCache.On("Save", mockRefreshToken, mock.AnythingOfType("int64"), "1\x00read\x00documents/42",
+		mock.AnythingOfType("*model.Decision")).Return()
+	mockDecisionCache.On("Load", mockRefreshToken, "1\x00write\x00documents/42").Return(nil, false)
+	mockDecisionCache.On("Save", mockRefreshToken, mock.AnythingOfType("int64"), "1\x00write\x00documents/42",
+		mock.AnythingOfType("*model.Decision")).Return()
+	mockDecisionCache.On("Load", mockRefreshToken, "1\x00read\x00orders/1").Return(cachedDecision, true)
+	mockPolicyEvaluator.On("Evaluate", mock.AnythingOfType("*model.PolicyInput")).Return(nil, false)
+	mockPolicyEvaluator.On("Revision").Return(uint64(1))
+
+	decisions := authorizer.Authorize(context.Background(), accessToken, []*model.AccessCheck{
+		{Action: "read", Resource: "documents/42"},
+		{Action: "write", Resource: "documents/42"},
+		{Action: "read", Resource: "orders/1"},
+	})
+
+	assert.Len(t, decisions, 3, "Expected decision for each check")
+	assert.True(t, decisions[0].Allowed, "Expected read on document to be allowed")
+	assert.False(t, decisions[1].Allowed, "Expected write on document to be denied")
+	assert.NotEmpty(t, decisions[1].Reason, "Expected deny reason")
+	assert.Equal(t, cachedDecision, decisions[2], "Expected cached decision")
+}
+
+func TestAuthorizer_Authorize_InvalidToken(t *testing.T) {
+	cfg := config.JwtConfig{AccessTokenKey: mockAccessTokenKey}
+	auth := newTestAuthService(t, &cfg, nil, nil, AuthOptions{})
+	authorizer := NewAuthorizerService(&config.AuthorizationConfig{DecisionCacheTTL: time.Minute}, nil, nil, auth)
+
+	decisions := authorizer.Authorize(context.Background(), "invalid-token", []*model.AccessCheck{
+		{Action: "read", Resource: "documents/42"},
+	})
+
+	assert.Len(t, decisions, 1, "Expected decision for each check")
+	assert.False(t, decisions[0].Allowed, "Expected check with invalid token to be denied")
+}
+
+func TestAuthorizer_Authorize_Policies(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:        mockAccessTokenKey,
+		AccessTokenExpiration: 30 * time.Minute}
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(false)
+	mockDecisionCache := mocks.NewDecisionCache(t)
+	mockPolicyEvaluator := mocks.NewPolicyEvaluator(t)
+	auth := newTestAuthService(t, &cfg, nil, nil, AuthOptions{TokenDenylist: mockTokenDenylist})
+	authorizer := NewAuthorizerService(&config.AuthorizationConfig{DecisionCacheTTL: time.Minute}, mockDecisionCache,
+		mockPolicyEvaluator, auth)
+	accessToken := newTestAccessToken(t, auth, &Claim{Permissions: []string{"documents:read"}})
+	mockDecisionCache.On("Load", mockRefreshToken, mock.AnythingOfType("string")).Return(nil, false)
+	mockPolicyEvaluator.On("Revision").Return(uint64(1))
+	mockPolicyEvaluator.On("Evaluate", mock.MatchedBy(func(input *model.PolicyInput) bool {
+		return input.Action == "read" && input.Token["Username"] == mockUsername
+	})).Return(&model.Decision{Reason: "denied by policy"}, true)
+	mockPolicyEvaluator.On("Evaluate", mock.MatchedBy(func(input *model.PolicyInput) bool {
+		return input.Action == "edit" && input.ResourceAttributes["owner"] == mockUserID
+	})).Return(&model.Decision{Allowed: true, Reason: "allowed by policy"}, true)
+
+	decisions := authorizer.Authorize(context.Background(), accessToken, []*model.AccessCheck{
+		{Action: "read", Resource: "documents/42"},
+		{Action: "edit", Resource: "documents/42", ResourceAttributes: map[string]interface{}{"owner": mockUserID}},
+	})
+
+	assert.False(t, decisions[0].Allowed, "Expected deny policy to override permission")
+	assert.True(t, decisions[1].Allowed, "Expected allow policy to grant access without permission")
+	mockDecisionCache.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+)
+
+// Grant types client profiles may be restricted to
+const (
+	GrantTypePassword     = "password"
+	GrantTypeRefreshToken = "refresh_token"
+	GrantTypeTrusted      = "trusted"
+	GrantTypePasswordless = "passwordless"
+	GrantTypeFederated    = "federated"
+)
+
+var (
+	// ErrInvalidClient godoc
+	ErrInvalidClient = errors.New("invalid client")
+	// ErrUnauthorizedGrantType godoc
+	ErrUnauthorizedGrantType = errors.New("grant type is not allowed for client")
+)
+
+type clientKey struct{}
+
+// WithClient returns context carrying client id
+func WithClient(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientKey{}, clientID)
+}
+
+// ClientFromContext returns client id carried by context, empty for default client
+func ClientFromContext(ctx context.Context) string {
+	clientID, _ := ctx.Value(clientKey{}).(string)
+	return clientID
+}
+
+// client resolves profile of the context client in the context tenant and checks it may use grant type
+func (a *Auth) client(ctx context.Context, grantType string) (*config.ClientProfile, error) {
+	tenant, err := a.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := a.cfg.Client(tenant, ClientFromContext(ctx))
+	if !ok {
+		return nil, ErrInvalidClient
+	}
+	if len(client.GrantTypes) > 0 && !contains(client.GrantTypes, grantType) {
+		return nil, ErrUnauthorizedGrantType
+	}
+	return client, nil
+}
+
+// AuthenticateClient checks credentials of named client in the context tenant, either client secret
+// or mutual TLS certificate carried by context. Default client needs no credentials.
+func (a *Auth) AuthenticateClient(ctx context.Context, clientID, secret string) error {
+	if clientID == "" || clientID == a.cfg.DefaultClient {
+		return nil
+	}
+	tenant, err := a.tenant(ctx)
+	if err != nil {
+		return err
+	}
+	client, ok := a.cfg.Client(tenant, clientID)
+	if !ok {
+		return ErrInvalidClient
+	}
+	if secret != "" {
+		if client.SecretHash == "" || subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(client.SecretHash)) != 1 {
+			return ErrInvalidClient
+		}
+		return nil
+	}
+	if x5t := certificateFromContext(ctx); x5t == "" || !contains(client.CertificateThumbprints, x5t) {
+		return ErrInvalidClient
+	}
+	return nil
+}
+
+// sessionExpiration returns absolute expiration of session started by client, zero when client sessions don't expire
+func sessionExpiration(session *model.Session, client *config.ClientProfile) int64 {
+	if client.AbsoluteTimeout <= 0 {
+		return 0
+	}
+	return time.Unix(session.AuthTime, 0).Add(client.AbsoluteTimeout).Unix()
+}
+
+// clientScope returns requested scope narrowed to client scopes, clients without scopes are not restricted
+func clientScope(client *config.ClientProfile, scope string) (string, error) {
+	if len(client.Scopes) == 0 {
+		return strings.Join(strings.Fields(scope), " "), nil
+	}
+	return downscope(strings.Join(client.Scopes, " "), scope)
+}
+
+// maxAccessTokenExpiration returns longest access token lifetime any client may get in tenant
+func (a *Auth) maxAccessTokenExpiration(tenant *config.Tenant) time.Duration {
+	expiration := tenant.AccessTokenExpiration
+	for id := range a.cfg.Clients {
+		if client, ok := a.cfg.Client(tenant, id); ok && client.AccessTokenExpiration > expiration {
+			expiration = client.AccessTokenExpiration
+		}
+	}
+	return expiration
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/userService/protocol/userService"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrExternalCredentials godoc
+	ErrExternalCredentials = errors.New("credentials are managed by external directory")
+	// ErrRealmUserNotFound godoc
+	ErrRealmUserNotFound = errors.New("user not found in credential realm")
+)
+
+// CredentialVerifier used to check username and password against a user directory,
+// unknown users and wrong passwords both fail with ErrInvalidPassword
+type CredentialVerifier interface {
+	VerifyCredentials(ctx context.Context, username, password string) (*model.Identity, error)
+}
+
+// RoleResolver used to look up current roles of directory user without credentials,
+// users no longer in the directory fail with ErrRealmUserNotFound
+type RoleResolver interface {
+	ResolveRoles(ctx context.Context, username string) ([]string, error)
+}
+
+// UserServiceVerifier verifies passwords against userService password hashes
+type UserServiceVerifier struct {
+	userServiceClient userService.UserServiceClient
+}
+
+// NewUserServiceVerifier creates new UserServiceVerifier
+func NewUserServiceVerifier(userServiceClient userService.UserServiceClient) *UserServiceVerifier {
+	return &UserServiceVerifier{userServiceClient: userServiceClient}
+}
+
+// VerifyCredentials compares password with user bcrypt hash
+func (v *UserServiceVerifier) VerifyCredentials(ctx context.Context, username, password string) (*model.Identity, error) {
+	user, err := v.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: username,
+	})
+	if err != nil {
+		log.Errorf("UserServiceVerifier / VerifyCredentials /GetByUsername err %v ", err)
+		return nil, err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return nil, ErrInvalidPassword
+	} else if err != nil {
+		log.Errorf("UserServiceVerifier / VerifyCredentials / CompareHashAndPassword / error %v", err)
+		return nil, err
+	}
+	return &model.Identity{UserID: user.Uuid, Username: username}, nil
+}
+
+// CredentialRealm verifier responsible for usernames matching the pattern
+type CredentialRealm struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	Verifier CredentialVerifier
+}
+
+// RealmVerifier routes credentials to the first realm matching username,
+// usernames matching no realm are checked by the fallback verifier
+type RealmVerifier struct {
+	fallback CredentialVerifier
+	realms   []CredentialRealm
+}
+
+// NewRealmVerifier creates new RealmVerifier, realms are matched in given order
+func NewRealmVerifier(fallback CredentialVerifier, realms ...CredentialRealm) *RealmVerifier {
+	return &RealmVerifier{fallback: fallback, realms: realms}
+}
+
+// VerifyCredentials verifies credentials with the realm responsible for username
+func (v *RealmVerifier) VerifyCredentials(ctx context.Context, username, password string) (*model.Identity, error) {
+	for _, realm := range v.realms {
+		if realm.Pattern.MatchString(username) {
+			return realm.Verifier.VerifyCredentials(ctx, username, password)
+		}
+	}
+	return v.fallback.VerifyCredentials(ctx, username, password)
+}
+
+// RealmRoles returns current roles of user signed in with named realm,
+// realms that can't resolve roles without credentials grant none
+func (v *RealmVerifier) RealmRoles(ctx context.Context, realmName, username string) ([]string, error) {
+	for _, realm := range v.realms {
+		if realm.Name != realmName {
+			continue
+		}
+		if resolver, ok := realm.Verifier.(RoleResolver); ok {
+			return resolver.ResolveRoles(ctx, username)
+		}
+		return nil, nil
+	}
+	return nil, ErrRealmUserNotFound
+}
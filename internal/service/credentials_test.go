@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRealmVerifier_VerifyCredentials(t *testing.T) {
+	mockFallback := mocks.NewCredentialVerifier(t)
+	mockFallback.On("VerifyCredentials", mock.Anything, mockUsername, mockPassword).
+		Return(&model.Identity{UserID: mockUserID, Username: mockUsername}, nil)
+	mockDirectory := mocks.NewCredentialVerifier(t)
+	mockDirectory.On("VerifyCredentials", mock.Anything, "alice@corp.example.com", mockPassword).
+		Return(&model.Identity{UserID: "alice-id", Username: "alice@corp.example.com", Realm: "corp"}, nil)
+	verifier := NewRealmVerifier(mockFallback, CredentialRealm{
+		Name:     "corp",
+		Pattern:  regexp.MustCompile(`@corp\.example\.com$`),
+		Verifier: mockDirectory,
+	})
+
+	t.Log("username matching realm pattern is verified by the realm")
+	identity, err := verifier.VerifyCredentials(context.Background(), "alice@corp.example.com", mockPassword)
+	assert.NoError(t, err, "Expected no error for realm user")
+	assert.Equal(t, "corp", identity.Realm, "Expected identity from corp realm")
+
+	t.Log("other usernames fall back to userService")
+	identity, err = verifier.VerifyCredentials(context.Background(), mockUsername, mockPassword)
+	assert.NoError(t, err, "Expected no error for userService user")
+	assert.Equal(t, "", identity.Realm, "Expected identity from userService")
+}
+
+func TestAuth_SignIn_CredentialRealm(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		RequireVerifiedEmail:   true}
+	mockVerifier := mocks.NewCredentialVerifier(t)
+	mockVerifier.On("VerifyCredentials", mock.Anything, mockUsername, mockPassword).
+		Return(&model.Identity{UserID: mockUserID, Username: mockUsername, Realm: "corp", Roles: []string{"admin"}}, nil)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadRole", "", "admin").Return(&model.Role{Name: "admin", Permissions: []string{"admin"}}, true)
+	mockRoleStorage.On("LoadRole", "", "viewer").Return(&model.Role{Name: "viewer", Permissions: []string{"read"}}, true)
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	session := &model.Session{}
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { *session = *args.Get(0).(*model.Session) }).Return()
+	mockSessionStorage.On("Load", "", mockUsername).Return(session, true)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{
+		TokenDenylist:      mockTokenDenylist,
+		RoleStorage:        mockRoleStorage,
+		CredentialVerifier: mockVerifier,
+	})
+
+	t.Log("realm user signs in without verified email and gets realm roles")
+	_, accessToken, err := auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in realm user")
+	claims, err := auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating token")
+	assert.Equal(t, mockUserID, claims.Subject, "Expected realm user id as subject")
+	assert.Equal(t, []string{"admin", "viewer"}, claims.Roles, "Expected realm roles merged with assigned roles")
+	assert.Equal(t, []string{"admin", "read"}, claims.Permissions, "Expected permissions of both roles")
+
+	t.Log("realm user password cannot be changed here")
+	password := NewPasswordService(&config.PasswordPolicyConfig{MinLength: 8, MaxChangeAttempts: 5}, auth,
+		mocks.NewPasswordStore(t), newMockAttemptCounter(t))
+	_, _, err = password.ChangePassword(context.Background(), accessToken, mockPassword, "new-password")
+	assert.ErrorIs(t, err, ErrExternalCredentials, "Expected ErrExternalCredentials for realm user")
+}
+
+// testDirectory credential realm directory whose user groups can change between sign-ins
+type testDirectory struct {
+	roles []string
+	found bool
+}
+
+func (d *testDirectory) VerifyCredentials(_ context.Context, username, _ string) (*model.Identity, error) {
+	return &model.Identity{UserID: mockUserID, Username: username, Realm: "corp", Roles: d.roles}, nil
+}
+
+func (d *testDirectory) ResolveRoles(_ context.Context, _ string) ([]string, error) {
+	if !d.found {
+		return nil, ErrRealmUserNotFound
+	}
+	return d.roles, nil
+}
+
+func TestAuth_RefreshTokens_CredentialRealm(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	directory := &testDirectory{roles: []string{"admin"}, found: true}
+	verifier := NewRealmVerifier(mocks.NewCredentialVerifier(t), CredentialRealm{
+		Name:     "corp",
+		Pattern:  regexp.MustCompile(`.*`),
+		Verifier: directory,
+	})
+	f := newAuthFixture(t, &cfg, AuthOptions{CredentialVerifier: verifier})
+	refreshToken, _, err := f.auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in realm user")
+
+	t.Log("group change in directory applies on refresh")
+	directory.roles = []string{"viewer"}
+	refreshToken, accessToken, err := f.auth.RefreshTokens(context.Background(), refreshToken, mockUsername)
+	assert.NoError(t, err, "Expected no error when refreshing realm session")
+	claims, err := f.auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, []string{"viewer"}, claims.Roles, "Expected roles resolved again on refresh")
+
+	t.Log("user removed from directory can't refresh")
+	directory.found = false
+	_, _, err = f.auth.RefreshTokens(context.Background(), refreshToken, mockUsername)
+	assert.ErrorIs(t, err, ErrRealmUserNotFound, "Expected ErrRealmUserNotFound for removed user")
+}
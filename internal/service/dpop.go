@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/go-jose/go-jose/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// dpopProofType typ header of DPoP proofs
+const dpopProofType = "dpop+jwt"
+
+var (
+	// ErrInvalidDPoPProof godoc
+	ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+	// ErrDPoPProofReplayed godoc
+	ErrDPoPProofReplayed = errors.New("DPoP proof is already used")
+	// ErrDPoPProofRequired godoc
+	ErrDPoPProofRequired = errors.New("DPoP proof is required for bound token")
+	// ErrDPoPKeyMismatch godoc
+	ErrDPoPKeyMismatch = errors.New("DPoP key does not match token binding")
+)
+
+// dpopAlgorithms asymmetric algorithms DPoP proofs may be signed with
+var dpopAlgorithms = []string{
+	string(jose.ES256), string(jose.ES384), string(jose.ES512), string(jose.EdDSA),
+	string(jose.RS256), string(jose.RS384), string(jose.RS512),
+	string(jose.PS256), string(jose.PS384), string(jose.PS512),
+}
+
+// DPoPReplayCache used to reject replayed proofs, Remember returns false for already used proof
+type DPoPReplayCache interface {
+	Remember(proofID string, expiresAt int64) bool
+}
+
+// DPoP sender-constrained tokens service struct, see RFC 9449
+type DPoP struct {
+	cfg         *config.DPoPConfig
+	replayCache DPoPReplayCache
+}
+
+// dpopClaims DPoP proof claims, ath is only present when proof accompanies access token
+type dpopClaims struct {
+	ID       string `json:"jti"`
+	Method   string `json:"htm"`
+	URI      string `json:"htu"`
+	IssuedAt int64  `json:"iat"`
+	ATH      string `json:"ath,omitempty"`
+}
+
+// NewDPoPService creates new DPoP service
+func NewDPoPService(cfg *config.DPoPConfig, replayCache DPoPReplayCache) *DPoP {
+	return &DPoP{cfg: cfg, replayCache: replayCache}
+}
+
+type dpopProofKey struct{}
+
+// dpopProof key proven by DPoP proof of the request and hash of the access token the proof covers
+type dpopProof struct {
+	jkt string
+	ath string
+}
+
+// WithProof verifies DPoP proof of request to uri with method and puts the proven key into context.
+// Tokens issued with the context are bound to the key, bound tokens are only accepted with proof of their key
+// covering them. Empty proof leaves context without proof.
+func (d *DPoP) WithProof(ctx context.Context, proof, method, uri string) (context.Context, error) {
+	if proof == "" {
+		return context.WithValue(ctx, dpopProofKey{}, dpopProof{}), nil
+	}
+	jkt, claims, err := d.verifyProof(proof, method, uri)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, dpopProofKey{}, dpopProof{jkt: jkt, ath: claims.ATH}), nil
+}
+
+func dpopProofFromContext(ctx context.Context) dpopProof {
+	proof, _ := ctx.Value(dpopProofKey{}).(dpopProof)
+	return proof
+}
+
+func dpopKeyFromContext(ctx context.Context) string {
+	return dpopProofFromContext(ctx).jkt
+}
+
+// RPCURI returns htu token RPCs expect, RPC full method is the path of its HTTP/2 request
+func (d *DPoP) RPCURI(authority, fullMethod string) string {
+	base := d.cfg.ServiceURI
+	if base == "" {
+		base = "https://" + authority
+	}
+	return strings.TrimSuffix(base, "/") + fullMethod
+}
+
+// verifyDPoPBinding checks token bound to DPoP key is presented with proof of that key covering the token,
+// tokens without jkt confirmation are not checked
+func verifyDPoPBinding(ctx context.Context, claims *Claim, accessToken string) error {
+	if claims.Cnf == nil || claims.Cnf.JKT == "" {
+		return nil
+	}
+	proof := dpopProofFromContext(ctx)
+	if proof.jkt == "" {
+		return ErrDPoPProofRequired
+	}
+	if proof.jkt != claims.Cnf.JKT {
+		return ErrDPoPKeyMismatch
+	}
+	hash := sha256.Sum256([]byte(accessToken))
+	if proof.ath != base64.RawURLEncoding.EncodeToString(hash[:]) {
+		return ErrInvalidDPoPProof
+	}
+	return nil
+}
+
+// verifyProof verifies proof signature with its embedded public key, the request it was created for and its age.
+// Every proof is accepted once.
+func (d *DPoP) verifyProof(proof, method, uri string) (string, *dpopClaims, error) {
+	signed, err := jose.ParseSigned(proof)
+	if err != nil || len(signed.Signatures) != 1 {
+		return "", nil, ErrInvalidDPoPProof
+	}
+	header := signed.Signatures[0].Protected
+	if header.ExtraHeaders[jose.HeaderType] != dpopProofType || !contains(dpopAlgorithms, header.Algorithm) ||
+		header.JSONWebKey == nil || !header.JSONWebKey.IsPublic() || !header.JSONWebKey.Valid() {
+		return "", nil, ErrInvalidDPoPProof
+	}
+	payload, err := signed.Verify(header.JSONWebKey)
+	if err != nil {
+		log.Errorf("invalid DPoP proof: %v", err)
+		return "", nil, ErrInvalidDPoPProof
+	}
+	claims := &dpopClaims{}
+	if err = json.Unmarshal(payload, claims); err != nil || claims.ID == "" {
+		return "", nil, ErrInvalidDPoPProof
+	}
+	if claims.Method != method || !sameTargetURI(claims.URI, uri) {
+		return "", nil, ErrInvalidDPoPProof
+	}
+	now := time.Now()
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	if issuedAt.After(now.Add(d.cfg.ClockSkew)) || issuedAt.Add(d.cfg.ProofLifetime+d.cfg.ClockSkew).Before(now) {
+		return "", nil, ErrInvalidDPoPProof
+	}
+	thumbprint, err := header.JSONWebKey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", nil, ErrInvalidDPoPProof
+	}
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+	expiresAt := issuedAt.Add(d.cfg.ProofLifetime + 2*d.cfg.ClockSkew).Unix()
+	if !d.replayCache.Remember(jkt+"|"+claims.ID, expiresAt) {
+		return "", nil, ErrDPoPProofReplayed
+	}
+
+	return jkt, claims, nil
+}
+
+// sameTargetURI compares htu with request URI ignoring query and fragment, see RFC 9449 section 4.3
+func sameTargetURI(htu, uri string) bool {
+	proofURI, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	requestURI, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(proofURI.Scheme, requestURI.Scheme) && strings.EqualFold(proofURI.Host, requestURI.Host) &&
+		targetPath(proofURI) == targetPath(requestURI)
+}
+
+func targetPath(uri *url.URL) string {
+	if uri.Path == "" {
+		return "/"
+	}
+	return uri.Path
+}
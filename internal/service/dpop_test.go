@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mockTokenURI    = "https://auth.example.com/authService.AuthGRPCService/SignIn"
+	mockResourceURI = "https://api.example.com/documents"
+)
+
+var mockDPoPConfig = &config.DPoPConfig{ProofLifetime: time.Minute, ClockSkew: 30 * time.Second}
+
+func newDPoPKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err, "Expected no error when generating DPoP key")
+	thumbprint, err := (&jose.JSONWebKey{Key: &key.PublicKey}).Thumbprint(crypto.SHA256)
+	assert.NoError(t, err, "Expected no error when computing thumbprint")
+	return key, base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
+func newDPoPProof(t *testing.T, key *ecdsa.PrivateKey, method, uri string, issuedAt time.Time, accessToken string) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(dpopProofType))
+	assert.NoError(t, err, "Expected no error when creating signer")
+	claims := &dpopClaims{ID: uuid.New().String(), Method: method, URI: uri, IssuedAt: issuedAt.Unix()}
+	if accessToken != "" {
+		hash := sha256.Sum256([]byte(accessToken))
+		claims.ATH = base64.RawURLEncoding.EncodeToString(hash[:])
+	}
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err, "Expected no error when encoding proof claims")
+	signed, err := signer.Sign(payload)
+	assert.NoError(t, err, "Expected no error when signing proof")
+	proof, err := signed.CompactSerialize()
+	assert.NoError(t, err, "Expected no error when serializing proof")
+	return proof
+}
+
+func TestDPoP_WithProof(t *testing.T) {
+	mockReplayCache := mocks.NewDPoPReplayCache(t)
+	dpop := NewDPoPService(mockDPoPConfig, mockReplayCache)
+	key, jkt := newDPoPKey(t)
+	proof := newDPoPProof(t, key, "POST", mockTokenURI, time.Now(), "")
+
+	mockReplayCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true).Once()
+	ctx, err := dpop.WithProof(context.Background(), proof, "POST", mockTokenURI+"?ignored=1")
+	assert.NoError(t, err, "Expected no error when verifying proof")
+	assert.Equal(t, jkt, dpopKeyFromContext(ctx), "Thumbprint mismatch")
+
+	t.Log("empty proof clears proof of the context")
+	ctx, err = dpop.WithProof(ctx, "", "GET", mockResourceURI)
+	assert.NoError(t, err, "Expected no error without proof")
+	assert.Empty(t, dpopKeyFromContext(ctx), "Expected no proven key")
+
+	t.Log("proof is accepted once")
+	mockReplayCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(false).Once()
+	_, err = dpop.WithProof(context.Background(), proof, "POST", mockTokenURI)
+	assert.ErrorIs(t, err, ErrDPoPProofReplayed, "Expected ErrDPoPProofReplayed for used proof")
+
+	t.Log("proof of other request or stale proof is rejected")
+	_, err = dpop.WithProof(context.Background(), proof, "GET", mockTokenURI)
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "Expected ErrInvalidDPoPProof for other method")
+	_, err = dpop.WithProof(context.Background(), proof, "POST", mockResourceURI)
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "Expected ErrInvalidDPoPProof for other URI")
+	stale := newDPoPProof(t, key, "POST", mockTokenURI, time.Now().Add(-5*time.Minute), "")
+	_, err = dpop.WithProof(context.Background(), stale, "POST", mockTokenURI)
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "Expected ErrInvalidDPoPProof for stale proof")
+	_, err = dpop.WithProof(context.Background(), "not.a.proof", "POST", mockTokenURI)
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "Expected ErrInvalidDPoPProof for malformed proof")
+}
+
+func TestDPoP_BoundTokens(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	var session *model.Session
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	mockReplayCache := mocks.NewDPoPReplayCache(t)
+	mockReplayCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true)
+	dpop := NewDPoPService(mockDPoPConfig, mockReplayCache)
+	key, jkt := newDPoPKey(t)
+	proofContext := func(key *ecdsa.PrivateKey, method, uri, accessToken string) context.Context {
+		ctx, err := dpop.WithProof(context.Background(), newDPoPProof(t, key, method, uri, time.Now(), accessToken),
+			method, uri)
+		assert.NoError(t, err, "Expected no error when verifying proof")
+		return ctx
+	}
+
+	refreshToken, accessToken, err := auth.GenerateTokens(proofContext(key, "POST", mockTokenURI, ""), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	claims, err := auth.ValidateToken(proofContext(key, "GET", mockResourceURI, accessToken), accessToken,
+		ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating bound token with proof of bound key")
+	assert.Equal(t, &Confirmation{JKT: jkt}, claims.Cnf, "Expected access token bound to DPoP key")
+	assert.Equal(t, jkt, session.JKT, "Expected session bound to DPoP key")
+
+	t.Log("bound token needs proof of the same key covering the token")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrDPoPProofRequired, "Expected ErrDPoPProofRequired without proof")
+	otherKey, _ := newDPoPKey(t)
+	_, err = auth.ValidateToken(proofContext(otherKey, "GET", mockResourceURI, accessToken), accessToken,
+		ValidateOptions{})
+	assert.ErrorIs(t, err, ErrDPoPKeyMismatch, "Expected ErrDPoPKeyMismatch for other key")
+	_, err = auth.ValidateToken(proofContext(key, "GET", mockResourceURI, ""), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "Expected ErrInvalidDPoPProof without access token hash")
+
+	t.Log("auth service RPCs taking access token check the binding too")
+	err = auth.SignOut(context.Background(), accessToken, false)
+	assert.ErrorIs(t, err, ErrDPoPProofRequired, "Expected ErrDPoPProofRequired when signing out without proof")
+
+	t.Log("bound session is refreshed with the same key only")
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(session, true).Once()
+	_, _, err = auth.RefreshTokens(context.Background(), refreshToken, mockUsername)
+	assert.ErrorIs(t, err, ErrDPoPKeyMismatch, "Expected ErrDPoPKeyMismatch without proof")
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(session, true).Once()
+	_, accessToken, err = auth.RefreshTokens(proofContext(key, "POST", mockTokenURI, ""), refreshToken, mockUsername)
+	assert.NoError(t, err, "Expected no error when refreshing with bound key")
+	claims, err = auth.ValidateToken(proofContext(key, "GET", mockResourceURI, accessToken), accessToken,
+		ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating refreshed token")
+	assert.Equal(t, &Confirmation{JKT: jkt}, claims.Cnf, "Expected refreshed token bound to DPoP key")
+}
+
+func TestDPoP_SignIn(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	var session *model.Session
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	mockVerifier := mocks.NewCredentialVerifier(t)
+	mockVerifier.On("VerifyCredentials", mock.Anything, mockUsername, mockPassword).
+		Return(&model.Identity{UserID: mockUserID, Username: mockUsername}, nil)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{
+		TokenDenylist:      mockTokenDenylist,
+		RoleStorage:        mockRoleStorage,
+		CredentialVerifier: mockVerifier,
+	})
+	mockReplayCache := mocks.NewDPoPReplayCache(t)
+	mockReplayCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true)
+	dpop := NewDPoPService(mockDPoPConfig, mockReplayCache)
+	key, jkt := newDPoPKey(t)
+	ctx, err := dpop.WithProof(context.Background(), newDPoPProof(t, key, "POST", mockTokenURI, time.Now(), ""),
+		"POST", mockTokenURI)
+	assert.NoError(t, err, "Expected no error when verifying proof")
+
+	t.Log("password sign in with DPoP proof issues tokens bound to the proof key")
+	_, accessToken, err := auth.SignIn(ctx, mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+	ctx, err = dpop.WithProof(context.Background(), newDPoPProof(t, key, "GET", mockResourceURI, time.Now(), accessToken),
+		"GET", mockResourceURI)
+	assert.NoError(t, err, "Expected no error when verifying proof")
+	claims, err := auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating bound token with proof of bound key")
+	assert.Equal(t, &Confirmation{JKT: jkt}, claims.Cnf, "Expected access token bound to DPoP key")
+	assert.Equal(t, jkt, session.JKT, "Expected session bound to DPoP key")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrDPoPProofRequired, "Expected ErrDPoPProofRequired without proof")
+}
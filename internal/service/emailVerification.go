@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// emailVerificationAudience keeps verification tokens from being accepted anywhere else
+const emailVerificationAudience = "email_verification"
+
+var (
+	// ErrEmailNotVerified godoc
+	ErrEmailNotVerified = errors.New("email is not verified")
+	// ErrInvalidVerificationToken godoc
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	// ErrEmailVerificationDisabled godoc
+	ErrEmailVerificationDisabled = errors.New("email verification is not available")
+)
+
+// EmailVerificationStorage used to store users who confirmed their email
+type EmailVerificationStorage interface {
+	MarkVerified(userID string) error
+	IsVerified(userID string) bool
+}
+
+// emailVerificationClaim verification token claims, token is bound to the address it was sent to
+type emailVerificationClaim struct {
+	Email string `json:"email"`
+	jwt.StandardClaims
+}
+
+// EmailVerification email verification service struct
+type EmailVerification struct {
+	cfg    *config.EmailVerificationConfig
+	auth   *Auth
+	mailer Mailer
+}
+
+// NewEmailVerificationService creates new EmailVerification service
+func NewEmailVerificationService(cfg *config.EmailVerificationConfig, auth *Auth, mailer Mailer) *EmailVerification {
+	return &EmailVerification{cfg: cfg, auth: auth, mailer: mailer}
+}
+
+// SendVerification mails signed verification token to user email, unknown and already verified users are skipped
+func (e *EmailVerification) SendVerification(ctx context.Context, username string) error {
+	user, err := e.auth.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: username,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	} else if err != nil {
+		log.Errorf("EmailVerification / SendVerification /GetByUsername err %v ", err)
+		return err
+	}
+	if e.auth.emailVerified(user.Uuid) {
+		return nil
+	}
+
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &emailVerificationClaim{
+		Email: user.Email,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    e.auth.cfg.Issuer,
+			Subject:   user.Uuid,
+			Audience:  emailVerificationAudience,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(e.cfg.TokenExpiration).Unix(),
+		},
+	}).SignedString([]byte(e.cfg.TokenKey))
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Use this code to verify your email: %s\n", token)
+	if e.cfg.URL != "" {
+		body = fmt.Sprintf("Follow the link to verify your email: %s?token=%s\n", e.cfg.URL, token)
+	}
+	body += fmt.Sprintf("It expires in %s.\n", e.cfg.TokenExpiration)
+
+	return e.mailer.Send(ctx, &model.Message{To: user.Email, Subject: "Verify your email", Body: body})
+}
+
+// VerifyEmail marks token owner email as verified if it is still the address the token was sent to
+func (e *EmailVerification) VerifyEmail(ctx context.Context, token string) error {
+	claims := &emailVerificationClaim{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrUnexpectedTokenSigningMethod
+		}
+		return []byte(e.cfg.TokenKey), nil
+	})
+	if err != nil {
+		log.Errorf("invalid email verification token: %v", err)
+		return ErrInvalidVerificationToken
+	}
+	if !claims.VerifyAudience(emailVerificationAudience, true) || claims.Subject == "" {
+		return ErrInvalidVerificationToken
+	}
+	user, err := e.auth.userServiceClient.GetByID(ctx, &userService.GetByIDRequest{Uuid: claims.Subject})
+	if status.Code(err) == codes.NotFound {
+		return ErrInvalidVerificationToken
+	} else if err != nil {
+		log.Errorf("EmailVerification / VerifyEmail /GetByID err %v ", err)
+		return err
+	}
+	if user.Email != claims.Email {
+		return ErrInvalidVerificationToken
+	}
+	if e.auth.emailVerificationStorage == nil {
+		return ErrEmailVerificationDisabled
+	}
+
+	if err = e.auth.emailVerificationStorage.MarkVerified(user.Uuid); err != nil {
+		log.Errorf("EmailVerification / VerifyEmail / MarkVerified err %v ", err)
+		return err
+	}
+	e.auth.auditLogger.Emit(&model.AuditEvent{
+		Type:     model.AuditEventEmailVerified,
+		TenantID: TenantFromContext(ctx),
+		Username: user.Name,
+		Time:     time.Now().Unix(),
+	})
+
+	return nil
+}
+
+// emailVerified checks if user confirmed their email, nil storage means emails are not tracked
+func (a *Auth) emailVerified(userID string) bool {
+	return a.emailVerificationStorage != nil && a.emailVerificationStorage.IsVerified(userID)
+}
+
+// markEmailVerified records email verified elsewhere, e.g. asserted by trusted identity provider
+func (a *Auth) markEmailVerified(userID string) {
+	if a.emailVerificationStorage == nil {
+		return
+	}
+	if err := a.emailVerificationStorage.MarkVerified(userID); err != nil {
+		log.Errorf("Auth / markEmailVerified / MarkVerified err %v ", err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/notify"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestEmailVerification_VerifyEmail(t *testing.T) {
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername, Email: mockEmail}, nil)
+	mockEmailVerificationStorage := mocks.NewEmailVerificationStorage(t)
+	mockEmailVerificationStorage.On("IsVerified", mockUserID).Return(false)
+	mockAuditLogger := mocks.NewAuditLogger(t)
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, mockUserServiceClient, AuthOptions{
+		EmailVerificationStorage: mockEmailVerificationStorage,
+		AuditLogger:              mockAuditLogger,
+	})
+	mailer := notify.NewMemoryMailer()
+	emailVerification := NewEmailVerificationService(&config.EmailVerificationConfig{
+		TokenKey: "mock-email-verification-key", TokenExpiration: time.Hour}, auth, mailer)
+
+	err := emailVerification.SendVerification(context.Background(), mockUsername)
+	assert.NoError(t, err, "Expected no error when sending verification")
+	messages := mailer.Messages(mockEmail)
+	assert.Len(t, messages, 1, "Expected verification email")
+	token := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(messages[0].Body,
+		"Use this code to verify your email: "), "\n", 2)[0])
+
+	err = emailVerification.VerifyEmail(context.Background(), token+"x")
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken, "Expected ErrInvalidVerificationToken for tampered token")
+
+	mockUserServiceClient.On("GetByID", mock.Anything, &userService.GetByIDRequest{Uuid: mockUserID}).
+		Return(&userService.GetByIDResponse{Uuid: mockUserID, Name: mockUsername, Email: "changed@example.com"}, nil).Once()
+	err = emailVerification.VerifyEmail(context.Background(), token)
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken, "Expected ErrInvalidVerificationToken after email change")
+
+	mockUserServiceClient.On("GetByID", mock.Anything, &userService.GetByIDRequest{Uuid: mockUserID}).
+		Return(&userService.GetByIDResponse{Uuid: mockUserID, Name: mockUsername, Email: mockEmail}, nil).Once()
+	mockEmailVerificationStorage.On("MarkVerified", mockUserID).Return(nil)
+	mockAuditLogger.On("Emit", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEventEmailVerified && event.Username == mockUsername
+	})).Return()
+	err = emailVerification.VerifyEmail(context.Background(), token)
+	assert.NoError(t, err, "Expected no error when verifying email")
+}
+
+func TestEmailVerification_VerifyEmail_Expired(t *testing.T) {
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername, Email: mockEmail}, nil)
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, mockUserServiceClient, AuthOptions{})
+	mailer := notify.NewMemoryMailer()
+	emailVerification := NewEmailVerificationService(&config.EmailVerificationConfig{
+		TokenKey: "mock-email-verification-key", TokenExpiration: -time.Minute}, auth, mailer)
+	err := emailVerification.SendVerification(context.Background(), mockUsername)
+	assert.NoError(t, err, "Expected no error when sending verification")
+	token := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(mailer.Messages(mockEmail)[0].Body,
+		"Use this code to verify your email: "), "\n", 2)[0])
+
+	err = emailVerification.VerifyEmail(context.Background(), token)
+
+	assert.ErrorIs(t, err, ErrInvalidVerificationToken, "Expected ErrInvalidVerificationToken for expired token")
+}
+
+func TestAuth_SignIn_RequireVerifiedEmail(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		RequireVerifiedEmail:   true}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(mockPassword), bcrypt.MinCost)
+	assert.NoError(t, err, "Expected no error when hashing password")
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername, PasswordHash: string(passwordHash)}, nil)
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockEmailVerificationStorage := mocks.NewEmailVerificationStorage(t)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		RoleStorage:              mockRoleStorage,
+		EmailVerificationStorage: mockEmailVerificationStorage,
+	})
+
+	mockEmailVerificationStorage.On("IsVerified", mockUserID).Return(false).Once()
+	_, _, err = auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.ErrorIs(t, err, ErrEmailNotVerified, "Expected ErrEmailNotVerified before email is verified")
+
+	mockEmailVerificationStorage.On("IsVerified", mockUserID).Return(true)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	_, accessToken, err := auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in with verified email")
+	claims, err := auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.True(t, claims.EmailVerified, "Expected email_verified claim")
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/coreos/go-oidc/v3/oidc"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrUnknownProvider godoc
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrInvalidFederationState godoc
+	ErrInvalidFederationState = errors.New("invalid or expired login state")
+	// ErrCodeExchangeFailed godoc
+	ErrCodeExchangeFailed = errors.New("authorization code exchange failed")
+	// ErrInvalidIDToken godoc
+	ErrInvalidIDToken = errors.New("invalid id token")
+	// ErrInvalidUserInfo godoc
+	ErrInvalidUserInfo = errors.New("invalid provider user info")
+)
+
+// federatedUsernameSeparator joins provider and subject in usernames of federated users
+const federatedUsernameSeparator = "|"
+
+// FederationStateStorage used to store pending upstream logins by state hash
+type FederationStateStorage interface {
+	Save(stateHash string, state *model.FederationState)
+	LoadAndDelete(stateHash string) (*model.FederationState, bool)
+}
+
+// FederatedIdentityStorage used to link upstream provider accounts to users
+type FederatedIdentityStorage interface {
+	Link(providerID, subject, userID string)
+	LoadUserID(providerID, subject string) (string, bool)
+}
+
+// FederatedLogin authorization request user agent is sent to
+type FederatedLogin struct {
+	AuthorizationURL string
+	State            string
+}
+
+// idTokenClaims upstream ID token claims used for account creation
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// providerEmail entry of GitHub style emails endpoint response
+type providerEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// federatedIdentity upstream user returned by provider
+type federatedIdentity struct {
+	subject       string
+	email         string
+	emailVerified bool
+}
+
+// Federation sign-in with upstream OpenID Connect and OAuth 2.0 providers service struct
+type Federation struct {
+	cfg             *config.FederationConfig
+	auth            *Auth
+	stateStorage    FederationStateStorage
+	identityStorage FederatedIdentityStorage
+
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider
+	discovery singleflight.Group
+}
+
+// NewFederationService creates new Federation service
+func NewFederationService(cfg *config.FederationConfig, auth *Auth, stateStorage FederationStateStorage,
+	identityStorage FederatedIdentityStorage) *Federation {
+	return &Federation{
+		cfg:             cfg,
+		auth:            auth,
+		stateStorage:    stateStorage,
+		identityStorage: identityStorage,
+		providers:       make(map[string]*oidc.Provider),
+	}
+}
+
+// BeginLogin returns provider authorization URL protected by single-use state, nonce and PKCE.
+// Requested scope applies to tokens issued once login completes.
+func (f *Federation) BeginLogin(ctx context.Context, providerID, scope string) (*FederatedLogin, error) {
+	client, err := f.auth.client(ctx, GrantTypeFederated)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = clientScope(client, scope); err != nil {
+		return nil, err
+	}
+	oauth2Config, provider, err := f.oauth2Config(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	f.stateStorage.Save(hashToken(state), &model.FederationState{
+		ProviderID:   providerID,
+		TenantID:     client.TenantID,
+		ClientID:     client.ID,
+		Scope:        scope,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(f.cfg.StateExpiration).Unix(),
+	})
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge(codeVerifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+	if provider != nil {
+		opts = append(opts, oidc.Nonce(nonce))
+	}
+	authorizationURL := oauth2Config.AuthCodeURL(state, opts...)
+
+	return &FederatedLogin{AuthorizationURL: authorizationURL, State: state}, nil
+}
+
+// CompleteLogin exchanges authorization code, verifies ID token against provider keys or, for OAuth 2.0
+// providers, fetches user info with provider access token and issues tokens for the linked user,
+// the user is created on first login
+func (f *Federation) CompleteLogin(ctx context.Context, state, code string) (refreshToken, accessToken string, err error) {
+	client, err := f.auth.client(ctx, GrantTypeFederated)
+	if err != nil {
+		return "", "", err
+	}
+	pending, ok := f.stateStorage.LoadAndDelete(hashToken(state))
+	if !ok || pending.ExpiresAt <= time.Now().Unix() ||
+		pending.TenantID != client.TenantID || pending.ClientID != client.ID {
+		return "", "", ErrInvalidFederationState
+	}
+	oauth2Config, provider, err := f.oauth2Config(ctx, pending.ProviderID)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", pending.CodeVerifier))
+	if err != nil {
+		log.Errorf("Federation / CompleteLogin / Exchange err %v ", err)
+		return "", "", ErrCodeExchangeFailed
+	}
+	var identity *federatedIdentity
+	if provider != nil {
+		identity, err = verifyIDToken(ctx, provider, oauth2Config.ClientID, pending.Nonce, token)
+	} else {
+		identity, err = f.userInfo(ctx, f.cfg.Providers[pending.ProviderID], oauth2Config.Client(ctx, token))
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	username, err := linkedUsername(ctx, f.auth, f.identityStorage, pending.ProviderID, identity.subject,
+		identity.email, identity.emailVerified)
+	if err != nil {
+		return "", "", err
+	}
+
+	return f.auth.generateUserTokens(ctx, GrantTypeFederated, username, pending.Scope,
+		[]string{AuthMethodFederated}, nil)
+}
+
+// verifyIDToken verifies ID token of token response against provider keys and login nonce
+func verifyIDToken(ctx context.Context, provider *oidc.Provider, clientID, nonce string,
+	token *oauth2.Token) (*federatedIdentity, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: clientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		log.Errorf("Federation / verifyIDToken / Verify err %v ", err)
+		return nil, ErrInvalidIDToken
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrInvalidIDToken
+	}
+	claims := &idTokenClaims{}
+	if err = idToken.Claims(claims); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &federatedIdentity{subject: idToken.Subject, email: claims.Email, emailVerified: claims.EmailVerified}, nil
+}
+
+// userInfo returns user of OAuth 2.0 provider read from its user info endpoint. Email is trusted only when
+// provider marks it verified, with emails endpoint configured the primary verified email is used instead.
+func (f *Federation) userInfo(ctx context.Context, providerCfg *config.FederationProvider,
+	httpClient *http.Client) (*federatedIdentity, error) {
+	claims := make(map[string]interface{})
+	if err := getProviderJSON(ctx, httpClient, providerCfg.UserInfoURL, &claims); err != nil {
+		log.Errorf("Federation / userInfo / getProviderJSON err %v ", err)
+		return nil, ErrInvalidUserInfo
+	}
+	subject := claimString(claims[providerCfg.SubjectClaim])
+	if subject == "" {
+		return nil, ErrInvalidUserInfo
+	}
+	identity := &federatedIdentity{subject: subject, email: claimString(claims[providerCfg.EmailClaim])}
+	identity.emailVerified, _ = claims["email_verified"].(bool)
+	if providerCfg.EmailsURL == "" {
+		return identity, nil
+	}
+
+	var emails []providerEmail
+	if err := getProviderJSON(ctx, httpClient, providerCfg.EmailsURL, &emails); err != nil {
+		log.Errorf("Federation / userInfo / getProviderJSON err %v ", err)
+		return nil, ErrInvalidUserInfo
+	}
+	identity.emailVerified = false
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.email, identity.emailVerified = email.Email, true
+			break
+		}
+	}
+	return identity, nil
+}
+
+// getProviderJSON decodes JSON response of provider endpoint, numbers are kept as json.Number
+// so numeric subjects don't lose precision
+func getProviderJSON(ctx context.Context, httpClient *http.Client, endpoint string, v interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	decoder := json.NewDecoder(response.Body)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// claimString returns string or numeric claim as string, empty for other types
+func claimString(claim interface{}) string {
+	switch value := claim.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return ""
+	}
+}
+
+// linkedUsername returns user linked to provider subject, new user is created on first login.
+// Username of created user embeds provider and subject, existing accounts are never linked by name or email.
+// Link missing for existing federated user, e.g. after restart, is restored by that username.
+func linkedUsername(ctx context.Context, auth *Auth, identityStorage FederatedIdentityStorage,
+	providerID, subject, email string, emailVerified bool) (string, error) {
+	if userID, ok := identityStorage.LoadUserID(providerID, subject); ok {
+		user, err := auth.userServiceClient.GetByID(ctx, &userService.GetByIDRequest{Uuid: userID})
+		if err != nil {
+			log.Errorf("linkedUsername /GetByID err %v ", err)
+			return "", err
+		}
+		return user.Name, nil
+	}
+
+	username := providerID + federatedUsernameSeparator + subject
+	existing, err := auth.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{Username: username})
+	if err == nil {
+		identityStorage.Link(providerID, subject, existing.Uuid)
+		if emailVerified {
+			auth.markEmailVerified(existing.Uuid)
+		}
+		return username, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		log.Errorf("linkedUsername /GetByUsername err %v ", err)
+		return "", err
+	}
+
+	password, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	user, err := auth.userServiceClient.Create(ctx, &userService.CreateRequest{
+		Username: username,
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
+		log.Errorf("linkedUsername /Create err %v ", err)
+		return "", err
+	}
+	identityStorage.Link(providerID, subject, user.Uuid)
+	if emailVerified {
+		auth.markEmailVerified(user.Uuid)
+	}
+
+	return username, nil
+}
+
+// oauth2Config returns client settings of provider. OpenID Connect provider metadata is discovered on first use,
+// once per provider however many logins wait for it and without blocking logins with other providers.
+// Provider is nil for OAuth 2.0 providers.
+func (f *Federation) oauth2Config(ctx context.Context, providerID string) (*oauth2.Config, *oidc.Provider, error) {
+	providerCfg, ok := f.cfg.Providers[providerID]
+	if !ok {
+		return nil, nil, ErrUnknownProvider
+	}
+	redirectURL := providerCfg.RedirectURL
+	if redirectURL == "" {
+		redirectURL = f.cfg.RedirectURL
+	}
+	if providerCfg.Type != "" && providerCfg.Type != config.FederationProviderOIDC {
+		return &oauth2.Config{
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			Endpoint:     oauth2.Endpoint{AuthURL: providerCfg.AuthURL, TokenURL: providerCfg.TokenURL},
+			RedirectURL:  redirectURL,
+			Scopes:       providerCfg.Scopes,
+		}, nil, nil
+	}
+	f.mu.Lock()
+	provider, ok := f.providers[providerID]
+	f.mu.Unlock()
+	if !ok {
+		discovered, err, _ := f.discovery.Do(providerID, func() (interface{}, error) {
+			provider, err := oidc.NewProvider(ctx, providerCfg.Issuer)
+			if err != nil {
+				return nil, err
+			}
+			f.mu.Lock()
+			f.providers[providerID] = provider
+			f.mu.Unlock()
+			return provider, nil
+		})
+		if err != nil {
+			log.Errorf("Federation / oauth2Config / NewProvider err %v ", err)
+			return nil, nil, err
+		}
+		provider = discovered.(*oidc.Provider)
+	}
+
+	return &oauth2.Config{
+		ClientID:     providerCfg.ClientID,
+		ClientSecret: providerCfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  redirectURL,
+		Scopes:       append([]string{oidc.ScopeOpenID}, providerCfg.Scopes...),
+	}, provider, nil
+}
+
+// codeChallenge returns S256 PKCE challenge of verifier, see RFC 7636 section 4.2
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mockProviderID       = "mock"
+	mockProviderClientID = "mock-client-id"
+	mockProviderSubject  = "248289761001"
+	mockProviderEmail    = "octocat@users.example.com"
+)
+
+// mockOIDCProvider local OpenID Connect provider issuing RS256 ID tokens for authorized codes,
+// provider access token reads GitHub style user info and emails
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu             sync.Mutex
+	signingKey     *rsa.PrivateKey
+	authorizations map[string]url.Values
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err, "Expected no error when generating provider key")
+	p := &mockOIDCProvider{key: key, signingKey: key, authorizations: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/keys", p.keys)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/user", p.user)
+	mux.HandleFunc("/user/emails", p.emails)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize stands in for user consent at authorization URL, returns code sent back to redirect URL
+func (p *mockOIDCProvider) authorize(t *testing.T, authorizationURL string) string {
+	u, err := url.Parse(authorizationURL)
+	assert.NoError(t, err, "Expected valid authorization URL")
+	query := u.Query()
+	assert.Equal(t, p.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path, "Authorization endpoint mismatch")
+	assert.Equal(t, mockProviderClientID, query.Get("client_id"), "Authorization client_id mismatch")
+	assert.Equal(t, "S256", query.Get("code_challenge_method"), "Expected S256 PKCE challenge")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	code := query.Get("state") + "-code"
+	p.authorizations[code] = query
+	return code
+}
+
+// setSigningKey makes provider sign ID tokens with key
+func (p *mockOIDCProvider) setSigningKey(key *rsa.PrivateKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signingKey = key
+}
+
+// setCodeChallenge replaces PKCE challenge recorded for code
+func (p *mockOIDCProvider) setCodeChallenge(code, challenge string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.authorizations[code].Set("code_challenge", challenge)
+}
+
+func (p *mockOIDCProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.server.URL,
+		"authorization_endpoint":                p.server.URL + "/authorize",
+		"token_endpoint":                        p.server.URL + "/token",
+		"jwks_uri":                              p.server.URL + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *mockOIDCProvider) keys(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "mock",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	p.mu.Lock()
+	authorization, ok := p.authorizations[r.Form.Get("code")]
+	delete(p.authorizations, r.Form.Get("code"))
+	signingKey := p.signingKey
+	p.mu.Unlock()
+	if !ok || codeChallenge(r.Form.Get("code_verifier")) != authorization.Get("code_challenge") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.server.URL,
+		"sub":            mockProviderSubject,
+		"aud":            mockProviderClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          authorization.Get("nonce"),
+		"email":          mockEmail,
+		"email_verified": true,
+	})
+	idToken.Header["kid"] = "mock"
+	rawIDToken, err := idToken.SignedString(signingKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "mock-provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     rawIDToken,
+	})
+}
+
+func (p *mockOIDCProvider) user(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer mock-provider-access-token" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"id": ` + mockProviderSubject + `, "login": "octocat", "email": "` + mockEmail + `"}`))
+}
+
+func (p *mockOIDCProvider) emails(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer mock-provider-access-token" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+	writeJSON(w, http.StatusOK, []providerEmail{
+		{Email: mockEmail, Primary: false, Verified: false},
+		{Email: mockProviderEmail, Primary: true, Verified: true},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newFederationTestService(t *testing.T, provider *mockOIDCProvider) (*Federation, *authFixture,
+	*mocks.FederatedIdentityStorage) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	mockEmailVerificationStorage := mocks.NewEmailVerificationStorage(t)
+	mockEmailVerificationStorage.On("IsVerified", mockUserID).Return(true).Maybe()
+	mockEmailVerificationStorage.On("MarkVerified", mockUserID).Return(nil).Maybe()
+	f := newAuthFixture(t, &cfg, AuthOptions{EmailVerificationStorage: mockEmailVerificationStorage})
+	states := make(map[string]*model.FederationState)
+	mockStateStorage := mocks.NewFederationStateStorage(t)
+	mockStateStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.FederationState")).
+		Run(func(args mock.Arguments) { states[args.String(0)] = args.Get(1).(*model.FederationState) }).Return().Maybe()
+	mockStateStorage.On("LoadAndDelete", mock.AnythingOfType("string")).Return(
+		func(stateHash string) *model.FederationState { return states[stateHash] },
+		func(stateHash string) bool {
+			_, ok := states[stateHash]
+			delete(states, stateHash)
+			return ok
+		}).Maybe()
+	mockIdentityStorage := mocks.NewFederatedIdentityStorage(t)
+	federation := NewFederationService(&config.FederationConfig{
+		RedirectURL:     "https://auth.example.com/callback",
+		StateExpiration: 10 * time.Minute,
+		Providers: map[string]*config.FederationProvider{
+			mockProviderID: {ID: mockProviderID, Issuer: provider.server.URL, ClientID: mockProviderClientID,
+				ClientSecret: "mock-client-secret", Scopes: []string{"email"}},
+		}}, f.auth, mockStateStorage, mockIdentityStorage)
+
+	return federation, f, mockIdentityStorage
+}
+
+func TestFederation_CompleteLogin(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	federation, f, mockIdentityStorage := newFederationTestService(t, provider)
+	federatedUsername := mockProviderID + "|" + mockProviderSubject
+
+	t.Log("First login creates and links the user")
+	login, err := federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	assert.Contains(t, login.AuthorizationURL, "scope=openid", "Expected openid scope")
+	code := provider.authorize(t, login.AuthorizationURL)
+	mockIdentityStorage.On("LoadUserID", mockProviderID, mockProviderSubject).Return("", false).Once()
+	f.userServiceClient.On("Create", mock.Anything, mock.MatchedBy(func(request *userService.CreateRequest) bool {
+		return request.Username == federatedUsername && request.Email == mockEmail && request.Password != ""
+	})).Run(func(mock.Arguments) {
+		f.users[federatedUsername] = &userService.GetByUsernameResponse{Uuid: mockUserID, Name: federatedUsername, Email: mockEmail}
+	}).Return(&userService.CreateResponse{Uuid: mockUserID}, nil).Once()
+	mockIdentityStorage.On("Link", mockProviderID, mockProviderSubject, mockUserID).Return().Once()
+	_, accessToken, err := federation.CompleteLogin(context.Background(), login.State, code)
+	assert.NoError(t, err, "Expected no error when completing first login")
+	claims, err := f.auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, federatedUsername, claims.Username, "Federated username mismatch")
+	assert.Equal(t, []string{AuthMethodFederated}, claims.AMR, "Federated amr mismatch")
+	assert.True(t, claims.EmailVerified, "Expected provider verified email to be trusted")
+
+	t.Log("State can't be replayed")
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+	assert.ErrorIs(t, err, ErrInvalidFederationState, "Expected ErrInvalidFederationState for used state")
+
+	t.Log("Next login uses the link")
+	login, err = federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	code = provider.authorize(t, login.AuthorizationURL)
+	mockIdentityStorage.On("LoadUserID", mockProviderID, mockProviderSubject).Return(mockUserID, true).Once()
+	f.userServiceClient.On("GetByID", mock.Anything, &userService.GetByIDRequest{Uuid: mockUserID}).
+		Return(&userService.GetByIDResponse{Uuid: mockUserID, Name: federatedUsername, Email: mockEmail}, nil).Once()
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+	assert.NoError(t, err, "Expected no error when completing linked login")
+
+	t.Log("Lost link is restored from federated username instead of creating the user again")
+	login, err = federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	code = provider.authorize(t, login.AuthorizationURL)
+	mockIdentityStorage.On("LoadUserID", mockProviderID, mockProviderSubject).Return("", false).Once()
+	mockIdentityStorage.On("Link", mockProviderID, mockProviderSubject, mockUserID).Return().Once()
+	_, accessToken, err = federation.CompleteLogin(context.Background(), login.State, code)
+	assert.NoError(t, err, "Expected no error when completing login with lost link")
+	claims, err = f.auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, federatedUsername, claims.Username, "Federated username mismatch")
+}
+
+func TestFederation_CompleteLogin_GitHub(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	federation, f, mockIdentityStorage := newFederationTestService(t, provider)
+	federation.cfg.Providers[mockProviderID] = &config.FederationProvider{ID: mockProviderID,
+		Type: config.FederationProviderGitHub, ClientID: mockProviderClientID, ClientSecret: "mock-client-secret",
+		AuthURL: provider.server.URL + "/authorize", TokenURL: provider.server.URL + "/token",
+		UserInfoURL: provider.server.URL + "/user", EmailsURL: provider.server.URL + "/user/emails",
+		SubjectClaim: "id", EmailClaim: "email", Scopes: []string{"read:user", "user:email"}}
+	federatedUsername := mockProviderID + "|" + mockProviderSubject
+
+	login, err := federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	assert.NotContains(t, login.AuthorizationURL, "openid", "Expected no openid scope for OAuth 2.0 provider")
+	assert.NotContains(t, login.AuthorizationURL, "nonce=", "Expected no nonce for OAuth 2.0 provider")
+	code := provider.authorize(t, login.AuthorizationURL)
+	mockIdentityStorage.On("LoadUserID", mockProviderID, mockProviderSubject).Return("", false).Once()
+	f.userServiceClient.On("Create", mock.Anything, mock.MatchedBy(func(request *userService.CreateRequest) bool {
+		return request.Username == federatedUsername && request.Email == mockProviderEmail
+	})).Run(func(mock.Arguments) {
+		f.users[federatedUsername] = &userService.GetByUsernameResponse{Uuid: mockUserID, Name: federatedUsername}
+	}).Return(&userService.CreateResponse{Uuid: mockUserID}, nil).Once()
+	mockIdentityStorage.On("Link", mockProviderID, mockProviderSubject, mockUserID).Return().Once()
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+
+	assert.NoError(t, err, "Expected user created from numeric id and primary verified email")
+}
+
+func TestFederation_CompleteLogin_OAuth2(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	federation, f, mockIdentityStorage := newFederationTestService(t, provider)
+	federation.cfg.Providers[mockProviderID] = &config.FederationProvider{ID: mockProviderID,
+		Type: config.FederationProviderOAuth2, ClientID: mockProviderClientID, ClientSecret: "mock-client-secret",
+		AuthURL: provider.server.URL + "/authorize", TokenURL: provider.server.URL + "/token",
+		UserInfoURL: provider.server.URL + "/user", SubjectClaim: "login", EmailClaim: "email"}
+	federatedUsername := mockProviderID + "|octocat"
+
+	login, err := federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	code := provider.authorize(t, login.AuthorizationURL)
+	mockIdentityStorage.On("LoadUserID", mockProviderID, "octocat").Return("", false).Once()
+	f.userServiceClient.On("Create", mock.Anything, mock.MatchedBy(func(request *userService.CreateRequest) bool {
+		return request.Username == federatedUsername && request.Email == mockEmail
+	})).Run(func(mock.Arguments) {
+		f.users[federatedUsername] = &userService.GetByUsernameResponse{Uuid: mockUserID, Name: federatedUsername}
+	}).Return(&userService.CreateResponse{Uuid: mockUserID}, nil).Once()
+	mockIdentityStorage.On("Link", mockProviderID, "octocat", mockUserID).Return().Once()
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+	assert.NoError(t, err, "Expected user created from configured user info claims")
+
+	t.Log("Provider rejecting access token fails login")
+	federation.cfg.Providers[mockProviderID].UserInfoURL = provider.server.URL + "/missing"
+	login, err = federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	code = provider.authorize(t, login.AuthorizationURL)
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+	assert.ErrorIs(t, err, ErrInvalidUserInfo, "Expected ErrInvalidUserInfo when user info can't be read")
+}
+
+func TestFederation_CompleteLogin_InvalidIDToken(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	federation, _, _ := newFederationTestService(t, provider)
+	forgedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err, "Expected no error when generating forged key")
+	provider.setSigningKey(forgedKey)
+
+	login, err := federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	code := provider.authorize(t, login.AuthorizationURL)
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "Expected ErrInvalidIDToken for token not signed by provider keys")
+}
+
+func TestFederation_CompleteLogin_PKCE(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	federation, _, _ := newFederationTestService(t, provider)
+	login, err := federation.BeginLogin(context.Background(), mockProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	code := provider.authorize(t, login.AuthorizationURL)
+	provider.setCodeChallenge(code, codeChallenge("intercepted"))
+
+	_, _, err = federation.CompleteLogin(context.Background(), login.State, code)
+
+	assert.ErrorIs(t, err, ErrCodeExchangeFailed, "Expected ErrCodeExchangeFailed when code verifier doesn't match")
+	_, err = federation.BeginLogin(context.Background(), "unknown", "")
+	assert.ErrorIs(t, err, ErrUnknownProvider, "Expected ErrUnknownProvider")
+}
+
+func TestFederation_BeginLogin_SlowDiscovery(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	federation, _, _ := newFederationTestService(t, provider)
+	discovering, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(discovering)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(slow.Close)
+	federation.cfg.Providers["slow"] = &config.FederationProvider{ID: "slow", Issuer: slow.URL, ClientID: mockProviderClientID}
+	slowLogin := make(chan error)
+	go func() {
+		_, err := federation.BeginLogin(context.Background(), "slow", "")
+		slowLogin <- err
+	}()
+	<-discovering
+
+	t.Log("login with other provider doesn't wait for slow discovery")
+	done := make(chan error)
+	go func() {
+		_, err := federation.BeginLogin(context.Background(), mockProviderID, "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err, "Expected no error when beginning login")
+	case <-time.After(5 * time.Second):
+		t.Error("Expected login to complete while other provider is being discovered")
+	}
+	close(release)
+	assert.Error(t, <-slowLogin, "Expected failed discovery to fail login")
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/go-jose/go-jose/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrEncryptionKeyNotConfigured godoc
+	ErrEncryptionKeyNotConfigured = errors.New("encryption key is not configured")
+	// ErrUnexpectedTokenEncryption godoc
+	ErrUnexpectedTokenEncryption = errors.New("unexpected token encryption")
+)
+
+// jweContentType content type of the nested signed token, see RFC 7519 section 5.2
+const jweContentType = "JWT"
+
+// isEncryptedToken reports whether token is a compact JWE, it has five parts unlike a three part JWS
+func isEncryptedToken(token string) bool {
+	return strings.Count(token, ".") == 4
+}
+
+// encryptAccessToken nests signed access token into ECDH-ES+A256KW/A256GCM JWE for the key of its audience
+func (a *Auth) encryptAccessToken(signedToken, audience string) (string, error) {
+	key, ok := a.cfg.EncryptionKeys[audience]
+	if !ok {
+		return "", ErrEncryptionKeyNotConfigured
+	}
+	encrypter, err := jose.NewEncrypter(jose.A256GCM,
+		jose.Recipient{Algorithm: jose.ECDH_ES_A256KW, Key: &key.PrivateKey.PublicKey, KeyID: key.KeyID},
+		(&jose.EncrypterOptions{}).WithType(jweContentType).WithContentType(jweContentType))
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := encrypter.Encrypt([]byte(signedToken))
+	if err != nil {
+		log.Errorf("auth/ encryptAccessToken/ error in Encrypt for audience %s: %v", audience, err)
+		return "", err
+	}
+
+	return encrypted.CompactSerialize()
+}
+
+// parseEncryptedToken decrypts JWE with the key named by its kid and verifies the nested JWT.
+// Nested token must be issued for the audience of the decrypting key.
+func (a *Auth) parseEncryptedToken(tenant *config.Tenant, accessToken string) (*Claim, error) {
+	encrypted, err := jose.ParseEncrypted(accessToken)
+	if err != nil {
+		log.Errorf("invalid token: %v", err)
+		return nil, ErrUnexpectedTokenEncryption
+	}
+	header := encrypted.Header
+	if header.Algorithm != string(jose.ECDH_ES_A256KW) ||
+		header.ExtraHeaders[jose.HeaderKey("enc")] != string(jose.A256GCM) ||
+		header.ExtraHeaders[jose.HeaderContentType] != jweContentType {
+		return nil, ErrUnexpectedTokenEncryption
+	}
+	key := a.encryptionKey(header.KeyID)
+	if key == nil {
+		return nil, ErrUnknownTokenKey
+	}
+	signedToken, err := encrypted.Decrypt(key.PrivateKey)
+	if err != nil {
+		log.Errorf("invalid token: %v", err)
+		return nil, err
+	}
+	claims, err := a.parseJWT(tenant, string(signedToken))
+	if err != nil {
+		return nil, err
+	}
+	if claims.Audience != key.Audience {
+		return nil, ErrInvalidTokenAudience
+	}
+
+	return claims, nil
+}
+
+// encryptionKey returns encryption key of given id, nil if there is none
+func (a *Auth) encryptionKey(keyID string) *config.EncryptionKey {
+	for _, key := range a.cfg.EncryptionKeys {
+		if key.KeyID == keyID {
+			return key
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newEncryptionKey(t *testing.T, audience, keyID string) *config.EncryptionKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err, "Expected no error when generating encryption key")
+	return &config.EncryptionKey{Audience: audience, KeyID: keyID, PrivateKey: privateKey}
+}
+
+func TestAuth_GenerateTokens_JWE(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Issuer:                 mockIssuer,
+		Audience:               mockTokenAudience,
+		EncryptionKeys: map[string]*config.EncryptionKey{
+			mockTokenAudience: newEncryptionKey(t, mockTokenAudience, "api-1"),
+			"billing":         newEncryptionKey(t, "billing", "billing-1"),
+		},
+		Clients: map[string]*config.ClientProfile{
+			"confidential": {ID: "confidential", AccessTokenFormat: config.AccessTokenFormatJWE},
+		}}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadRole", "", "viewer").Return(&model.Role{Name: "viewer"}, true)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	ctx := WithClient(context.Background(), "confidential")
+
+	_, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	assert.Equal(t, 4, strings.Count(accessToken, "."), "Expected compact JWE access token")
+	encrypted, err := jose.ParseEncrypted(accessToken)
+	assert.NoError(t, err, "Expected parsable JWE")
+	assert.Equal(t, string(jose.ECDH_ES_A256KW), encrypted.Header.Algorithm, "Key management algorithm mismatch")
+	assert.Equal(t, "api-1", encrypted.Header.KeyID, "Expected key of token audience")
+
+	t.Log("ValidateToken decrypts the token transparently")
+	claims, err := auth.ValidateToken(ctx, accessToken, ValidateOptions{Audience: mockTokenAudience})
+	assert.NoError(t, err, "Expected no error when validating encrypted token")
+	assert.Equal(t, mockUserID, claims.Subject, "Subject mismatch")
+	assert.Equal(t, "confidential", claims.ClientID, "Client mismatch")
+	assert.Equal(t, []string{"viewer"}, claims.Roles, "Roles mismatch")
+
+	t.Log("nested token must be issued for the audience of the decrypting key")
+	signed, err := auth.generateAccessToken(&model.Session{UserID: mockUserID, Username: mockUsername},
+		mockAccessTokenKey, time.Now().Add(time.Minute).Unix())
+	assert.NoError(t, err, "Expected no error when signing token")
+	misdirected, err := auth.encryptAccessToken(signed, "billing")
+	assert.NoError(t, err, "Expected no error when encrypting token")
+	_, err = auth.ValidateToken(ctx, misdirected, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidTokenAudience, "Expected ErrInvalidTokenAudience for misdirected token")
+
+	t.Log("token of unknown key is rejected")
+	unknown := newEncryptionKey(t, mockTokenAudience, "retired")
+	encrypter, err := jose.NewEncrypter(jose.A256GCM,
+		jose.Recipient{Algorithm: jose.ECDH_ES_A256KW, Key: &unknown.PrivateKey.PublicKey, KeyID: unknown.KeyID},
+		(&jose.EncrypterOptions{}).WithContentType(jweContentType))
+	assert.NoError(t, err, "Expected no error when creating encrypter")
+	object, err := encrypter.Encrypt([]byte(signed))
+	assert.NoError(t, err, "Expected no error when encrypting token")
+	forged, err := object.CompactSerialize()
+	assert.NoError(t, err, "Expected no error when serializing token")
+	_, err = auth.ValidateToken(ctx, forged, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrUnknownTokenKey, "Expected ErrUnknownTokenKey for unknown key id")
+
+	t.Log("other clients keep getting JWTs")
+	_, accessToken, err = auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	assert.Equal(t, 2, strings.Count(accessToken, "."), "Expected JWT access token")
+}
+
+func TestAuth_GenerateTokens_JWEKeyNotConfigured(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Audience:               mockTokenAudience,
+		AccessTokenFormat:      config.AccessTokenFormatJWE}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		RoleStorage: mockRoleStorage,
+	})
+
+	_, _, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.ErrorIs(t, err, ErrEncryptionKeyNotConfigured, "Expected ErrEncryptionKeyNotConfigured without audience key")
+}
@@ -12,18 +12,18 @@ type SessionStorage struct {
 	mock.Mock
 }
 
-// Delete provides a mock function with given fields: refreshToken
-func (_m *SessionStorage) Delete(refreshToken string) {
-	_m.Called(refreshToken)
+// Delete provides a mock function with given fields: tenantID, username
+func (_m *SessionStorage) Delete(tenantID string, username string) {
+	_m.Called(tenantID, username)
 }
 
-// Load provides a mock function with given fields: username
-func (_m *SessionStorage) Load(username string) (*model.Session, bool) {
-	ret := _m.Called(username)
+// Load provides a mock function with given fields: tenantID, username
+func (_m *SessionStorage) Load(tenantID string, username string) (*model.Session, bool) {
+	ret := _m.Called(tenantID, username)
 
 	var r0 *model.Session
-	if rf, ok := ret.Get(0).(func(string) *model.Session); ok {
-		r0 = rf(username)
+	if rf, ok := ret.Get(0).(func(string, string) *model.Session); ok {
+		r0 = rf(tenantID, username)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Session)
@@ -31,8 +31,8 @@ func (_m *SessionStorage) Load(username string) (*model.Session, bool) {
 	}
 
 	var r1 bool
-	if rf, ok := ret.Get(1).(func(string) bool); ok {
-		r1 = rf(username)
+	if rf, ok := ret.Get(1).(func(string, string) bool); ok {
+		r1 = rf(tenantID, username)
 	} else {
 		r1 = ret.Get(1).(bool)
 	}
@@ -40,13 +40,13 @@ func (_m *SessionStorage) Load(username string) (*model.Session, bool) {
 	return r0, r1
 }
 
-// LoadAndDelete provides a mock function with given fields: refreshToken
-func (_m *SessionStorage) LoadAndDelete(refreshToken string) (*model.Session, bool) {
-	ret := _m.Called(refreshToken)
+// LoadAndDelete provides a mock function with given fields: tenantID, username
+func (_m *SessionStorage) LoadAndDelete(tenantID string, username string) (*model.Session, bool) {
+	ret := _m.Called(tenantID, username)
 
 	var r0 *model.Session
-	if rf, ok := ret.Get(0).(func(string) *model.Session); ok {
-		r0 = rf(refreshToken)
+	if rf, ok := ret.Get(0).(func(string, string) *model.Session); ok {
+		r0 = rf(tenantID, username)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Session)
@@ -54,8 +54,8 @@ func (_m *SessionStorage) LoadAndDelete(refreshToken string) (*model.Session, bo
 	}
 
 	var r1 bool
-	if rf, ok := ret.Get(1).(func(string) bool); ok {
-		r1 = rf(refreshToken)
+	if rf, ok := ret.Get(1).(func(string, string) bool); ok {
+		r1 = rf(tenantID, username)
 	} else {
 		r1 = ret.Get(1).(bool)
 	}
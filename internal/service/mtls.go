@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrCertificateMismatch godoc
+var ErrCertificateMismatch = errors.New("token is bound to another client certificate")
+
+type certificateKey struct{}
+
+// WithClientCertificate returns context carrying thumbprint of verified mutual TLS client certificate.
+// Tokens issued with it are bound to the certificate, see RFC 8705.
+func WithClientCertificate(ctx context.Context, certificate *x509.Certificate) context.Context {
+	return context.WithValue(ctx, certificateKey{}, CertificateThumbprint(certificate))
+}
+
+// CertificateThumbprint returns base64url encoded SHA-256 hash of DER encoded certificate, the x5t#S256 value
+func CertificateThumbprint(certificate *x509.Certificate) string {
+	hash := sha256.Sum256(certificate.Raw)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func certificateFromContext(ctx context.Context) string {
+	x5t, _ := ctx.Value(certificateKey{}).(string)
+	return x5t
+}
+
+// verifyCertificateBinding checks token bound to certificate is used over connection with that certificate
+func verifyCertificateBinding(ctx context.Context, claims *Claim) error {
+	if claims.Cnf == nil || claims.Cnf.X5T == "" {
+		return nil
+	}
+	if claims.Cnf.X5T != certificateFromContext(ctx) {
+		return ErrCertificateMismatch
+	}
+	return nil
+}
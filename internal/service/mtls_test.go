@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuth_CertificateBoundTokens(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	var session *model.Session
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+	})
+	certificate := &x509.Certificate{Raw: []byte("client certificate")}
+	hash := sha256.Sum256(certificate.Raw)
+	ctx := WithClientCertificate(context.Background(), certificate)
+
+	refreshToken, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	claims, err := auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating token over the same connection certificate")
+	assert.Equal(t, &Confirmation{X5T: base64.RawURLEncoding.EncodeToString(hash[:])}, claims.Cnf,
+		"Expected access token bound to client certificate")
+
+	t.Log("bound token is rejected without certificate or with other certificate")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrCertificateMismatch, "Expected ErrCertificateMismatch without certificate")
+	otherCtx := WithClientCertificate(context.Background(), &x509.Certificate{Raw: []byte("other certificate")})
+	_, err = auth.ValidateToken(otherCtx, accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrCertificateMismatch, "Expected ErrCertificateMismatch with other certificate")
+
+	t.Log("bound session is refreshed with the same certificate only")
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(session, true).Once()
+	_, _, err = auth.RefreshTokens(otherCtx, refreshToken, mockUsername)
+	assert.ErrorIs(t, err, ErrCertificateMismatch, "Expected ErrCertificateMismatch for other certificate")
+	mockSessionStorage.On("LoadAndDelete", "", mockUsername).Return(session, true).Once()
+	_, accessToken, err = auth.RefreshTokens(ctx, refreshToken, mockUsername)
+	assert.NoError(t, err, "Expected no error when refreshing with the same certificate")
+	_, err = auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating refreshed token")
+
+	t.Log("tokens issued without certificate stay bearer tokens")
+	_, accessToken, err = auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	claims, err = auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating bearer token")
+	assert.Nil(t, claims.Cnf, "Expected unbound access token")
+}
+
+func TestAuth_SignIn_CertificateBound(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	var session *model.Session
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).Return()
+	mockVerifier := mocks.NewCredentialVerifier(t)
+	mockVerifier.On("VerifyCredentials", mock.Anything, mockUsername, mockPassword).
+		Return(&model.Identity{UserID: mockUserID, Username: mockUsername}, nil)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, nil, AuthOptions{
+		TokenDenylist:      mockTokenDenylist,
+		RoleStorage:        mockRoleStorage,
+		CredentialVerifier: mockVerifier,
+	})
+	certificate := &x509.Certificate{Raw: []byte("client certificate")}
+	hash := sha256.Sum256(certificate.Raw)
+	x5t := base64.RawURLEncoding.EncodeToString(hash[:])
+	ctx := WithClientCertificate(context.Background(), certificate)
+
+	t.Log("password sign in over mutual TLS issues tokens bound to the client certificate")
+	_, accessToken, err := auth.SignIn(ctx, mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+	claims, err := auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating token over the same connection certificate")
+	assert.Equal(t, &Confirmation{X5T: x5t}, claims.Cnf, "Expected access token bound to client certificate")
+	assert.Equal(t, x5t, session.X5T, "Expected session bound to client certificate")
+	_, err = auth.ValidateToken(context.Background(), accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrCertificateMismatch, "Expected ErrCertificateMismatch without certificate")
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/paseto"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrPasetoKeyNotConfigured godoc
+	ErrPasetoKeyNotConfigured = errors.New("paseto key is not configured")
+	// ErrUnknownTokenKey godoc
+	ErrUnknownTokenKey = errors.New("unknown token key")
+)
+
+// pasetoTimeClaims registered claims PASETO carries as RFC 3339 date times rather than unix seconds
+var pasetoTimeClaims = []string{"exp", "iat", "nbf"}
+
+// pasetoFooter footer of issued tokens, kid is the PASERK id of the key
+type pasetoFooter struct {
+	KID string `json:"kid"`
+}
+
+func isPasetoToken(token string) bool {
+	return strings.HasPrefix(token, paseto.HeaderLocal) || strings.HasPrefix(token, paseto.HeaderPublic)
+}
+
+// signPasetoToken issues v4.local or v4.public token with tenant keys,
+// tenant id is the implicit assertion binding token to tenant
+func signPasetoToken(tenant *config.Tenant, claims *Claim, format string) (string, error) {
+	message, err := marshalPasetoClaims(claims)
+	if err != nil {
+		return "", err
+	}
+	implicit := []byte(claims.TenantID)
+	switch format {
+	case config.AccessTokenFormatPasetoLocal:
+		if tenant.PasetoLocalKey == nil {
+			return "", ErrPasetoKeyNotConfigured
+		}
+		footer, err := json.Marshal(pasetoFooter{KID: paseto.LocalKeyID(tenant.PasetoLocalKey)})
+		if err != nil {
+			return "", err
+		}
+		return paseto.Encrypt(tenant.PasetoLocalKey, message, footer, implicit)
+	case config.AccessTokenFormatPasetoPublic:
+		if tenant.PasetoSecretKey == nil {
+			return "", ErrPasetoKeyNotConfigured
+		}
+		publicKey := tenant.PasetoSecretKey.Public().(ed25519.PublicKey)
+		footer, err := json.Marshal(pasetoFooter{KID: paseto.PublicKeyID(publicKey)})
+		if err != nil {
+			return "", err
+		}
+		return paseto.Sign(tenant.PasetoSecretKey, message, footer, implicit)
+	default:
+		return "", ErrUnexpectedTokenSigningMethod
+	}
+}
+
+// parsePasetoToken verifies v4.local or v4.public token of tenant, footer kid must name the tenant key
+func parsePasetoToken(tenant *config.Tenant, token string) (*Claim, error) {
+	data, err := paseto.Footer(token)
+	if err != nil {
+		return nil, err
+	}
+	footer := pasetoFooter{}
+	if err = json.Unmarshal(data, &footer); err != nil {
+		return nil, ErrUnknownTokenKey
+	}
+	implicit := []byte(tenant.ID)
+	var message []byte
+	if strings.HasPrefix(token, paseto.HeaderLocal) {
+		if tenant.PasetoLocalKey == nil || footer.KID != paseto.LocalKeyID(tenant.PasetoLocalKey) {
+			return nil, ErrUnknownTokenKey
+		}
+		message, _, err = paseto.Decrypt(tenant.PasetoLocalKey, token, implicit)
+	} else {
+		if tenant.PasetoSecretKey == nil {
+			return nil, ErrUnknownTokenKey
+		}
+		publicKey := tenant.PasetoSecretKey.Public().(ed25519.PublicKey)
+		if footer.KID != paseto.PublicKeyID(publicKey) {
+			return nil, ErrUnknownTokenKey
+		}
+		message, _, err = paseto.Verify(publicKey, token, implicit)
+	}
+	if err != nil {
+		log.Errorf("invalid token: %v", err)
+		return nil, err
+	}
+
+	return unmarshalPasetoClaims(message)
+}
+
+// marshalPasetoClaims encodes claims like JWT claims except for registered time claims
+func marshalPasetoClaims(claims *Claim) ([]byte, error) {
+	fields, err := claimFields(claims)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range pasetoTimeClaims {
+		if value, ok := fields[name].(json.Number); ok {
+			seconds, err := value.Int64()
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return json.Marshal(fields)
+}
+
+func unmarshalPasetoClaims(message []byte) (*Claim, error) {
+	fields := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, ErrInvalidTokenClaims
+	}
+	for _, name := range pasetoTimeClaims {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		text, _ := value.(string)
+		parsed, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, ErrInvalidTokenClaims
+		}
+		fields[name] = parsed.Unix()
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claim{}
+	if err = json.Unmarshal(data, claims); err != nil || claims.Id == "" {
+		return nil, ErrInvalidTokenClaims
+	}
+	return claims, nil
+}
+
+// claimFields returns claims as JSON object fields, numbers are kept exact
+func claimFields(claims *Claim) (map[string]interface{}, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/paseto"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPasetoTestService(t *testing.T) *Auth {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Issuer:                 mockIssuer,
+		DefaultTenant:          "default",
+		Tenants:                map[string]*config.Tenant{"acme": {ID: "acme"}},
+		PasetoLocalKey:         []byte("0123456789abcdef0123456789abcdef"),
+		PasetoSecretKey:        ed25519.NewKeyFromSeed([]byte("fedcba9876543210fedcba9876543210")),
+		Clients: map[string]*config.ClientProfile{
+			"local":  {ID: "local", AccessTokenFormat: config.AccessTokenFormatPasetoLocal},
+			"public": {ID: "public", AccessTokenFormat: config.AccessTokenFormatPasetoPublic},
+		}}
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", mock.AnythingOfType("string"), mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadRole", mock.AnythingOfType("string"), "viewer").
+		Return(&model.Role{Name: "viewer", Permissions: []string{"documents:read"}}, true)
+
+	return newAuthFixture(t, &cfg, AuthOptions{RoleStorage: mockRoleStorage}).auth
+}
+
+func TestAuth_GenerateTokens_Paseto(t *testing.T) {
+	auth := newPasetoTestService(t)
+	for _, format := range []string{config.AccessTokenFormatPasetoLocal, config.AccessTokenFormatPasetoPublic} {
+		client := strings.TrimPrefix(format, "v4.")
+		ctx := WithClient(WithTenant(context.Background(), "acme"), client)
+
+		_, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+		assert.NoError(t, err, "Expected no error when generating %s tokens", format)
+		assert.True(t, strings.HasPrefix(accessToken, format+"."), "Expected %s access token", format)
+
+		claims, err := auth.ValidateToken(ctx, accessToken, ValidateOptions{Issuer: mockIssuer})
+		assert.NoError(t, err, "Expected no error when validating %s token", format)
+		assert.Equal(t, mockUserID, claims.Subject, "Subject mismatch")
+		assert.Equal(t, "acme", claims.TenantID, "Tenant mismatch")
+		assert.Equal(t, client, claims.ClientID, "Client mismatch")
+		assert.Equal(t, []string{"documents:read"}, claims.Permissions, "Permissions mismatch")
+		assert.InDelta(t, time.Now().Add(30*time.Minute).Unix(), claims.ExpiresAt, 1, "Expiration mismatch")
+
+		_, err = auth.ValidateToken(WithTenant(context.Background(), "default"), accessToken, ValidateOptions{})
+		assert.ErrorIs(t, err, paseto.ErrInvalidToken, "Expected tenant implicit assertion to reject %s token", format)
+	}
+}
+
+func TestAuth_ValidateToken_PasetoPublicClaims(t *testing.T) {
+	auth := newPasetoTestService(t)
+	ctx := WithClient(context.Background(), "public")
+	_, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	parts := strings.Split(strings.TrimPrefix(accessToken, paseto.HeaderPublic), ".")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	assert.NoError(t, err, "Expected base64url payload")
+	fields := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(payload[:len(payload)-ed25519.SignatureSize], &fields), "Expected JSON claims")
+	_, err = time.Parse(time.RFC3339, fields["exp"].(string))
+	assert.NoError(t, err, "Expected exp as RFC 3339 date time")
+	footer, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.NoError(t, err, "Expected base64url footer")
+	assert.Contains(t, string(footer), `"kid":"k4.pid.`, "Expected PASERK key id in footer")
+
+	t.Log("token of unknown key is rejected before verification")
+	otherKey := ed25519.NewKeyFromSeed([]byte("00000000000000000000000000000000"))
+	forged, err := paseto.Sign(otherKey, payload[:len(payload)-ed25519.SignatureSize], footer, nil)
+	assert.NoError(t, err, "Expected no error when signing forged token")
+	_, err = auth.ValidateToken(ctx, forged, ValidateOptions{})
+	assert.ErrorIs(t, err, paseto.ErrInvalidToken, "Expected forged token to be rejected")
+	forged, err = paseto.Sign(otherKey, payload[:len(payload)-ed25519.SignatureSize],
+		[]byte(`{"kid":"`+paseto.PublicKeyID(otherKey.Public().(ed25519.PublicKey))+`"}`), nil)
+	assert.NoError(t, err, "Expected no error when signing forged token")
+	_, err = auth.ValidateToken(ctx, forged, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrUnknownTokenKey, "Expected ErrUnknownTokenKey for other key id")
+}
+
+func TestAuth_GenerateTokens_PasetoTenantKeys(t *testing.T) {
+	auth := newPasetoTestService(t)
+	acme := auth.cfg.Tenants["acme"]
+	acme.PasetoLocalKey = []byte("abcdef0123456789abcdef0123456789")
+	acme.PasetoSecretKey = ed25519.NewKeyFromSeed([]byte("9876543210fedcba9876543210fedcba"))
+	globalKIDs := map[string]string{
+		config.AccessTokenFormatPasetoLocal:  paseto.LocalKeyID(auth.cfg.PasetoLocalKey),
+		config.AccessTokenFormatPasetoPublic: paseto.PublicKeyID(auth.cfg.PasetoSecretKey.Public().(ed25519.PublicKey)),
+	}
+	for _, format := range []string{config.AccessTokenFormatPasetoLocal, config.AccessTokenFormatPasetoPublic} {
+		client := strings.TrimPrefix(format, "v4.")
+
+		t.Log("tenant with own key signs with it")
+		ctx := WithClient(WithTenant(context.Background(), "acme"), client)
+		_, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+		assert.NoError(t, err, "Expected no error when generating %s tokens of acme", format)
+		footer, err := paseto.Footer(accessToken)
+		assert.NoError(t, err, "Expected footer of %s token", format)
+		assert.NotContains(t, string(footer), globalKIDs[format], "Expected acme %s token not to use global key", format)
+		_, err = auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+		assert.NoError(t, err, "Expected no error when validating %s token of acme", format)
+
+		t.Log("tenant without own key falls back to global key")
+		ctx = WithClient(WithTenant(context.Background(), "default"), client)
+		_, accessToken, err = auth.GenerateTokens(ctx, mockUsername, "")
+		assert.NoError(t, err, "Expected no error when generating %s tokens of default tenant", format)
+		footer, err = paseto.Footer(accessToken)
+		assert.NoError(t, err, "Expected footer of %s token", format)
+		assert.Contains(t, string(footer), globalKIDs[format], "Expected default %s token to use global key", format)
+		_, err = auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+		assert.NoError(t, err, "Expected no error when validating %s token of default tenant", format)
+	}
+}
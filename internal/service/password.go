@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrPasswordTooShort godoc
+	ErrPasswordTooShort = errors.New("password is too short")
+	// ErrPasswordTooLong godoc
+	ErrPasswordTooLong = errors.New("password is too long")
+	// ErrPasswordTooWeak godoc
+	ErrPasswordTooWeak = errors.New("password does not meet complexity requirements")
+	// ErrPasswordNotChanged godoc
+	ErrPasswordNotChanged = errors.New("new password must differ from the current one")
+	// ErrTooManyPasswordAttempts godoc
+	ErrTooManyPasswordAttempts = errors.New("too many password change attempts")
+)
+
+// Password password change service struct. userService has no password update RPC yet,
+// so ChangePassword is not exposed over gRPC until PasswordStore can be implemented.
+type Password struct {
+	policy        *config.PasswordPolicyConfig
+	auth          *Auth
+	passwordStore PasswordStore
+	attempts      AttemptCounter
+}
+
+// NewPasswordService creates new Password service
+func NewPasswordService(policy *config.PasswordPolicyConfig, auth *Auth, passwordStore PasswordStore,
+	attempts AttemptCounter) *Password {
+	return &Password{policy: policy, auth: auth, passwordStore: passwordStore, attempts: attempts}
+}
+
+// ChangePassword sets new password for access token owner after re-verifying the old one.
+// Every old password check takes an attempt up front, after MaxChangeAttempts of them the user
+// can't change password until ChangeAttemptWindow passes, a correct old password resets the count.
+// All other user sessions are ended, current session is kept and gets a fresh token pair
+// issued after the revocation, so the revocation never covers it.
+func (p *Password) ChangePassword(ctx context.Context, accessToken, oldPassword, newPassword string) (refreshToken, newAccessToken string, err error) {
+	claims, err := p.auth.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return "", "", err
+	}
+	session, ok := p.auth.sessionStorage.Load(claims.TenantID, claims.Username)
+	if !ok || claims.SessionID == "" || session.ID != claims.SessionID {
+		return "", "", ErrSessionNotFound
+	}
+	attemptsKey := model.TenantKey(session.TenantID, session.UserID)
+	if p.attempts.Add(attemptsKey, p.policy.ChangeAttemptWindow) > p.policy.MaxChangeAttempts {
+		return "", "", ErrTooManyPasswordAttempts
+	}
+	identity, err := p.auth.verifyPassword(ctx, claims.Username, oldPassword)
+	if err != nil {
+		return "", "", err
+	}
+	p.attempts.Reset(attemptsKey)
+	if identity.Realm != "" {
+		return "", "", ErrExternalCredentials
+	}
+	if newPassword == oldPassword {
+		return "", "", ErrPasswordNotChanged
+	}
+	if err = validatePassword(p.policy, newPassword); err != nil {
+		return "", "", err
+	}
+	tenant, err := p.auth.tenantByID(session.TenantID)
+	if err != nil {
+		return "", "", err
+	}
+	client, ok := p.auth.cfg.Client(tenant, session.ClientID)
+	if !ok {
+		return "", "", ErrInvalidClient
+	}
+
+	if err = p.passwordStore.UpdatePassword(ctx, identity.UserID, newPassword); err != nil {
+		log.Errorf("Password / ChangePassword / UpdatePassword err %v ", err)
+		return "", "", err
+	}
+	p.auth.revokeUserSessions(identity.UserID, claims.Username)
+	refreshToken, newAccessToken, err = p.auth.generateTokens(session, client)
+	if err != nil {
+		return "", "", err
+	}
+	p.auth.auditLogger.Emit(&model.AuditEvent{
+		Type:      model.AuditEventPasswordChange,
+		TenantID:  claims.TenantID,
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		Time:      time.Now().Unix(),
+	})
+
+	return refreshToken, newAccessToken, nil
+}
+
+// validatePassword checks password against password policy, length is counted in bytes as bcrypt does
+func validatePassword(policy *config.PasswordPolicyConfig, password string) error {
+	if len(password) < policy.MinLength {
+		return ErrPasswordTooShort
+	}
+	if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+		return ErrPasswordTooLong
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if policy.RequireMixedCase && !(upper && lower) || policy.RequireDigit && !digit || policy.RequireSymbol && !symbol {
+		return ErrPasswordTooWeak
+	}
+	return nil
+}
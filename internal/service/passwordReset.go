@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/userService/protocol/userService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrInvalidResetToken godoc
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	// ErrTooManyResetRequests godoc
+	ErrTooManyResetRequests = errors.New("too many password reset requests")
+)
+
+// PasswordResetStorage used to store pending password resets by reset token hash
+type PasswordResetStorage interface {
+	Save(tokenHash string, reset *model.PasswordReset)
+	LoadAndDelete(tokenHash string) (*model.PasswordReset, bool)
+}
+
+// Mailer used to deliver messages to users
+type Mailer interface {
+	Send(ctx context.Context, message *model.Message) error
+}
+
+// PasswordStore used to update user passwords. userService has no password update RPC yet,
+// so there is no implementation and password reset is not exposed over gRPC.
+type PasswordStore interface {
+	UpdatePassword(ctx context.Context, userID, password string) error
+}
+
+// PasswordReset password reset service struct
+type PasswordReset struct {
+	cfg           *config.PasswordResetConfig
+	policy        *config.PasswordPolicyConfig
+	auth          *Auth
+	resetStorage  PasswordResetStorage
+	mailer        Mailer
+	passwordStore PasswordStore
+	requests      AttemptCounter
+}
+
+// NewPasswordResetService creates new PasswordReset service
+func NewPasswordResetService(cfg *config.PasswordResetConfig, policy *config.PasswordPolicyConfig, auth *Auth,
+	resetStorage PasswordResetStorage, mailer Mailer, passwordStore PasswordStore, requests AttemptCounter) *PasswordReset {
+	return &PasswordReset{cfg: cfg, policy: policy, auth: auth, resetStorage: resetStorage, mailer: mailer,
+		passwordStore: passwordStore, requests: requests}
+}
+
+// RequestPasswordReset mails single-use reset token to user email, only token hash is stored.
+// Unknown usernames are not reported so the call can't be used to enumerate users.
+// Each username gets at most MaxRequests emails within RequestWindow, known or not.
+func (p *PasswordReset) RequestPasswordReset(ctx context.Context, username string) error {
+	if p.requests.Add(model.TenantKey(TenantFromContext(ctx), username), p.cfg.RequestWindow) > p.cfg.MaxRequests {
+		return ErrTooManyResetRequests
+	}
+	user, err := p.auth.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: username,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	} else if err != nil {
+		log.Errorf("PasswordReset / RequestPasswordReset /GetByUsername err %v ", err)
+		return err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	p.resetStorage.Save(hashToken(token), &model.PasswordReset{
+		UserID:    user.Uuid,
+		Username:  username,
+		ExpiresAt: time.Now().Add(p.cfg.TokenExpiration).Unix(),
+	})
+	body := fmt.Sprintf("Use this code to reset your password: %s\n", token)
+	if p.cfg.URL != "" {
+		body = fmt.Sprintf("Follow the link to reset your password: %s?token=%s\n", p.cfg.URL, token)
+	}
+	body += fmt.Sprintf("It expires in %s. If you did not ask to reset your password, ignore this email.\n",
+		p.cfg.TokenExpiration)
+
+	return p.mailer.Send(ctx, &model.Message{To: user.Email, Subject: "Password reset", Body: body})
+}
+
+// ConfirmPasswordReset sets new password for reset token owner and ends all user sessions.
+// Password policy is checked first so a rejected password doesn't consume the token,
+// any later failure does and the user has to request a new one.
+func (p *PasswordReset) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	if err := validatePassword(p.policy, newPassword); err != nil {
+		return err
+	}
+	reset, ok := p.resetStorage.LoadAndDelete(hashToken(token))
+	if !ok || reset.ExpiresAt <= time.Now().Unix() {
+		return ErrInvalidResetToken
+	}
+	if err := p.passwordStore.UpdatePassword(ctx, reset.UserID, newPassword); err != nil {
+		log.Errorf("PasswordReset / ConfirmPasswordReset / UpdatePassword err %v ", err)
+		return err
+	}
+	p.auth.revokeUserSessions(reset.UserID, reset.Username)
+	p.auth.auditLogger.Emit(&model.AuditEvent{
+		Type:     model.AuditEventPasswordReset,
+		TenantID: TenantFromContext(ctx),
+		Username: reset.Username,
+		Time:     time.Now().Unix(),
+	})
+
+	return nil
+}
+
+// randomToken returns url safe random token with 256 bits of entropy
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns hex encoded SHA-256 of token, tokens are random so no salt is needed
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
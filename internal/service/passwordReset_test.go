@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/notify"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPasswordReset_ConfirmPasswordReset(t *testing.T) {
+	cfg := config.JwtConfig{AccessTokenExpiration: 30 * time.Minute}
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername, Email: mockEmail}, nil)
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("Delete", "", mockUsername).Return()
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("RevokeSubject", model.TenantKey("", mockUsername), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).Return()
+	mockAuditLogger := mocks.NewAuditLogger(t)
+	mockAuditLogger.On("Emit", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEventPasswordReset && event.Username == mockUsername
+	})).Return()
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		AuditLogger:   mockAuditLogger,
+	})
+	mockResetStorage := mocks.NewPasswordResetStorage(t)
+	var tokenHash string
+	var reset *model.PasswordReset
+	mockResetStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.PasswordReset")).
+		Run(func(args mock.Arguments) {
+			tokenHash, reset = args.String(0), args.Get(1).(*model.PasswordReset)
+		}).Return().Once()
+	mockPasswordStore := mocks.NewPasswordStore(t)
+	mockPasswordStore.On("UpdatePassword", mock.Anything, mockUserID, "new-password").Return(nil)
+	mailer := notify.NewMemoryMailer()
+	passwordReset := NewPasswordResetService(
+		&config.PasswordResetConfig{TokenExpiration: 15 * time.Minute, MaxRequests: 3, RequestWindow: time.Hour},
+		&config.PasswordPolicyConfig{MinLength: 8}, auth, mockResetStorage, mailer, mockPasswordStore, newMockAttemptCounter(t))
+
+	err := passwordReset.RequestPasswordReset(context.Background(), mockUsername)
+	assert.NoError(t, err, "Expected no error when requesting password reset")
+	messages := mailer.Messages(mockEmail)
+	assert.Len(t, messages, 1, "Expected password reset email")
+	token := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(messages[0].Body,
+		"Use this code to reset your password: "), "\n", 2)[0])
+	assert.NotEqual(t, token, tokenHash, "Reset token must be stored hashed")
+	assert.Equal(t, hashToken(token), tokenHash, "Stored reset token hash mismatch")
+
+	mockResetStorage.On("LoadAndDelete", tokenHash).Return(reset, true).Once()
+	err = passwordReset.ConfirmPasswordReset(context.Background(), token, "new-password")
+	assert.NoError(t, err, "Expected no error when confirming password reset")
+
+	mockResetStorage.On("LoadAndDelete", tokenHash).Return(nil, false).Once()
+	err = passwordReset.ConfirmPasswordReset(context.Background(), token, "new-password")
+	assert.ErrorIs(t, err, ErrInvalidResetToken, "Expected ErrInvalidResetToken for used reset token")
+}
+
+func TestPasswordReset_RequestPasswordReset_UnknownUser(t *testing.T) {
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(nil, status.Error(codes.NotFound, "user not found"))
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, mockUserServiceClient, AuthOptions{})
+	mailer := notify.NewMemoryMailer()
+	passwordReset := NewPasswordResetService(
+		&config.PasswordResetConfig{TokenExpiration: 15 * time.Minute, MaxRequests: 3, RequestWindow: time.Hour},
+		&config.PasswordPolicyConfig{MinLength: 8}, auth, mocks.NewPasswordResetStorage(t), mailer, nil, newMockAttemptCounter(t))
+
+	err := passwordReset.RequestPasswordReset(context.Background(), mockUsername)
+
+	assert.NoError(t, err, "Expected unknown user not to be reported")
+}
+
+func TestPasswordReset_ConfirmPasswordReset_Expired(t *testing.T) {
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, nil, AuthOptions{})
+	mockResetStorage := mocks.NewPasswordResetStorage(t)
+	mockResetStorage.On("LoadAndDelete", hashToken(mockRefreshToken)).
+		Return(&model.PasswordReset{UserID: mockUserID, Username: mockUsername, ExpiresAt: time.Now().Add(-time.Minute).Unix()}, true)
+	passwordReset := NewPasswordResetService(&config.PasswordResetConfig{}, &config.PasswordPolicyConfig{}, auth,
+		mockResetStorage, nil, nil, nil)
+
+	err := passwordReset.ConfirmPasswordReset(context.Background(), mockRefreshToken, "new-password")
+
+	assert.ErrorIs(t, err, ErrInvalidResetToken, "Expected ErrInvalidResetToken for expired reset token")
+}
+
+func TestPasswordReset_ConfirmPasswordReset_WeakPassword(t *testing.T) {
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, nil, AuthOptions{})
+	passwordReset := NewPasswordResetService(&config.PasswordResetConfig{}, &config.PasswordPolicyConfig{MinLength: 8},
+		auth, mocks.NewPasswordResetStorage(t), nil, nil, nil)
+
+	err := passwordReset.ConfirmPasswordReset(context.Background(), mockRefreshToken, "short")
+
+	assert.ErrorIs(t, err, ErrPasswordTooShort, "Expected ErrPasswordTooShort before reset token is consumed")
+}
+
+func TestPasswordReset_ConfirmPasswordReset_UpdateFailed(t *testing.T) {
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, nil, AuthOptions{})
+	mockResetStorage := mocks.NewPasswordResetStorage(t)
+	mockResetStorage.On("LoadAndDelete", hashToken(mockRefreshToken)).
+		Return(&model.PasswordReset{UserID: mockUserID, Username: mockUsername, ExpiresAt: time.Now().Add(time.Minute).Unix()}, true).Once()
+	mockPasswordStore := mocks.NewPasswordStore(t)
+	mockPasswordStore.On("UpdatePassword", mock.Anything, mockUserID, "new-password").
+		Return(status.Error(codes.Unavailable, "user service is unavailable"))
+	passwordReset := NewPasswordResetService(&config.PasswordResetConfig{}, &config.PasswordPolicyConfig{}, auth,
+		mockResetStorage, nil, mockPasswordStore, nil)
+
+	err := passwordReset.ConfirmPasswordReset(context.Background(), mockRefreshToken, "new-password")
+
+	assert.Equal(t, codes.Unavailable, status.Code(err), "Expected password store error")
+	mockResetStorage.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestPasswordReset_RequestPasswordReset_RateLimit(t *testing.T) {
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername, Email: mockEmail}, nil).Twice()
+	auth := newTestAuthService(t, &config.JwtConfig{}, nil, mockUserServiceClient, AuthOptions{})
+	mockResetStorage := mocks.NewPasswordResetStorage(t)
+	mockResetStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.PasswordReset")).Return().Twice()
+	mailer := notify.NewMemoryMailer()
+	passwordReset := NewPasswordResetService(
+		&config.PasswordResetConfig{TokenExpiration: 15 * time.Minute, MaxRequests: 2, RequestWindow: time.Hour},
+		&config.PasswordPolicyConfig{}, auth, mockResetStorage, mailer, nil, newMockAttemptCounter(t))
+
+	for i := 0; i < 2; i++ {
+		err := passwordReset.RequestPasswordReset(context.Background(), mockUsername)
+		assert.NoError(t, err, "Expected no error when requesting password reset within limit")
+	}
+	err := passwordReset.RequestPasswordReset(context.Background(), mockUsername)
+
+	assert.ErrorIs(t, err, ErrTooManyResetRequests, "Expected ErrTooManyResetRequests over limit")
+	assert.Len(t, mailer.Messages(mockEmail), 2, "Expected no email over limit")
+}
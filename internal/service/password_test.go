@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPassword_ChangePassword(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Tenants:                map[string]*config.Tenant{"acme": {ID: "acme"}}}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(mockPassword), bcrypt.MinCost)
+	assert.NoError(t, err, "Expected no error when hashing password")
+	mockUserServiceClient := mocks.NewUserServiceClient(t)
+	mockUserServiceClient.On("GetByUsername", mock.Anything, &userService.GetByUsernameRequest{Username: mockUsername}).
+		Return(&userService.GetByUsernameResponse{Uuid: mockUserID, Name: mockUsername, PasswordHash: string(passwordHash)}, nil)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	revokedAt := make(map[string]int64)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).
+		Return(func(subject string, issuedAt int64) bool {
+			at, ok := revokedAt[subject]
+			return ok && issuedAt <= at
+		})
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	mockAuditLogger := mocks.NewAuditLogger(t)
+	mockAuditLogger.On("Emit", mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEventPasswordChange && event.Username == mockUsername
+	})).Return()
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	session := &model.Session{}
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { *session = *args.Get(0).(*model.Session) }).Return()
+	mockSessionStorage.On("Load", "", mockUsername).Return(session, true)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist: mockTokenDenylist,
+		RoleStorage:   mockRoleStorage,
+		AuditLogger:   mockAuditLogger,
+	})
+	mockPasswordStore := mocks.NewPasswordStore(t)
+	password := NewPasswordService(&config.PasswordPolicyConfig{MinLength: 8, MaxChangeAttempts: 2,
+		ChangeAttemptWindow: time.Minute}, auth, mockPasswordStore, newMockAttemptCounter(t))
+	oldRefreshToken, accessToken, err := auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+	sessionID := session.ID
+
+	_, _, err = password.ChangePassword(context.Background(), accessToken, "wrong-password", "new-password")
+	assert.ErrorIs(t, err, ErrInvalidPassword, "Expected ErrInvalidPassword for wrong old password")
+	_, _, err = password.ChangePassword(context.Background(), accessToken, mockPassword, "short")
+	assert.ErrorIs(t, err, ErrPasswordTooShort, "Expected ErrPasswordTooShort for short new password")
+	_, _, err = password.ChangePassword(context.Background(), accessToken, mockPassword, mockPassword)
+	assert.ErrorIs(t, err, ErrPasswordNotChanged, "Expected ErrPasswordNotChanged for unchanged password")
+
+	mockPasswordStore.On("UpdatePassword", mock.Anything, mockUserID, "new-password").Return(nil)
+	mockSessionStorage.On("Delete", "", mockUsername).Return()
+	mockSessionStorage.On("Delete", "acme", mockUsername).Return()
+	mockTokenDenylist.On("RevokeSubject", mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
+		Run(func(args mock.Arguments) { revokedAt[args.String(0)] = args.Get(1).(int64) }).Return().Twice()
+	refreshToken, newAccessToken, err := password.ChangePassword(context.Background(), accessToken, mockPassword, "new-password")
+
+	assert.NoError(t, err, "Expected no error when changing password")
+	assert.NotEqual(t, oldRefreshToken, refreshToken, "Expected refresh token to be rotated")
+	assert.Equal(t, sessionID, session.ID, "Expected current session to be kept")
+	assert.Equal(t, refreshToken, session.RefreshToken, "Expected current session to hold new refresh token")
+	assert.Contains(t, revokedAt, model.TenantKey("acme", mockUsername), "Expected sessions in other tenants to be revoked")
+	claims, err := auth.validateAccessToken(context.Background(), newAccessToken)
+	assert.NoError(t, err, "Expected new access token issued after revocation to be valid")
+	assert.Equal(t, sessionID, claims.SessionID, "New access token session mismatch")
+	assert.Greater(t, claims.IssuedAtNano, revokedAt[model.TenantKey("", mockUsername)],
+		"New access token must be issued after the revocation")
+	_, err = auth.validateAccessToken(context.Background(), accessToken)
+	assert.ErrorIs(t, err, ErrAccessTokenRevoked, "Expected access token issued before password change to be revoked")
+}
+
+func TestPassword_ChangePassword_AttemptLimit(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(mockPassword), bcrypt.MinCost)
+	assert.NoError(t, err, "Expected no error when hashing password")
+	f := newAuthFixture(t, &cfg, AuthOptions{})
+	f.users[mockUsername].PasswordHash = string(passwordHash)
+	password := NewPasswordService(&config.PasswordPolicyConfig{MinLength: 8, MaxChangeAttempts: 2,
+		ChangeAttemptWindow: time.Minute}, f.auth, mocks.NewPasswordStore(t), newMockAttemptCounter(t))
+	_, accessToken, err := f.auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+
+	t.Log("Step 1: correct old password resets failed attempts")
+	_, _, err = password.ChangePassword(context.Background(), accessToken, "wrong-password", "new-password")
+	assert.ErrorIs(t, err, ErrInvalidPassword, "Expected ErrInvalidPassword for wrong old password")
+	_, _, err = password.ChangePassword(context.Background(), accessToken, mockPassword, "short")
+	assert.ErrorIs(t, err, ErrPasswordTooShort, "Expected old password to be checked after single failed attempt")
+
+	t.Log("Step 2: after MaxChangeAttempts failures even correct old password is refused")
+	for i := 0; i < 2; i++ {
+		_, _, err = password.ChangePassword(context.Background(), accessToken, "wrong-password", "new-password")
+		assert.ErrorIs(t, err, ErrInvalidPassword, "Expected ErrInvalidPassword for wrong old password")
+	}
+	_, _, err = password.ChangePassword(context.Background(), accessToken, mockPassword, "short")
+	assert.ErrorIs(t, err, ErrTooManyPasswordAttempts, "Expected ErrTooManyPasswordAttempts once attempts are exhausted")
+}
+
+func TestValidatePassword(t *testing.T) {
+	policy := &config.PasswordPolicyConfig{MinLength: 8, MaxLength: 72, RequireMixedCase: true, RequireDigit: true,
+		RequireSymbol: true}
+
+	assert.NoError(t, validatePassword(policy, "Str0ng-password"), "Expected password to satisfy policy")
+	assert.ErrorIs(t, validatePassword(policy, "S0-rt"), ErrPasswordTooShort, "Expected ErrPasswordTooShort")
+	assert.ErrorIs(t, validatePassword(policy, "S0-"+string(make([]byte, 70))), ErrPasswordTooLong,
+		"Expected ErrPasswordTooLong")
+	assert.ErrorIs(t, validatePassword(policy, "str0ng-password"), ErrPasswordTooWeak, "Expected mixed case to be required")
+	assert.ErrorIs(t, validatePassword(policy, "Strong-password"), ErrPasswordTooWeak, "Expected digit to be required")
+	assert.ErrorIs(t, validatePassword(policy, "Str0ngPassword"), ErrPasswordTooWeak, "Expected symbol to be required")
+}
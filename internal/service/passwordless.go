@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/userService/protocol/userService"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Passwordless sign-in modes
+const (
+	PasswordlessModeLink = "link"
+	PasswordlessModeCode = "code"
+)
+
+var (
+	// ErrUnsupportedPasswordlessMode godoc
+	ErrUnsupportedPasswordlessMode = errors.New("unsupported passwordless mode")
+	// ErrInvalidSignInCode godoc
+	ErrInvalidSignInCode = errors.New("invalid or expired sign-in code")
+	// ErrTooManyAttempts godoc
+	ErrTooManyAttempts = errors.New("too many sign-in attempts")
+)
+
+// PasswordlessStorage used to store pending passwordless sign-in challenges
+type PasswordlessStorage interface {
+	Save(key string, challenge *model.PasswordlessChallenge)
+	Load(key string) (*model.PasswordlessChallenge, bool)
+	LoadAndDelete(key string) (*model.PasswordlessChallenge, bool)
+}
+
+// Passwordless passwordless sign-in service struct
+type Passwordless struct {
+	cfg     *config.PasswordlessConfig
+	auth    *Auth
+	storage PasswordlessStorage
+	mailer  Mailer
+}
+
+// NewPasswordlessService creates new Passwordless service
+func NewPasswordlessService(cfg *config.PasswordlessConfig, auth *Auth, storage PasswordlessStorage,
+	mailer Mailer) *Passwordless {
+	return &Passwordless{cfg: cfg, auth: auth, storage: storage, mailer: mailer}
+}
+
+// StartPasswordless mails magic link token or 6-digit code to user email
+func (p *Passwordless) StartPasswordless(ctx context.Context, username, mode string) error {
+	if mode != PasswordlessModeLink && mode != PasswordlessModeCode {
+		return ErrUnsupportedPasswordlessMode
+	}
+	client, err := p.auth.client(ctx, GrantTypePasswordless)
+	if err != nil {
+		return err
+	}
+	user, err := p.auth.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: username,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	} else if err != nil {
+		log.Errorf("Passwordless / StartPasswordless /GetByUsername err %v ", err)
+		return err
+	}
+
+	now := time.Now()
+	challenge := &model.PasswordlessChallenge{
+		TenantID:  client.TenantID,
+		Username:  username,
+		ExpiresAt: now.Add(p.cfg.TokenExpiration).Unix(),
+	}
+	var body string
+	switch mode {
+	case PasswordlessModeLink:
+		token, err := randomToken()
+		if err != nil {
+			return err
+		}
+		p.storage.Save(linkKey(token), challenge)
+		body = fmt.Sprintf("Use this code to sign in: %s\n", token)
+		if p.cfg.URL != "" {
+			body = fmt.Sprintf("Follow the link to sign in: %s?token=%s\n", p.cfg.URL, token)
+		}
+	case PasswordlessModeCode:
+		code, err := randomCode()
+		if err != nil {
+			return err
+		}
+		// failed attempts carry over to a new code until the previous one expires, so resending doesn't reset the limit
+		key := codeKey(client.TenantID, username)
+		if previous, ok := p.storage.Load(key); ok && previous.ExpiresAt > now.Unix() {
+			if previous.Attempts >= p.cfg.MaxAttempts {
+				return ErrTooManyAttempts
+			}
+			challenge.Attempts = previous.Attempts
+		}
+		challenge.CodeHash = hashToken(code)
+		p.storage.Save(key, challenge)
+		body = fmt.Sprintf("Your sign-in code: %s\n", code)
+	}
+	body += fmt.Sprintf("It expires in %s. If you did not try to sign in, ignore this email.\n", p.cfg.TokenExpiration)
+
+	return p.mailer.Send(ctx, &model.Message{To: user.Email, Subject: "Sign in", Body: body})
+}
+
+// SignInWithLink issues tokens for owner of single-use magic link token
+func (p *Passwordless) SignInWithLink(ctx context.Context, token, scope string) (refreshToken, accessToken string, err error) {
+	tenant, err := p.auth.tenant(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	challenge, ok := p.storage.LoadAndDelete(linkKey(token))
+	if !ok || challenge.ExpiresAt <= time.Now().Unix() || challenge.TenantID != tenant.ID {
+		return "", "", ErrInvalidSignInCode
+	}
+
+	return p.auth.generateUserTokens(ctx, GrantTypePasswordless, challenge.Username, scope, []string{AuthMethodEmail}, nil)
+}
+
+// SignInWithCode issues tokens if code matches the one mailed to user, each code allows MaxAttempts guesses
+func (p *Passwordless) SignInWithCode(ctx context.Context, username, code, scope string) (refreshToken, accessToken string, err error) {
+	tenant, err := p.auth.tenant(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	key := codeKey(tenant.ID, username)
+	challenge, ok := p.storage.LoadAndDelete(key)
+	if !ok || challenge.ExpiresAt <= time.Now().Unix() {
+		return "", "", ErrInvalidSignInCode
+	}
+	if challenge.Attempts >= p.cfg.MaxAttempts {
+		p.storage.Save(key, challenge)
+		return "", "", ErrTooManyAttempts
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(challenge.CodeHash)) != 1 {
+		challenge.Attempts++
+		p.storage.Save(key, challenge)
+		return "", "", ErrInvalidSignInCode
+	}
+
+	return p.auth.generateUserTokens(ctx, GrantTypePasswordless, username, scope, []string{AuthMethodEmail}, nil)
+}
+
+// randomCode returns uniformly distributed 6-digit code
+func randomCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func linkKey(token string) string {
+	return "link/" + hashToken(token)
+}
+
+func codeKey(tenantID, username string) string {
+	return "code/" + model.TenantKey(tenantID, username)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/notify"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPasswordlessTestService(t *testing.T) (*Passwordless, *Auth, *mocks.PasswordlessStorage, *notify.MemoryMailer) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	auth := newAuthFixture(t, &cfg, AuthOptions{}).auth
+	mockStorage := mocks.NewPasswordlessStorage(t)
+	mailer := notify.NewMemoryMailer()
+
+	return NewPasswordlessService(&config.PasswordlessConfig{TokenExpiration: 10 * time.Minute, MaxAttempts: 3},
+		auth, mockStorage, mailer), auth, mockStorage, mailer
+}
+
+func TestPasswordless_SignInWithCode(t *testing.T) {
+	passwordless, auth, mockStorage, mailer := newPasswordlessTestService(t)
+	key := codeKey("", mockUsername)
+	var challenge *model.PasswordlessChallenge
+	mockStorage.On("Load", key).Return(nil, false).Once()
+	mockStorage.On("Save", key, mock.AnythingOfType("*model.PasswordlessChallenge")).
+		Run(func(args mock.Arguments) { challenge = args.Get(1).(*model.PasswordlessChallenge) }).Return()
+
+	err := passwordless.StartPasswordless(context.Background(), mockUsername, PasswordlessModeCode)
+	assert.NoError(t, err, "Expected no error when starting passwordless sign-in")
+	messages := mailer.Messages(mockEmail)
+	assert.Len(t, messages, 1, "Expected sign-in email")
+	code := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(messages[0].Body, "Your sign-in code: "), "\n", 2)[0])
+	assert.Len(t, code, 6, "Expected 6-digit code")
+	assert.NotEqual(t, code, challenge.CodeHash, "Code must be stored hashed")
+
+	mockStorage.On("LoadAndDelete", key).Return(func(string) *model.PasswordlessChallenge { return challenge },
+		func(string) bool { return true })
+	_, _, err = passwordless.SignInWithCode(context.Background(), mockUsername, wrongCode(code), "")
+	assert.ErrorIs(t, err, ErrInvalidSignInCode, "Expected ErrInvalidSignInCode for wrong code")
+	assert.Equal(t, 1, challenge.Attempts, "Expected failed attempt to be counted")
+
+	_, accessToken, err := passwordless.SignInWithCode(context.Background(), mockUsername, code, "")
+	assert.NoError(t, err, "Expected no error when signing in with code")
+	claims, err := auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, []string{AuthMethodEmail}, claims.AMR, "Passwordless amr mismatch")
+}
+
+func TestPasswordless_SignInWithCode_TooManyAttempts(t *testing.T) {
+	passwordless, _, mockStorage, mailer := newPasswordlessTestService(t)
+	key := codeKey("", mockUsername)
+	challenge := &model.PasswordlessChallenge{
+		Username:  mockUsername,
+		CodeHash:  hashToken("123456"),
+		Attempts:  3,
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}
+	mockStorage.On("LoadAndDelete", key).Return(challenge, true)
+	mockStorage.On("Save", key, challenge).Return()
+
+	_, _, err := passwordless.SignInWithCode(context.Background(), mockUsername, "123456", "")
+	assert.ErrorIs(t, err, ErrTooManyAttempts, "Expected ErrTooManyAttempts once attempts are exhausted")
+
+	mockStorage.On("Load", key).Return(challenge, true)
+	err = passwordless.StartPasswordless(context.Background(), mockUsername, PasswordlessModeCode)
+	assert.ErrorIs(t, err, ErrTooManyAttempts, "Expected new code not to reset attempts")
+	assert.Empty(t, mailer.Messages(mockEmail), "Expected no email while attempts are exhausted")
+}
+
+func TestPasswordless_SignInWithLink(t *testing.T) {
+	passwordless, _, mockStorage, mailer := newPasswordlessTestService(t)
+	var key string
+	var challenge *model.PasswordlessChallenge
+	mockStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.PasswordlessChallenge")).
+		Run(func(args mock.Arguments) {
+			key, challenge = args.String(0), args.Get(1).(*model.PasswordlessChallenge)
+		}).Return()
+
+	err := passwordless.StartPasswordless(context.Background(), mockUsername, PasswordlessModeLink)
+	assert.NoError(t, err, "Expected no error when starting passwordless sign-in")
+	token := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(mailer.Messages(mockEmail)[0].Body,
+		"Use this code to sign in: "), "\n", 2)[0])
+	assert.Equal(t, linkKey(token), key, "Magic link token must be stored hashed")
+
+	mockStorage.On("LoadAndDelete", key).Return(challenge, true).Once()
+	_, _, err = passwordless.SignInWithLink(context.Background(), token, "")
+	assert.NoError(t, err, "Expected no error when signing in with magic link")
+	mockStorage.On("LoadAndDelete", key).Return(nil, false).Once()
+	_, _, err = passwordless.SignInWithLink(context.Background(), token, "")
+	assert.ErrorIs(t, err, ErrInvalidSignInCode, "Expected magic link to be single-use")
+
+	err = passwordless.StartPasswordless(context.Background(), mockUsername, "sms")
+	assert.ErrorIs(t, err, ErrUnsupportedPasswordlessMode, "Expected ErrUnsupportedPasswordlessMode")
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/userService/protocol/userService"
+	log "github.com/sirupsen/logrus"
+)
+
+// Wildcard permission grants every permission
+const Wildcard = "*"
+
+var (
+	// ErrRoleNotFound godoc
+	ErrRoleNotFound = errors.New("role not found")
+	// ErrInvalidRole godoc
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrRoleCycle godoc
+	ErrRoleCycle = errors.New("role inheritance cycle")
+	// ErrRoleInUse godoc
+	ErrRoleInUse = errors.New("role is inherited by other roles")
+	// ErrPermissionDenied godoc
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrUnauthenticated godoc
+	ErrUnauthenticated = errors.New("unauthenticated")
+)
+
+// RoleStorage used to store roles and user role assignments
+type RoleStorage interface {
+	SaveRole(tenantID string, role *model.Role)
+	LoadRole(tenantID, name string) (*model.Role, bool)
+	// DeleteRole deletes role and unassigns it from all tenant users
+	DeleteRole(tenantID, name string)
+	ListRoles(tenantID string) []*model.Role
+	AssignRole(tenantID, userID, role string)
+	UnassignRole(tenantID, userID, role string)
+	LoadUserRoles(tenantID, userID string) []string
+}
+
+// RBAC role based access control service struct
+type RBAC struct {
+	cfg               *config.RBACConfig
+	roleStorage       RoleStorage
+	auth              *Auth
+	userServiceClient userService.UserServiceClient
+}
+
+// NewRBACService creates new RBAC service and grants admin role to configured users,
+// RBACConfig admins are granted in the default tenant, tenant admins in their tenants
+func NewRBACService(cfg *config.RBACConfig, roleStorage RoleStorage, auth *Auth,
+	userServiceClient userService.UserServiceClient) *RBAC {
+	r := &RBAC{cfg: cfg, roleStorage: roleStorage, auth: auth, userServiceClient: userServiceClient}
+	r.grantAdmin(auth.cfg.DefaultTenant, cfg.AdminUserIDs)
+	for tenantID, tenant := range auth.cfg.Tenants {
+		r.grantAdmin(tenantID, tenant.AdminUserIDs)
+	}
+	return r
+}
+
+// Resolve returns tenant roles assigned to user and effective permissions including inherited ones
+func (r *RBAC) Resolve(tenantID, userID string) (roles, permissions []string) {
+	return resolveRoles(r.roleStorage, tenantID, userID)
+}
+
+// PutRole creates or replaces role of the admin tenant
+func (r *RBAC) PutRole(ctx context.Context, accessToken string, role *model.Role) error {
+	tenantID, err := r.requireAdmin(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if role.Name == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidRole)
+	}
+	for _, parent := range role.Inherits {
+		if _, ok := r.roleStorage.LoadRole(tenantID, parent); !ok {
+			return fmt.Errorf("%w: %s", ErrRoleNotFound, parent)
+		}
+		if r.inherits(tenantID, parent, role.Name) {
+			return fmt.Errorf("%w: %s inherits %s", ErrRoleCycle, parent, role.Name)
+		}
+	}
+	r.roleStorage.SaveRole(tenantID, role)
+
+	return nil
+}
+
+// DeleteRole deletes role along with its assignments, users assigned to it lose its permissions.
+// Roles inherited by other roles can't be deleted until the inheriting roles drop them.
+func (r *RBAC) DeleteRole(ctx context.Context, accessToken, name string) error {
+	tenantID, err := r.requireAdmin(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.roleStorage.LoadRole(tenantID, name); !ok {
+		return ErrRoleNotFound
+	}
+	for _, role := range r.roleStorage.ListRoles(tenantID) {
+		if contains(role.Inherits, name) {
+			return fmt.Errorf("%w: %s inherits %s", ErrRoleInUse, role.Name, name)
+		}
+	}
+	r.roleStorage.DeleteRole(tenantID, name)
+
+	return nil
+}
+
+// ListRoles lists all roles of the admin tenant
+func (r *RBAC) ListRoles(ctx context.Context, accessToken string) ([]*model.Role, error) {
+	tenantID, err := r.requireAdmin(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.roleStorage.ListRoles(tenantID), nil
+}
+
+// AssignRole assigns role to user
+func (r *RBAC) AssignRole(ctx context.Context, accessToken, username, role string) error {
+	tenantID, err := r.requireAdmin(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.roleStorage.LoadRole(tenantID, role); !ok {
+		return ErrRoleNotFound
+	}
+	userID, err := r.userID(ctx, username)
+	if err != nil {
+		return err
+	}
+	r.roleStorage.AssignRole(tenantID, userID, role)
+
+	return nil
+}
+
+// UnassignRole removes role from user
+func (r *RBAC) UnassignRole(ctx context.Context, accessToken, username, role string) error {
+	tenantID, err := r.requireAdmin(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	userID, err := r.userID(ctx, username)
+	if err != nil {
+		return err
+	}
+	r.roleStorage.UnassignRole(tenantID, userID, role)
+
+	return nil
+}
+
+// ListUserRoles lists user roles and effective permissions
+func (r *RBAC) ListUserRoles(ctx context.Context, accessToken, username string) (roles, permissions []string, err error) {
+	tenantID, err := r.requireAdmin(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	userID, err := r.userID(ctx, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, permissions = r.Resolve(tenantID, userID)
+
+	return roles, permissions, nil
+}
+
+// grantAdmin creates tenant admin role if needed and assigns it to users
+func (r *RBAC) grantAdmin(tenantID string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	if _, ok := r.roleStorage.LoadRole(tenantID, r.cfg.AdminRole); !ok {
+		r.roleStorage.SaveRole(tenantID, &model.Role{Name: r.cfg.AdminRole, Permissions: []string{r.cfg.AdminPermission}})
+	}
+	for _, userID := range userIDs {
+		r.roleStorage.AssignRole(tenantID, userID, r.cfg.AdminRole)
+	}
+}
+
+// resolveRoles returns tenant roles assigned to user along with extra granted roles
+// and effective permissions walking role hierarchy
+func resolveRoles(roleStorage RoleStorage, tenantID, userID string, extra ...string) (roles, permissions []string) {
+	roles = roleStorage.LoadUserRoles(tenantID, userID)
+	for _, role := range extra {
+		if !contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	visited := make(map[string]bool)
+	granted := make(map[string]bool)
+	queue := append([]string(nil), roles...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		role, ok := roleStorage.LoadRole(tenantID, name)
+		if !ok {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			granted[permission] = true
+		}
+		queue = append(queue, role.Inherits...)
+	}
+	permissions = make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	sort.Strings(roles)
+
+	return roles, permissions
+}
+
+// hasPermission checks if user is currently granted permission in tenant, directly or by wildcard
+func hasPermission(roleStorage RoleStorage, tenantID, userID, permission string) bool {
+	_, permissions := resolveRoles(roleStorage, tenantID, userID)
+	return contains(permissions, permission) || contains(permissions, Wildcard)
+}
+
+// requireAdmin checks that access token holder is currently granted admin permission and returns its tenant,
+// permissions are resolved from role storage so revoked roles take effect before the token expires
+func (r *RBAC) requireAdmin(ctx context.Context, accessToken string) (string, error) {
+	claims, err := r.auth.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if !hasPermission(r.roleStorage, claims.TenantID, claims.Subject, r.cfg.AdminPermission) {
+		return "", ErrPermissionDenied
+	}
+	return claims.TenantID, nil
+}
+
+// inherits checks if tenant role inherits ancestor directly or transitively
+func (r *RBAC) inherits(tenantID, role, ancestor string) bool {
+	visited := make(map[string]bool)
+	queue := []string{role}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == ancestor {
+			return true
+		}
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		if current, ok := r.roleStorage.LoadRole(tenantID, name); ok {
+			queue = append(queue, current.Inherits...)
+		}
+	}
+	return false
+}
+
+func (r *RBAC) userID(ctx context.Context, username string) (string, error) {
+	user, err := r.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: username,
+	})
+	if err != nil {
+		log.Errorf("RBAC / userID /GetByUsername err %v ", err)
+		return "", err
+	}
+	return user.Uuid, nil
+}
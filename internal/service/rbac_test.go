@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockAdminPermission = "roles:admin"
+
+func newRBACTestService(t *testing.T, roleStorage RoleStorage) *RBAC {
+	cfg := config.JwtConfig{
+		AccessTokenKey:        mockAccessTokenKey,
+		AccessTokenExpiration: 30 * time.Minute}
+	auth := newAuthFixture(t, &cfg, AuthOptions{RoleStorage: roleStorage}).auth
+
+	return NewRBACService(&config.RBACConfig{AdminPermission: mockAdminPermission, AdminRole: "admin"}, roleStorage, auth, nil)
+}
+
+// expectAdmin makes mockUsername hold admin role in the default tenant
+func expectAdmin(roleStorage *mocks.RoleStorage) {
+	roleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"admin"})
+	roleStorage.On("LoadRole", "", "admin").
+		Return(&model.Role{Name: "admin", Permissions: []string{mockAdminPermission}}, true)
+}
+
+func TestRBAC_Resolve(t *testing.T) {
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	rbac := newRBACTestService(t, mockRoleStorage)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"editor"})
+	mockRoleStorage.On("LoadRole", "", "editor").
+		Return(&model.Role{Name: "editor", Permissions: []string{"documents:write"}, Inherits: []string{"viewer"}}, true)
+	mockRoleStorage.On("LoadRole", "", "viewer").
+		Return(&model.Role{Name: "viewer", Permissions: []string{"documents:read"}, Inherits: []string{"editor"}}, true)
+
+	roles, permissions := rbac.Resolve("", mockUserID)
+
+	assert.Equal(t, []string{"editor"}, roles, "Resolved roles mismatch")
+	assert.Equal(t, []string{"documents:read", "documents:write"}, permissions, "Resolved permissions mismatch")
+}
+
+func TestRBAC_PutRole_Cycle(t *testing.T) {
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	rbac := newRBACTestService(t, mockRoleStorage)
+	accessToken := newTestAccessToken(t, rbac.auth, &Claim{})
+	expectAdmin(mockRoleStorage)
+	mockRoleStorage.On("LoadRole", "", "editor").
+		Return(&model.Role{Name: "editor", Inherits: []string{"viewer"}}, true)
+
+	err := rbac.PutRole(context.Background(), accessToken, &model.Role{Name: "viewer", Inherits: []string{"editor"}})
+
+	assert.ErrorIs(t, err, ErrRoleCycle, "Expected ErrRoleCycle for cyclic role inheritance")
+}
+
+func TestRBAC_PutRole(t *testing.T) {
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	rbac := newRBACTestService(t, mockRoleStorage)
+	accessToken := newTestAccessToken(t, rbac.auth, &Claim{})
+	expectAdmin(mockRoleStorage)
+	role := &model.Role{Name: "editor", Permissions: []string{"documents:write"}, Inherits: []string{"viewer"}}
+	mockRoleStorage.On("LoadRole", "", "viewer").Return(&model.Role{Name: "viewer"}, true)
+	mockRoleStorage.On("SaveRole", "", role).Return()
+
+	err := rbac.PutRole(context.Background(), accessToken, role)
+
+	assert.NoError(t, err, "Expected no error when putting role")
+}
+
+func TestRBAC_PutRole_PermissionDenied(t *testing.T) {
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	rbac := newRBACTestService(t, mockRoleStorage)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadRole", "", "viewer").
+		Return(&model.Role{Name: "viewer", Permissions: []string{"documents:read"}}, true)
+	accessToken := newTestAccessToken(t, rbac.auth, &Claim{Permissions: []string{"documents:read"}})
+
+	err := rbac.PutRole(context.Background(), accessToken, &model.Role{Name: "viewer"})
+
+	assert.ErrorIs(t, err, ErrPermissionDenied, "Expected ErrPermissionDenied without admin permission")
+
+	t.Log("admin permission baked into the token is not trusted once admin role is gone")
+	accessToken = newTestAccessToken(t, rbac.auth, &Claim{Permissions: []string{mockAdminPermission}})
+	err = rbac.PutRole(context.Background(), accessToken, &model.Role{Name: "viewer"})
+	assert.ErrorIs(t, err, ErrPermissionDenied, "Expected ErrPermissionDenied for token of revoked admin")
+}
+
+func TestRBAC_DeleteRole(t *testing.T) {
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	rbac := newRBACTestService(t, mockRoleStorage)
+	accessToken := newTestAccessToken(t, rbac.auth, &Claim{})
+	expectAdmin(mockRoleStorage)
+	viewer := &model.Role{Name: "viewer", Permissions: []string{"documents:read"}}
+	editor := &model.Role{Name: "editor", Permissions: []string{"documents:write"}, Inherits: []string{"viewer"}}
+	mockRoleStorage.On("LoadRole", "", "viewer").Return(viewer, true)
+	mockRoleStorage.On("LoadRole", "", "editor").Return(editor, true)
+	mockRoleStorage.On("ListRoles", "").Return([]*model.Role{editor, viewer})
+	mockRoleStorage.On("DeleteRole", "", "editor").Return()
+
+	err := rbac.DeleteRole(context.Background(), accessToken, "viewer")
+	assert.ErrorIs(t, err, ErrRoleInUse, "Expected ErrRoleInUse for inherited role")
+
+	err = rbac.DeleteRole(context.Background(), accessToken, "editor")
+	assert.NoError(t, err, "Expected no error when deleting role nobody inherits")
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+)
+
+var (
+	// ErrReferenceTokensDisabled godoc
+	ErrReferenceTokensDisabled = errors.New("reference tokens are not configured")
+	// ErrReferenceTokenNotFound godoc
+	ErrReferenceTokenNotFound = errors.New("reference token not found")
+)
+
+// ReferenceTokenStorage used to store signed access tokens behind opaque reference tokens
+type ReferenceTokenStorage interface {
+	Save(tokenHash string, token *model.ReferenceToken)
+	Load(tokenHash string) (*model.ReferenceToken, bool)
+	Delete(tokenHash string)
+}
+
+// isReferenceToken opaque tokens are random url safe strings, unlike JWTs they have no dot separated segments
+func isReferenceToken(token string) bool {
+	return token != "" && !strings.Contains(token, ".")
+}
+
+// issueReferenceToken keeps signed access token server side and returns opaque token referencing it
+func (a *Auth) issueReferenceToken(tenantID, accessToken string, expiresAt int64) (string, error) {
+	if a.referenceTokenStorage == nil {
+		return "", ErrReferenceTokensDisabled
+	}
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	a.referenceTokenStorage.Save(hashToken(token), &model.ReferenceToken{
+		TenantID:    tenantID,
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+	})
+	return token, nil
+}
+
+// resolveReferenceToken returns claims of access token referenced by opaque token.
+// Resolved claims are cached, callers still check expiration and revocation on every use.
+func (a *Auth) resolveReferenceToken(tenant *config.Tenant, token string) (*Claim, error) {
+	if a.referenceTokenStorage == nil {
+		return nil, ErrReferenceTokenNotFound
+	}
+	tokenHash := hashToken(token)
+	if a.referenceTokenCache != nil {
+		if claims, ok := a.referenceTokenCache.Get(tokenHash); ok {
+			resolved := *claims
+			return &resolved, nil
+		}
+	}
+	stored, ok := a.referenceTokenStorage.Load(tokenHash)
+	if !ok {
+		return nil, ErrReferenceTokenNotFound
+	}
+	if stored.TenantID != tenant.ID {
+		return nil, ErrInvalidTokenTenant
+	}
+	claims, err := a.parseJWT(tenant, stored.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if a.referenceTokenCache != nil {
+		a.referenceTokenCache.Add(tokenHash, claims)
+	}
+	resolved := *claims
+	return &resolved, nil
+}
+
+// forgetReferenceToken removes opaque token, so it stops resolving right away
+func (a *Auth) forgetReferenceToken(token string) {
+	if !isReferenceToken(token) || a.referenceTokenStorage == nil {
+		return
+	}
+	tokenHash := hashToken(token)
+	a.referenceTokenStorage.Delete(tokenHash)
+	if a.referenceTokenCache != nil {
+		a.referenceTokenCache.Remove(tokenHash)
+	}
+}
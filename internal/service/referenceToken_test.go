@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuth_GenerateTokens_ReferenceToken(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:          mockAccessTokenKey,
+		AccessTokenExpiration:   30 * time.Minute,
+		RefreshTokenExpiration:  24 * time.Hour,
+		ReferenceTokenCacheSize: 10,
+		Clients: map[string]*config.ClientProfile{
+			"cli": {ID: "cli", AccessTokenFormat: config.AccessTokenFormatOpaque},
+		}}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockTokenDenylist := mocks.NewTokenDenylist(t)
+	mockTokenDenylist.On("IsRevoked", mock.AnythingOfType("string")).Return(false)
+	mockTokenDenylist.On("IsSubjectRevoked", model.TenantKey("", mockUsername), mock.AnythingOfType("int64")).Return(false)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return([]string{"viewer"})
+	mockRoleStorage.On("LoadRole", "", "viewer").Return(&model.Role{Name: "viewer"}, true)
+	mockReferenceTokenStorage := mocks.NewReferenceTokenStorage(t)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		TokenDenylist:         mockTokenDenylist,
+		RoleStorage:           mockRoleStorage,
+		ReferenceTokenStorage: mockReferenceTokenStorage,
+	})
+	var tokenHash string
+	var stored *model.ReferenceToken
+	mockReferenceTokenStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.ReferenceToken")).
+		Run(func(args mock.Arguments) {
+			tokenHash, stored = args.String(0), args.Get(1).(*model.ReferenceToken)
+		}).Return()
+	ctx := WithClient(context.Background(), "cli")
+
+	_, accessToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	assert.NotContains(t, accessToken, ".", "Expected opaque access token")
+	assert.Equal(t, hashToken(accessToken), tokenHash, "Reference token must be stored hashed")
+
+	t.Log("opaque token resolves to the claims a JWT would carry, the store is only hit once")
+	mockReferenceTokenStorage.On("Load", tokenHash).Return(stored, true).Once()
+	claims, err := auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating reference token")
+	assert.Equal(t, mockUserID, claims.Subject, "Subject mismatch")
+	assert.Equal(t, "cli", claims.ClientID, "Client mismatch")
+	assert.Equal(t, []string{"viewer"}, claims.Roles, "Roles mismatch")
+	claims.Roles = nil
+	claims, err = auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.NoError(t, err, "Expected no error when validating cached reference token")
+	assert.Equal(t, []string{"viewer"}, claims.Roles, "Cached claims must not be shared with callers")
+
+	t.Log("revoked opaque token stops resolving right away")
+	mockTokenDenylist.On("Revoke", claims.Id, claims.ExpiresAt).Return()
+	mockReferenceTokenStorage.On("Delete", tokenHash).Return()
+	err = auth.RevokeAccessToken(ctx, accessToken)
+	assert.NoError(t, err, "Expected no error when revoking reference token")
+	mockReferenceTokenStorage.On("Load", tokenHash).Return(nil, false)
+	_, err = auth.ValidateToken(ctx, accessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrReferenceTokenNotFound, "Expected ErrReferenceTokenNotFound for revoked token")
+
+	t.Log("other clients keep getting JWTs")
+	_, accessToken, err = auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	assert.Equal(t, 2, strings.Count(accessToken, "."), "Expected JWT access token")
+}
+
+func TestAuth_GenerateTokens_ReferenceTokenDisabled(t *testing.T) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		AccessTokenFormat:      config.AccessTokenFormatOpaque}
+	mockSessionStorage := mocks.NewSessionStorage(t)
+	mockSessionStorage.On("SaveSession", mock.AnythingOfType("*model.Session")).Return()
+	mockUserServiceClient := newMockUserServiceClient(t)
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", "", mockUserID).Return(nil)
+	auth := newTestAuthService(t, &cfg, mockSessionStorage, mockUserServiceClient, AuthOptions{
+		RoleStorage: mockRoleStorage,
+	})
+
+	_, _, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.ErrorIs(t, err, ErrReferenceTokensDisabled, "Expected ErrReferenceTokensDisabled without token storage")
+}
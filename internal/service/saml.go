@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/crewjam/saml"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxSAMLMetadataSize limits identity provider metadata fetched from URL
+const maxSAMLMetadataSize = 1 << 20
+
+// defaultSAMLMetadataTimeout bounds metadata requests when config has no timeout
+const defaultSAMLMetadataTimeout = 10 * time.Second
+
+var (
+	// ErrInvalidSAMLRelayState godoc
+	ErrInvalidSAMLRelayState = errors.New("invalid or expired SAML relay state")
+	// ErrInvalidSAMLResponse godoc
+	ErrInvalidSAMLResponse = errors.New("invalid SAML response")
+	// ErrSAMLAssertionReplayed godoc
+	ErrSAMLAssertionReplayed = errors.New("SAML assertion was already used")
+	// ErrUnsupportedSAMLBinding godoc
+	ErrUnsupportedSAMLBinding = errors.New("identity provider has no HTTP-Redirect SSO endpoint")
+)
+
+// SAMLRequestStorage used to store pending SAML logins by relay state hash
+type SAMLRequestStorage interface {
+	Save(relayStateHash string, request *model.SAMLRequest)
+	LoadAndDelete(relayStateHash string) (*model.SAMLRequest, bool)
+}
+
+// SAMLAssertionCache used to reject replayed assertions, Remember returns false for already consumed assertion
+type SAMLAssertionCache interface {
+	Remember(assertionID string, expiresAt int64) bool
+}
+
+// SAMLLogin identity provider SSO URL user agent is redirected to
+type SAMLLogin struct {
+	RedirectURL string
+	RelayState  string
+}
+
+// SAML SP-initiated SAML 2.0 login service struct
+type SAML struct {
+	cfg             *config.SAMLConfig
+	auth            *Auth
+	requestStorage  SAMLRequestStorage
+	assertionCache  SAMLAssertionCache
+	identityStorage FederatedIdentityStorage
+
+	clockSkew  time.Duration
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	idpMetadata map[string]*samlMetadata
+	refresh     singleflight.Group
+}
+
+// samlMetadata identity provider metadata cached until refreshAt
+type samlMetadata struct {
+	descriptor *saml.EntityDescriptor
+	refreshAt  time.Time
+}
+
+// NewSAMLService creates new SAML service. crewjam/saml only has process wide clock skew,
+// configured skew is applied on top of it so it can narrow but not widen the library leeway.
+func NewSAMLService(cfg *config.SAMLConfig, auth *Auth, requestStorage SAMLRequestStorage,
+	assertionCache SAMLAssertionCache, identityStorage FederatedIdentityStorage) *SAML {
+	clockSkew := cfg.ClockSkew
+	if clockSkew <= 0 || clockSkew > saml.MaxClockSkew {
+		clockSkew = saml.MaxClockSkew
+	}
+	timeout := cfg.MetadataTimeout
+	if timeout <= 0 {
+		timeout = defaultSAMLMetadataTimeout
+	}
+	return &SAML{
+		cfg:             cfg,
+		auth:            auth,
+		requestStorage:  requestStorage,
+		assertionCache:  assertionCache,
+		identityStorage: identityStorage,
+		clockSkew:       clockSkew,
+		httpClient:      &http.Client{Timeout: timeout},
+		idpMetadata:     make(map[string]*samlMetadata),
+	}
+}
+
+// Metadata returns SP metadata document identity providers are configured with
+func (s *SAML) Metadata() ([]byte, error) {
+	sp, err := s.serviceProvider(nil)
+	if err != nil {
+		return nil, err
+	}
+	return xml.MarshalIndent(sp.Metadata(), "", "  ")
+}
+
+// BeginLogin returns identity provider SSO URL carrying AuthnRequest and single-use relay state.
+// Requested scope applies to tokens issued once login completes.
+func (s *SAML) BeginLogin(ctx context.Context, providerID, scope string) (*SAMLLogin, error) {
+	client, err := s.auth.client(ctx, GrantTypeFederated)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = clientScope(client, scope); err != nil {
+		return nil, err
+	}
+	sp, err := s.providerServiceProvider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	ssoURL := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if ssoURL == "" {
+		return nil, ErrUnsupportedSAMLBinding
+	}
+	request, err := sp.MakeAuthenticationRequest(ssoURL, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		log.Errorf("SAML / BeginLogin / MakeAuthenticationRequest err %v ", err)
+		return nil, err
+	}
+
+	relayState, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	redirectURL, err := request.Redirect(relayState, sp)
+	if err != nil {
+		log.Errorf("SAML / BeginLogin / Redirect err %v ", err)
+		return nil, err
+	}
+	s.requestStorage.Save(hashToken(relayState), &model.SAMLRequest{
+		ProviderID: providerID,
+		TenantID:   client.TenantID,
+		ClientID:   client.ID,
+		Scope:      scope,
+		RequestID:  request.ID,
+		ExpiresAt:  time.Now().Add(s.cfg.RequestExpiration).Unix(),
+	})
+
+	return &SAMLLogin{RedirectURL: redirectURL.String(), RelayState: relayState}, nil
+}
+
+// CompleteLogin validates base64 encoded SAML response posted by identity provider and issues tokens
+// for the linked user, the user is created on first login. Assertion must be signed by the provider,
+// issued in response to the pending request and is accepted only once.
+func (s *SAML) CompleteLogin(ctx context.Context, samlResponse, relayState string) (refreshToken, accessToken string, err error) {
+	client, err := s.auth.client(ctx, GrantTypeFederated)
+	if err != nil {
+		return "", "", err
+	}
+	pending, ok := s.requestStorage.LoadAndDelete(hashToken(relayState))
+	if !ok || pending.ExpiresAt <= time.Now().Unix() ||
+		pending.TenantID != client.TenantID || pending.ClientID != client.ID {
+		return "", "", ErrInvalidSAMLRelayState
+	}
+	providerCfg, ok := s.cfg.Providers[pending.ProviderID]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+	sp, err := s.providerServiceProvider(ctx, pending.ProviderID)
+	if err != nil {
+		return "", "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return "", "", ErrInvalidSAMLResponse
+	}
+	assertion, err := sp.ParseXMLResponse(decoded, []string{pending.RequestID})
+	if err != nil {
+		var responseErr *saml.InvalidResponseError
+		if errors.As(err, &responseErr) {
+			err = responseErr.PrivateErr
+		}
+		log.Errorf("SAML / CompleteLogin / ParseXMLResponse err %v ", err)
+		return "", "", ErrInvalidSAMLResponse
+	}
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		return "", "", ErrInvalidSAMLResponse
+	}
+	if err = s.checkValidity(assertion); err != nil {
+		log.Errorf("SAML / CompleteLogin / checkValidity err %v ", err)
+		return "", "", ErrInvalidSAMLResponse
+	}
+	if !s.assertionCache.Remember(pending.ProviderID+"|"+assertion.ID, s.assertionExpiresAt(assertion)) {
+		return "", "", ErrSAMLAssertionReplayed
+	}
+
+	email := firstValue(attributeValues(assertion, providerCfg.EmailAttribute))
+	username, err := linkedUsername(ctx, s.auth, s.identityStorage, samlProviderKey(pending.ProviderID),
+		assertion.Subject.NameID.Value, email, providerCfg.TrustEmail && email != "")
+	if err != nil {
+		return "", "", err
+	}
+	roles := groupRoles(providerCfg.GroupRoles, attributeValues(assertion, providerCfg.GroupsAttribute))
+
+	return s.auth.generateUserTokens(ctx, GrantTypeFederated, username, pending.Scope,
+		[]string{AuthMethodFederated}, roles)
+}
+
+// providerServiceProvider returns service provider trusting given identity provider
+func (s *SAML) providerServiceProvider(ctx context.Context, providerID string) (*saml.ServiceProvider, error) {
+	idpMetadata, err := s.metadata(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.serviceProvider(idpMetadata)
+}
+
+func (s *SAML) serviceProvider(idpMetadata *saml.EntityDescriptor) (*saml.ServiceProvider, error) {
+	metadataURL, err := url.Parse(s.cfg.MetadataURL)
+	if err != nil {
+		return nil, err
+	}
+	acsURL, err := url.Parse(s.cfg.ACSURL)
+	if err != nil {
+		return nil, err
+	}
+	return &saml.ServiceProvider{
+		EntityID:          s.cfg.EntityID,
+		Key:               s.cfg.Key,
+		Certificate:       s.cfg.Certificate,
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		IDPMetadata:       idpMetadata,
+		AuthnNameIDFormat: saml.PersistentNameIDFormat,
+	}, nil
+}
+
+// metadata returns identity provider metadata, it is read on first use and again once refresh is due.
+// Cached metadata keeps being used when refresh fails so rotated keys are picked up without outages.
+// Metadata is read without holding the lock, concurrent logins with the provider share one read.
+func (s *SAML) metadata(ctx context.Context, providerID string) (*saml.EntityDescriptor, error) {
+	providerCfg, ok := s.cfg.Providers[providerID]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	s.mu.Lock()
+	cached, ok := s.idpMetadata[providerID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.refreshAt) {
+		return cached.descriptor, nil
+	}
+
+	descriptor, err, _ := s.refresh.Do(providerID, func() (interface{}, error) {
+		descriptor, err := s.readMetadata(ctx, providerCfg)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.idpMetadata[providerID] = &samlMetadata{descriptor: descriptor, refreshAt: s.metadataRefreshAt(descriptor)}
+		s.mu.Unlock()
+		return descriptor, nil
+	})
+	if err != nil {
+		log.Errorf("SAML / metadata / read metadata of %s err %v ", providerID, err)
+		if ok {
+			return cached.descriptor, nil
+		}
+		return nil, err
+	}
+
+	return descriptor.(*saml.EntityDescriptor), nil
+}
+
+// metadataRefreshAt returns when metadata is read again, cacheDuration declared by provider shortens the interval
+func (s *SAML) metadataRefreshAt(descriptor *saml.EntityDescriptor) time.Time {
+	interval := s.cfg.MetadataRefreshInterval
+	if descriptor.CacheDuration > 0 && (interval <= 0 || descriptor.CacheDuration < interval) {
+		interval = descriptor.CacheDuration
+	}
+	if interval <= 0 {
+		return time.Now()
+	}
+	return time.Now().Add(interval)
+}
+
+func (s *SAML) readMetadata(ctx context.Context, providerCfg *config.SAMLProvider) (*saml.EntityDescriptor, error) {
+	data, err := s.readMetadataDocument(ctx, providerCfg)
+	if err != nil {
+		return nil, err
+	}
+	descriptor := &saml.EntityDescriptor{}
+	if err = xml.Unmarshal(data, descriptor); err != nil {
+		return nil, err
+	}
+	return descriptor, nil
+}
+
+func (s *SAML) readMetadataDocument(ctx context.Context, providerCfg *config.SAMLProvider) ([]byte, error) {
+	if providerCfg.MetadataFile != "" {
+		return os.ReadFile(filepath.Clean(providerCfg.MetadataFile))
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, providerCfg.MetadataURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected metadata response status " + response.Status)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, maxSAMLMetadataSize))
+}
+
+// checkValidity checks assertion validity window with configured clock skew,
+// crewjam/saml has already checked it with its own wider default
+func (s *SAML) checkValidity(assertion *saml.Assertion) error {
+	now := saml.TimeNow()
+	if assertion.Conditions != nil {
+		if assertion.Conditions.NotBefore.Add(-s.clockSkew).After(now) {
+			return errors.New("assertion conditions are not yet valid")
+		}
+		if assertion.Conditions.NotOnOrAfter.Add(s.clockSkew).Before(now) {
+			return errors.New("assertion conditions are expired")
+		}
+	}
+	for _, confirmation := range assertion.Subject.SubjectConfirmations {
+		if confirmation.SubjectConfirmationData != nil &&
+			confirmation.SubjectConfirmationData.NotOnOrAfter.Add(s.clockSkew).Before(now) {
+			return errors.New("assertion subject confirmation is expired")
+		}
+	}
+	return nil
+}
+
+// assertionExpiresAt returns time until which assertion is accepted, including clock skew
+func (s *SAML) assertionExpiresAt(assertion *saml.Assertion) int64 {
+	if assertion.Conditions == nil {
+		return saml.TimeNow().Add(saml.MaxIssueDelay + s.clockSkew).Unix()
+	}
+	return assertion.Conditions.NotOnOrAfter.Add(s.clockSkew).Unix()
+}
+
+// attributeValues returns values of assertion attribute matched by name or friendly name
+func attributeValues(assertion *saml.Assertion, name string) []string {
+	if name == "" {
+		return nil
+	}
+	var values []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			if attribute.Name != name && attribute.FriendlyName != name {
+				continue
+			}
+			for _, value := range attribute.Values {
+				values = append(values, value.Value)
+			}
+		}
+	}
+	return values
+}
+
+// groupRoles maps identity provider groups to tenant roles
+func groupRoles(mapping map[string][]string, groups []string) []string {
+	var roles []string
+	for _, group := range groups {
+		for _, role := range mapping[group] {
+			if !contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// samlProviderKey namespaces SAML providers in federated identities shared with OpenID Connect providers
+func samlProviderKey(providerID string) string {
+	return "saml:" + providerID
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
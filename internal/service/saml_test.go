@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mockSAMLProviderID = "corp"
+	mockSAMLNameID     = "a3f1c9e2-corp-user"
+)
+
+// mockSAMLIdP local SAML identity provider signing assertions with a generated certificate
+type mockSAMLIdP struct {
+	idp        *saml.IdentityProvider
+	spMetadata *saml.EntityDescriptor
+}
+
+func newMockSAMLIdP(t *testing.T) *mockSAMLIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err, "Expected no error when generating IdP key")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mock-idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err, "Expected no error when creating IdP certificate")
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err, "Expected no error when parsing IdP certificate")
+	p := &mockSAMLIdP{}
+	p.idp = &saml.IdentityProvider{
+		Key:                     key,
+		Certificate:             certificate,
+		Logger:                  logger.DefaultLogger,
+		MetadataURL:             url.URL{Scheme: "https", Host: "idp.example.com", Path: "/metadata"},
+		SSOURL:                  url.URL{Scheme: "https", Host: "idp.example.com", Path: "/sso"},
+		ServiceProviderProvider: p,
+	}
+	return p
+}
+
+// GetServiceProvider returns metadata of the service provider under test
+func (p *mockSAMLIdP) GetServiceProvider(_ *http.Request, _ string) (*saml.EntityDescriptor, error) {
+	return p.spMetadata, nil
+}
+
+// writeMetadata stores IdP metadata in a file the provider config points to
+func (p *mockSAMLIdP) writeMetadata(t *testing.T) string {
+	data, err := xml.Marshal(p.idp.Metadata())
+	assert.NoError(t, err, "Expected no error when marshaling IdP metadata")
+	name := filepath.Join(t.TempDir(), "idp.xml")
+	assert.NoError(t, os.WriteFile(name, data, 0o600), "Expected no error when writing IdP metadata")
+	return name
+}
+
+// respond stands in for user sign in at IdP SSO URL, returns response posted back to ACS URL
+func (p *mockSAMLIdP) respond(t *testing.T, redirectURL string, session *saml.Session) (samlResponse, relayState string) {
+	request, err := saml.NewIdpAuthnRequest(p.idp, httptest.NewRequest(http.MethodGet, redirectURL, http.NoBody))
+	assert.NoError(t, err, "Expected no error when reading authentication request")
+	assert.NoError(t, request.Validate(), "Expected valid authentication request")
+	assert.NoError(t, saml.DefaultAssertionMaker{}.MakeAssertion(request, session), "Expected no error when making assertion")
+	form, err := request.PostBinding()
+	assert.NoError(t, err, "Expected no error when making response")
+	return form.SAMLResponse, form.RelayState
+}
+
+func newSAMLTestService(t *testing.T, idp *mockSAMLIdP) (*SAML, *authFixture, *mocks.FederatedIdentityStorage,
+	*mocks.SAMLAssertionCache) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	f := newAuthFixture(t, &cfg, AuthOptions{})
+	requests := make(map[string]*model.SAMLRequest)
+	mockRequestStorage := mocks.NewSAMLRequestStorage(t)
+	mockRequestStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.SAMLRequest")).
+		Run(func(args mock.Arguments) { requests[args.String(0)] = args.Get(1).(*model.SAMLRequest) }).Return().Maybe()
+	mockRequestStorage.On("LoadAndDelete", mock.AnythingOfType("string")).Return(
+		func(relayStateHash string) *model.SAMLRequest { return requests[relayStateHash] },
+		func(relayStateHash string) bool {
+			_, ok := requests[relayStateHash]
+			delete(requests, relayStateHash)
+			return ok
+		}).Maybe()
+	mockAssertionCache := mocks.NewSAMLAssertionCache(t)
+	mockIdentityStorage := mocks.NewFederatedIdentityStorage(t)
+	samlSvc := NewSAMLService(&config.SAMLConfig{
+		EntityID:          "https://auth.example.com/saml",
+		MetadataURL:       "https://auth.example.com/saml/metadata",
+		ACSURL:            "https://auth.example.com/saml/acs",
+		RequestExpiration: 10 * time.Minute,
+		Providers: map[string]*config.SAMLProvider{
+			mockSAMLProviderID: {
+				ID:              mockSAMLProviderID,
+				MetadataFile:    idp.writeMetadata(t),
+				EmailAttribute:  "eduPersonPrincipalName",
+				TrustEmail:      true,
+				GroupsAttribute: "eduPersonAffiliation",
+				GroupRoles:      map[string][]string{"Engineering": {"editor", "viewer"}},
+			},
+		}}, f.auth, mockRequestStorage, mockAssertionCache, mockIdentityStorage)
+	metadata, err := samlSvc.Metadata()
+	assert.NoError(t, err, "Expected no error when building SP metadata")
+	idp.spMetadata = &saml.EntityDescriptor{}
+	assert.NoError(t, xml.Unmarshal(metadata, idp.spMetadata), "Expected valid SP metadata")
+
+	return samlSvc, f, mockIdentityStorage, mockAssertionCache
+}
+
+func newSAMLTestSession() *saml.Session {
+	return &saml.Session{
+		ID:         "session-1",
+		CreateTime: time.Now(),
+		ExpireTime: time.Now().Add(time.Hour),
+		NameID:     mockSAMLNameID,
+		UserEmail:  mockEmail,
+		Groups:     []string{"Engineering", "Unmapped"},
+	}
+}
+
+func TestSAML_Metadata(t *testing.T) {
+	samlSvc, _, _, _ := newSAMLTestService(t, newMockSAMLIdP(t))
+
+	metadata, err := samlSvc.Metadata()
+	assert.NoError(t, err, "Expected no error when building SP metadata")
+	descriptor := &saml.EntityDescriptor{}
+	assert.NoError(t, xml.Unmarshal(metadata, descriptor), "Expected valid SP metadata")
+	assert.Equal(t, "https://auth.example.com/saml", descriptor.EntityID, "SP entity ID mismatch")
+	assert.Equal(t, "https://auth.example.com/saml/acs",
+		descriptor.SPSSODescriptors[0].AssertionConsumerServices[0].Location, "SP ACS URL mismatch")
+}
+
+func TestSAML_CompleteLogin(t *testing.T) {
+	idp := newMockSAMLIdP(t)
+	samlSvc, f, mockIdentityStorage, mockAssertionCache := newSAMLTestService(t, idp)
+	samlUsername := samlProviderKey(mockSAMLProviderID) + "|" + mockSAMLNameID
+
+	t.Log("First login creates and links the user, groups are mapped to roles")
+	login, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	assert.True(t, strings.HasPrefix(login.RedirectURL, "https://idp.example.com/sso?"), "Expected IdP SSO URL")
+	samlResponse, relayState := idp.respond(t, login.RedirectURL, newSAMLTestSession())
+	assert.Equal(t, login.RelayState, relayState, "Relay state mismatch")
+	mockAssertionCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true).Once()
+	mockIdentityStorage.On("LoadUserID", samlProviderKey(mockSAMLProviderID), mockSAMLNameID).Return("", false).Once()
+	f.userServiceClient.On("Create", mock.Anything, mock.MatchedBy(func(request *userService.CreateRequest) bool {
+		return request.Username == samlUsername && request.Email == mockEmail && request.Password != ""
+	})).Run(func(mock.Arguments) {
+		f.users[samlUsername] = &userService.GetByUsernameResponse{Uuid: mockUserID, Name: samlUsername, Email: mockEmail}
+	}).Return(&userService.CreateResponse{Uuid: mockUserID}, nil).Once()
+	mockIdentityStorage.On("Link", samlProviderKey(mockSAMLProviderID), mockSAMLNameID, mockUserID).Return().Once()
+	_, accessToken, err := samlSvc.CompleteLogin(context.Background(), samlResponse, relayState)
+	assert.NoError(t, err, "Expected no error when completing login")
+	claims, err := f.auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, samlUsername, claims.Username, "SAML username mismatch")
+	assert.Equal(t, []string{AuthMethodFederated}, claims.AMR, "SAML amr mismatch")
+	assert.Equal(t, []string{"editor", "viewer"}, claims.Roles, "Expected groups mapped to roles")
+
+	t.Log("Relay state can not be used twice")
+	_, _, err = samlSvc.CompleteLogin(context.Background(), samlResponse, relayState)
+	assert.ErrorIs(t, err, ErrInvalidSAMLRelayState, "Expected ErrInvalidSAMLRelayState for reused relay state")
+}
+
+func TestSAML_CompleteLoginReplayed(t *testing.T) {
+	idp := newMockSAMLIdP(t)
+	samlSvc, _, _, mockAssertionCache := newSAMLTestService(t, idp)
+
+	login, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	samlResponse, relayState := idp.respond(t, login.RedirectURL, newSAMLTestSession())
+	mockAssertionCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(false).Once()
+	_, _, err = samlSvc.CompleteLogin(context.Background(), samlResponse, relayState)
+	assert.ErrorIs(t, err, ErrSAMLAssertionReplayed, "Expected ErrSAMLAssertionReplayed for consumed assertion")
+}
+
+func TestSAML_CompleteLoginInvalidResponse(t *testing.T) {
+	idp := newMockSAMLIdP(t)
+	samlSvc, _, _, _ := newSAMLTestService(t, idp)
+
+	t.Log("Tampered assertion fails signature validation")
+	login, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	samlResponse, relayState := idp.respond(t, login.RedirectURL, newSAMLTestSession())
+	decoded, err := base64.StdEncoding.DecodeString(samlResponse)
+	assert.NoError(t, err, "Expected base64 encoded response")
+	tampered := strings.ReplaceAll(string(decoded), mockSAMLNameID, "admin")
+	_, _, err = samlSvc.CompleteLogin(context.Background(), base64.StdEncoding.EncodeToString([]byte(tampered)), relayState)
+	assert.ErrorIs(t, err, ErrInvalidSAMLResponse, "Expected ErrInvalidSAMLResponse for tampered assertion")
+
+	t.Log("Assertion issued for another request is rejected")
+	first, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	second, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	samlResponse, _ = idp.respond(t, first.RedirectURL, newSAMLTestSession())
+	_, _, err = samlSvc.CompleteLogin(context.Background(), samlResponse, second.RelayState)
+	assert.ErrorIs(t, err, ErrInvalidSAMLResponse, "Expected ErrInvalidSAMLResponse for other request")
+
+	t.Log("Expired assertion is rejected beyond clock skew")
+	login, err = samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	samlResponse, relayState = idp.respond(t, login.RedirectURL, newSAMLTestSession())
+	now := saml.TimeNow
+	saml.TimeNow = func() time.Time { return time.Now().Add(saml.MaxIssueDelay + saml.MaxClockSkew + time.Minute) }
+	defer func() { saml.TimeNow = now }()
+	_, _, err = samlSvc.CompleteLogin(context.Background(), samlResponse, relayState)
+	assert.ErrorIs(t, err, ErrInvalidSAMLResponse, "Expected ErrInvalidSAMLResponse for expired assertion")
+}
+
+func TestSAML_CompleteLoginClockSkew(t *testing.T) {
+	idp := newMockSAMLIdP(t)
+	samlSvc, _, _, _ := newSAMLTestService(t, idp)
+	samlSvc.clockSkew = 10 * time.Second
+
+	login, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	samlResponse, relayState := idp.respond(t, login.RedirectURL, newSAMLTestSession())
+	now := saml.TimeNow
+	saml.TimeNow = func() time.Time { return time.Now().Add(-time.Minute) }
+	defer func() { saml.TimeNow = now }()
+	_, _, err = samlSvc.CompleteLogin(context.Background(), samlResponse, relayState)
+
+	assert.ErrorIs(t, err, ErrInvalidSAMLResponse, "Expected ErrInvalidSAMLResponse for assertion not valid within configured skew")
+	assert.Equal(t, 180*time.Second, saml.MaxClockSkew, "Expected library clock skew left untouched")
+}
+
+func TestSAML_MetadataRefresh(t *testing.T) {
+	idp := newMockSAMLIdP(t)
+	samlSvc, _, _, _ := newSAMLTestService(t, idp)
+	var requests, failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := xml.Marshal(idp.idp.Metadata())
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	samlSvc.cfg.Providers[mockSAMLProviderID].MetadataFile = ""
+	samlSvc.cfg.Providers[mockSAMLProviderID].MetadataURL = server.URL
+	samlSvc.cfg.MetadataRefreshInterval = time.Hour
+
+	t.Log("Metadata is fetched once while fresh")
+	_, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	_, err = samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "Expected cached metadata to be reused")
+
+	t.Log("Due metadata is fetched again")
+	samlSvc.idpMetadata[mockSAMLProviderID].refreshAt = time.Now().Add(-time.Second)
+	_, err = samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected no error when beginning login")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "Expected metadata to be refreshed")
+
+	t.Log("Failed refresh keeps cached metadata")
+	atomic.StoreInt32(&failing, 1)
+	samlSvc.idpMetadata[mockSAMLProviderID].refreshAt = time.Now().Add(-time.Second)
+	_, err = samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+	assert.NoError(t, err, "Expected cached metadata when refresh fails")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests), "Expected refresh attempt")
+}
+
+func TestSAML_MetadataSlowProvider(t *testing.T) {
+	idp := newMockSAMLIdP(t)
+	samlSvc, _, _, _ := newSAMLTestService(t, idp)
+	reading, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(reading)
+		<-release
+		data, _ := xml.Marshal(idp.idp.Metadata())
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	slow := *samlSvc.cfg.Providers[mockSAMLProviderID]
+	slow.ID, slow.MetadataFile, slow.MetadataURL = "slow", "", server.URL
+	samlSvc.cfg.Providers[slow.ID] = &slow
+	slowLogin := make(chan error)
+	go func() {
+		_, err := samlSvc.BeginLogin(context.Background(), slow.ID, "")
+		slowLogin <- err
+	}()
+	<-reading
+
+	t.Log("login with other provider doesn't wait for slow metadata read")
+	done := make(chan error)
+	go func() {
+		_, err := samlSvc.BeginLogin(context.Background(), mockSAMLProviderID, "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err, "Expected no error when beginning login")
+	case <-time.After(5 * time.Second):
+		t.Error("Expected login to complete while other provider metadata is being read")
+	}
+	close(release)
+	assert.NoError(t, <-slowLogin, "Expected no error when beginning login with slow provider")
+}
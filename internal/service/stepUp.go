@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+)
+
+// Authentication method references, see RFC 8176
+const (
+	AuthMethodPassword  = "pwd"
+	AuthMethodOTP       = "otp"
+	AuthMethodWebAuthn  = "webauthn"
+	AuthMethodEmail     = "email"
+	AuthMethodFederated = "fed"
+)
+
+// Authentication context class references, authenticator assurance levels of NIST SP 800-63B
+const (
+	ACRSingleFactor = "aal1"
+	ACRMultiFactor  = "aal2"
+)
+
+var (
+	// ErrUnsupportedAuthMethod godoc
+	ErrUnsupportedAuthMethod = errors.New("unsupported authentication method")
+	// ErrInvalidSecondFactor godoc
+	ErrInvalidSecondFactor = errors.New("invalid second factor")
+	// ErrSessionNotFound godoc
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrStepUpNotElevated godoc
+	ErrStepUpNotElevated = errors.New("authentication method doesn't raise assurance level")
+	// ErrTooManyStepUpAttempts godoc
+	ErrTooManyStepUpAttempts = errors.New("too many step-up attempts")
+)
+
+// SecondFactorVerifier used to verify second factor credentials such as one-time passwords
+type SecondFactorVerifier interface {
+	Verify(ctx context.Context, userID, method, credential string) error
+}
+
+// AttemptCounter used to count failed attempts per key within fixed windows
+type AttemptCounter interface {
+	Add(key string, window time.Duration) int
+	Count(key string) int
+	Reset(key string)
+}
+
+// StepUpResult elevated access token
+type StepUpResult struct {
+	AccessToken string
+	ExpiresIn   int64
+	ACR         string
+}
+
+// StepUp step-up authentication service struct
+type StepUp struct {
+	cfg                  *config.StepUpConfig
+	auth                 *Auth
+	secondFactorVerifier SecondFactorVerifier
+	attempts             AttemptCounter
+}
+
+// NewStepUpService creates new StepUp service, nil verifier allows password step-up only
+func NewStepUpService(cfg *config.StepUpConfig, auth *Auth, secondFactorVerifier SecondFactorVerifier,
+	attempts AttemptCounter) *StepUp {
+	return &StepUp{cfg: cfg, auth: auth, secondFactorVerifier: secondFactorVerifier, attempts: attempts}
+}
+
+// StepUp re-verifies user of access token session with password or one-time password and issues short-lived
+// multi-factor access token with fresh auth_time and the verified method added to amr. Unsupported methods
+// and methods that leave the token single-factor are refused before the credential is checked. Every check
+// takes an attempt up front, so concurrent requests can't exceed MaxAttempts, after that the user can't step up
+// until AttemptWindow passes. Session itself is not elevated, tokens issued on refresh keep session
+// authentication methods.
+func (s *StepUp) StepUp(ctx context.Context, accessToken, method, credential string) (*StepUpResult, error) {
+	claims, err := s.auth.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	session, ok := s.auth.sessionStorage.Load(claims.TenantID, claims.Username)
+	if !ok || claims.SessionID == "" || session.ID != claims.SessionID {
+		return nil, ErrSessionNotFound
+	}
+
+	tenant, err := s.auth.tenantByID(session.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := s.auth.cfg.Client(tenant, session.ClientID)
+	if !ok {
+		return nil, ErrInvalidClient
+	}
+	switch method {
+	case AuthMethodPassword:
+	case AuthMethodOTP:
+		if s.secondFactorVerifier == nil {
+			return nil, ErrUnsupportedAuthMethod
+		}
+	default:
+		return nil, ErrUnsupportedAuthMethod
+	}
+	methods := append([]string(nil), session.AuthMethods...)
+	if !contains(methods, method) {
+		methods = append(methods, method)
+	}
+	if assuranceLevel(methods) != ACRMultiFactor {
+		return nil, ErrStepUpNotElevated
+	}
+	attemptsKey := model.TenantKey(session.TenantID, session.UserID)
+	if s.attempts.Add(attemptsKey, s.cfg.AttemptWindow) > s.cfg.MaxAttempts {
+		return nil, ErrTooManyStepUpAttempts
+	}
+
+	if method == AuthMethodPassword {
+		_, err = s.auth.verifyPassword(ctx, session.Username, credential)
+	} else {
+		err = s.secondFactorVerifier.Verify(ctx, session.UserID, method, credential)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.attempts.Reset(attemptsKey)
+
+	now := time.Now()
+	elevated := *session
+	elevated.AuthTime = now.Unix()
+	elevated.AuthMethods = methods
+	elevated.ACR = ACRMultiFactor
+	expiresAt := capExpiration(now.Add(s.cfg.TokenExpiration).Unix(), sessionExpiration(session, client))
+	if expiresAt <= now.Unix() {
+		return nil, ErrSessionLifetimeExceeded
+	}
+	elevatedToken, err := s.auth.generateAccessToken(&elevated, tenant.AccessTokenKey, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StepUpResult{
+		AccessToken: elevatedToken,
+		ExpiresIn:   expiresAt - now.Unix(),
+		ACR:         elevated.ACR,
+	}, nil
+}
+
+// assuranceLevel returns acr for authentication methods, password combined with another factor
+// or a phishing resistant authenticator is multi-factor
+func assuranceLevel(methods []string) string {
+	switch {
+	case len(methods) == 0:
+		return ""
+	case contains(methods, AuthMethodWebAuthn) || len(methods) > 1:
+		return ACRMultiFactor
+	default:
+		return ACRSingleFactor
+	}
+}
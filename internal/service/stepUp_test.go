@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const mockPassword = "mock-password"
+
+func newStepUpTestService(t *testing.T, secondFactorVerifier SecondFactorVerifier) (*StepUp, *authFixture) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		SessionAbsoluteTimeout: 8 * time.Hour}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(mockPassword), bcrypt.MinCost)
+	assert.NoError(t, err, "Expected no error when hashing password")
+	f := newAuthFixture(t, &cfg, AuthOptions{})
+	f.users[mockUsername].PasswordHash = string(passwordHash)
+
+	stepUpCfg := &config.StepUpConfig{TokenExpiration: 5 * time.Minute, MaxAttempts: 2, AttemptWindow: time.Minute}
+	return NewStepUpService(stepUpCfg, f.auth, secondFactorVerifier, newMockAttemptCounter(t)), f
+}
+
+func TestStepUp_StepUp_Password(t *testing.T) {
+	stepUp, f := newStepUpTestService(t, nil)
+	auth := f.auth
+
+	t.Log("Step 1: password doesn't elevate session authenticated with password")
+	_, accessToken, err := auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+	claims, err := auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	assert.Equal(t, []string{AuthMethodPassword}, claims.AMR, "Sign in amr mismatch")
+	assert.Equal(t, ACRSingleFactor, claims.ACR, "Sign in acr mismatch")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodPassword, mockPassword)
+	assert.ErrorIs(t, err, ErrStepUpNotElevated, "Expected ErrStepUpNotElevated for password over password session")
+
+	t.Log("Step 2: password elevates session authenticated with emailed code")
+	_, accessToken, err = auth.generateUserTokens(context.Background(), GrantTypeTrusted, mockUsername, "",
+		[]string{AuthMethodEmail}, nil)
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	claims, err = auth.parseAccessToken(context.Background(), accessToken)
+	assert.NoError(t, err, "Expected no error when parsing access token")
+	result, err := stepUp.StepUp(context.Background(), accessToken, AuthMethodPassword, mockPassword)
+	assert.NoError(t, err, "Expected no error when stepping up with password")
+	assert.Equal(t, ACRMultiFactor, result.ACR, "Elevated token acr mismatch")
+	assert.LessOrEqual(t, result.ExpiresIn, int64((5 * time.Minute).Seconds()), "Elevated token is not short-lived")
+	elevated, err := auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected elevated token to be valid")
+	assert.Equal(t, f.session("").ID, elevated.SessionID, "Elevated token session mismatch")
+	assert.Equal(t, []string{AuthMethodEmail, AuthMethodPassword}, elevated.AMR, "Elevated token amr mismatch")
+	assert.Equal(t, ACRMultiFactor, elevated.ACR, "Elevated token acr claim mismatch")
+	assert.GreaterOrEqual(t, elevated.AuthTime, claims.AuthTime, "Elevated token auth_time is not fresh")
+
+	t.Log("Step 3: wrong password and missing second factor verifier are rejected")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodPassword, "wrong-password")
+	assert.ErrorIs(t, err, ErrInvalidPassword, "Expected ErrInvalidPassword for wrong password")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "123456")
+	assert.ErrorIs(t, err, ErrUnsupportedAuthMethod, "Expected ErrUnsupportedAuthMethod without second factor verifier")
+}
+
+func TestStepUp_StepUp_AttemptLimit(t *testing.T) {
+	mockSecondFactorVerifier := mocks.NewSecondFactorVerifier(t)
+	mockSecondFactorVerifier.On("Verify", mock.Anything, mockUserID, AuthMethodOTP, "123456").Return(nil)
+	mockSecondFactorVerifier.On("Verify", mock.Anything, mockUserID, AuthMethodOTP, "000000").Return(ErrInvalidSecondFactor)
+	stepUp, f := newStepUpTestService(t, mockSecondFactorVerifier)
+	_, accessToken, err := f.auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+
+	t.Log("Step 1: successful step-up resets failed attempts")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "000000")
+	assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected ErrInvalidSecondFactor for wrong one-time password")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "123456")
+	assert.NoError(t, err, "Expected no error after single failed attempt")
+
+	t.Log("Step 2: after MaxAttempts failures even valid credential is refused")
+	for i := 0; i < 2; i++ {
+		_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "000000")
+		assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected ErrInvalidSecondFactor for wrong one-time password")
+	}
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "123456")
+	assert.ErrorIs(t, err, ErrTooManyStepUpAttempts, "Expected ErrTooManyStepUpAttempts once attempts are exhausted")
+}
+
+func TestStepUp_StepUp_WebAuthn(t *testing.T) {
+	mockSecondFactorVerifier := mocks.NewSecondFactorVerifier(t)
+	mockSecondFactorVerifier.On("Verify", mock.Anything, mockUserID, AuthMethodOTP, "123456").Return(nil)
+	stepUp, f := newStepUpTestService(t, mockSecondFactorVerifier)
+	_, accessToken, err := f.auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+
+	t.Log("Step 1: webauthn is refused without reaching the verifier")
+	for i := 0; i < 3; i++ {
+		_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodWebAuthn, "assertion")
+		assert.ErrorIs(t, err, ErrUnsupportedAuthMethod, "Expected ErrUnsupportedAuthMethod for webauthn")
+	}
+
+	t.Log("Step 2: refused webauthn requests don't use up attempts")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "123456")
+	assert.NoError(t, err, "Expected no error when stepping up with one-time password")
+}
+
+func TestStepUp_StepUp_SessionExpiration(t *testing.T) {
+	mockSecondFactorVerifier := mocks.NewSecondFactorVerifier(t)
+	mockSecondFactorVerifier.On("Verify", mock.Anything, mockUserID, AuthMethodOTP, "123456").Return(nil)
+	stepUp, f := newStepUpTestService(t, mockSecondFactorVerifier)
+	_, accessToken, err := f.auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+	sessionExpiresAt := time.Now().Add(time.Minute)
+	f.session("").AuthTime = sessionExpiresAt.Add(-8 * time.Hour).Unix()
+
+	result, err := stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "123456")
+
+	assert.NoError(t, err, "Expected no error when stepping up")
+	elevated, err := f.auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected elevated token to be valid")
+	assert.LessOrEqual(t, elevated.ExpiresAt, sessionExpiresAt.Unix(), "Elevated token outlives session")
+	assert.LessOrEqual(t, result.ExpiresIn, int64(time.Minute.Seconds()), "Expires in is not capped")
+}
+
+func TestStepUp_StepUp_SecondFactor(t *testing.T) {
+	mockSecondFactorVerifier := mocks.NewSecondFactorVerifier(t)
+	mockSecondFactorVerifier.On("Verify", mock.Anything, mockUserID, AuthMethodOTP, "123456").Return(nil)
+	mockSecondFactorVerifier.On("Verify", mock.Anything, mockUserID, AuthMethodOTP, "000000").Return(ErrInvalidSecondFactor)
+	stepUp, f := newStepUpTestService(t, mockSecondFactorVerifier)
+	auth := f.auth
+	_, accessToken, err := auth.SignIn(context.Background(), mockUsername, mockPassword, "")
+	assert.NoError(t, err, "Expected no error when signing in")
+
+	result, err := stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "123456")
+
+	assert.NoError(t, err, "Expected no error when stepping up with one-time password")
+	assert.Equal(t, ACRMultiFactor, result.ACR, "Elevated token acr mismatch")
+	elevated, err := auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected elevated token to be valid")
+	assert.Equal(t, []string{AuthMethodPassword, AuthMethodOTP}, elevated.AMR, "Elevated token amr mismatch")
+	assert.Equal(t, []string{AuthMethodPassword}, f.session("").AuthMethods, "Session must not be elevated")
+	_, err = stepUp.StepUp(context.Background(), accessToken, AuthMethodOTP, "000000")
+	assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected ErrInvalidSecondFactor for wrong one-time password")
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Entetry/authService/internal/config"
+)
+
+var (
+	// ErrUnknownTenant godoc
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrInvalidTokenTenant godoc
+	ErrInvalidTokenTenant = errors.New("invalid token tenant")
+	// ErrNotTenantMember godoc
+	ErrNotTenantMember = errors.New("user is not a tenant member")
+)
+
+type tenantKey struct{}
+
+// WithTenant returns context carrying tenant id
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns tenant id carried by context, empty for default tenant
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
+
+// tenant resolves settings of the context tenant
+func (a *Auth) tenant(ctx context.Context) (*config.Tenant, error) {
+	return a.tenantByID(TenantFromContext(ctx))
+}
+
+func (a *Auth) tenantByID(tenantID string) (*config.Tenant, error) {
+	tenant, ok := a.cfg.Tenant(tenantID)
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	return tenant, nil
+}
+
+// checkTenantMember checks user may sign in to tenant, everyone belongs to default tenant
+// while other tenants require at least one role assigned to user in that tenant
+func (a *Auth) checkTenantMember(tenantID, userID string) error {
+	if tenantID == "" || tenantID == a.cfg.DefaultTenant {
+		return nil
+	}
+	if len(a.roleStorage.LoadUserRoles(tenantID, userID)) == 0 {
+		return ErrNotTenantMember
+	}
+	return nil
+}
+
+// HasTenant checks if tenant is configured, empty id means default tenant
+func (a *Auth) HasTenant(tenantID string) bool {
+	_, ok := a.cfg.Tenant(tenantID)
+	return ok
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// GrantTypeTokenExchange recorded in gty claim of exchanged tokens, see RFC 8693 section 2.1
+const GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// Token type identifiers, see RFC 8693 section 3
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+var (
+	// ErrUnsupportedTokenType godoc
+	ErrUnsupportedTokenType = errors.New("unsupported token type")
+	// ErrInvalidTarget godoc
+	ErrInvalidTarget = errors.New("requested audience is not allowed")
+	// ErrInvalidScope godoc
+	ErrInvalidScope = errors.New("requested scope exceeds subject token scope")
+	// ErrExchangeNotAllowed godoc
+	ErrExchangeNotAllowed = errors.New("token exchange is not allowed")
+)
+
+// ExchangeRequest token exchange request parameters
+type ExchangeRequest struct {
+	SubjectToken       string
+	SubjectTokenType   string
+	ActorToken         string
+	ActorTokenType     string
+	RequestedSubject   string
+	RequestedTokenType string
+	Audience           string
+	Scope              string
+}
+
+// ExchangeResult issued token
+type ExchangeResult struct {
+	AccessToken     string
+	IssuedTokenType string
+	ExpiresIn       int64
+	Scope           string
+}
+
+// TokenExchange token exchange service struct
+type TokenExchange struct {
+	cfg  *config.TokenExchangeConfig
+	auth *Auth
+}
+
+// NewTokenExchangeService creates new TokenExchange service
+func NewTokenExchangeService(cfg *config.TokenExchangeConfig, auth *Auth) *TokenExchange {
+	return &TokenExchange{cfg: cfg, auth: auth}
+}
+
+// Exchange exchanges subject token for a new access token narrowed to requested audience and scope.
+// Actor token turns the result into a delegation token, requested subject into an impersonation token,
+// both are recorded in the act claim. Exchanged token keeps the key or certificate binding of the subject token.
+func (e *TokenExchange) Exchange(ctx context.Context, request *ExchangeRequest) (*ExchangeResult, error) {
+	if !isSupportedTokenType(request.SubjectTokenType) ||
+		request.RequestedTokenType != "" && !isSupportedTokenType(request.RequestedTokenType) {
+		return nil, ErrUnsupportedTokenType
+	}
+	tenant, err := e.auth.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := e.auth.verifyAccessToken(ctx, request.SubjectToken)
+	if err != nil {
+		return nil, err
+	}
+
+	audience, err := e.audience(subject, request.Audience)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := downscope(subject.Scope, request.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claim{
+		StandardClaims: jwt.StandardClaims{
+			Subject: subject.Subject,
+		},
+		Username:      subject.Username,
+		TenantID:      subject.TenantID,
+		ClientID:      subject.ClientID,
+		AuthTime:      subject.AuthTime,
+		AMR:           subject.AMR,
+		ACR:           subject.ACR,
+		SessionID:     subject.SessionID,
+		Scope:         scope,
+		Roles:         subject.Roles,
+		Permissions:   subject.Permissions,
+		EmailVerified: subject.EmailVerified,
+		Act:           subject.Act,
+		Cnf:           subject.Cnf,
+		GrantType:     GrantTypeTokenExchange,
+	}
+	switch {
+	case request.RequestedSubject != "" && request.ActorToken != "":
+		return nil, ErrExchangeNotAllowed
+	case request.RequestedSubject != "":
+		err = e.impersonate(ctx, claims, subject, request.RequestedSubject)
+	case request.ActorToken != "":
+		err = e.delegate(ctx, claims, request.ActorToken, request.ActorTokenType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(e.cfg.TokenExpiration).Unix()
+	if subject.ExpiresAt < expiresAt {
+		expiresAt = subject.ExpiresAt
+	}
+	claims.Id = uuid.New().String()
+	claims.Issuer = e.auth.cfg.Issuer
+	claims.Audience = audience
+	claims.IssuedAt = now.Unix()
+	claims.IssuedAtNano = now.UnixNano()
+	claims.NotBefore = now.Unix()
+	claims.ExpiresAt = expiresAt
+	accessToken, err := e.auth.signAccessToken(claims, tenant.AccessTokenKey)
+	if err != nil {
+		return nil, err
+	}
+	if client, ok := e.auth.cfg.Client(tenant, claims.ClientID); ok && client.AccessTokenFormat == config.AccessTokenFormatJWE {
+		accessToken, err = e.auth.encryptAccessToken(accessToken, audience)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ExchangeResult{
+		AccessToken:     accessToken,
+		IssuedTokenType: TokenTypeAccessToken,
+		ExpiresIn:       expiresAt - now.Unix(),
+		Scope:           scope,
+	}, nil
+}
+
+// impersonate makes claims subject the requested user acted upon by the subject token holder,
+// holder needs impersonate permission in the token tenant and requested user has to be its member
+func (e *TokenExchange) impersonate(ctx context.Context, claims, subject *Claim, requestedSubject string) error {
+	if !hasPermission(e.auth.roleStorage, subject.TenantID, subject.Subject, e.cfg.ImpersonatePermission) {
+		return ErrExchangeNotAllowed
+	}
+	user, err := e.auth.userServiceClient.GetByUsername(ctx, &userService.GetByUsernameRequest{
+		Username: requestedSubject,
+	})
+	if err != nil {
+		log.Errorf("TokenExchange / impersonate /GetByUsername err %v ", err)
+		return err
+	}
+	if err = e.auth.checkTenantMember(subject.TenantID, user.Uuid); err != nil {
+		return err
+	}
+	claims.Username = requestedSubject
+	claims.Subject = user.Uuid
+	claims.SessionID = ""
+	claims.Roles, claims.Permissions = resolveRoles(e.auth.roleStorage, subject.TenantID, user.Uuid)
+	claims.EmailVerified = e.auth.emailVerified(user.Uuid)
+	claims.Act = &ActorClaim{Subject: subject.Subject, Act: subject.Act}
+
+	return nil
+}
+
+// delegate records actor token holder as the current party acting on behalf of claims subject,
+// prior actors of the subject token are nested below it, see RFC 8693 section 4.1.
+// Actor tokens acting for someone else are refused as their chain can't be recorded.
+func (e *TokenExchange) delegate(ctx context.Context, claims *Claim, actorToken, actorTokenType string) error {
+	if !isSupportedTokenType(actorTokenType) {
+		return ErrUnsupportedTokenType
+	}
+	actor, err := e.auth.validateAccessToken(ctx, actorToken)
+	if err != nil {
+		return err
+	}
+	if !contains(e.cfg.Actors, actor.Username) || actor.Act != nil {
+		return ErrExchangeNotAllowed
+	}
+	claims.Act = &ActorClaim{Subject: actor.Subject, Act: claims.Act}
+
+	return nil
+}
+
+// audience returns audience of exchanged token, only tokens issued for the default audience can be retargeted
+func (e *TokenExchange) audience(subject *Claim, requested string) (string, error) {
+	if requested == "" {
+		return subject.Audience, nil
+	}
+	if subject.Audience != e.auth.cfg.Audience && subject.Audience != requested {
+		return "", ErrInvalidTarget
+	}
+	if !contains(e.cfg.AllowedAudiences, requested) {
+		return "", ErrInvalidTarget
+	}
+	return requested, nil
+}
+
+// downscope returns requested scope if it is a subset of the granted one
+func downscope(granted, requested string) (string, error) {
+	if requested == "" {
+		return granted, nil
+	}
+	grantedScopes := strings.Fields(granted)
+	requestedScopes := strings.Fields(requested)
+	for _, scope := range requestedScopes {
+		if !contains(grantedScopes, scope) {
+			return "", ErrInvalidScope
+		}
+	}
+	return strings.Join(requestedScopes, " "), nil
+}
+
+func isSupportedTokenType(tokenType string) bool {
+	return tokenType == TokenTypeAccessToken || tokenType == TokenTypeJWT
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
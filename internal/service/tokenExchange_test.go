@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/Entetry/userService/protocol/userService"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mockAudience     = "orders-service"
+	mockImpersonator = "support_user"
+	mockActor        = "orders_service"
+)
+
+// newTokenExchangeTestService creates TokenExchange where mockImpersonator holds support role granting
+// impersonate permission in default and acme tenants, mockUsername is a member of default tenant only
+func newTokenExchangeTestService(t *testing.T) (*TokenExchange, *Auth) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour,
+		Audience:               mockTokenAudience,
+		Tenants:                map[string]*config.Tenant{"acme": {ID: "acme"}}}
+	exchangeCfg := config.TokenExchangeConfig{
+		AllowedAudiences:      []string{mockAudience},
+		Actors:                []string{mockActor},
+		ImpersonatePermission: "users:impersonate",
+		TokenExpiration:       5 * time.Minute}
+	mockRoleStorage := mocks.NewRoleStorage(t)
+	mockRoleStorage.On("LoadUserRoles", mock.AnythingOfType("string"), "uuid-"+mockImpersonator).
+		Return([]string{"support"}).Maybe()
+	mockRoleStorage.On("LoadUserRoles", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Return(nil).Maybe()
+	mockRoleStorage.On("LoadRole", mock.AnythingOfType("string"), "support").
+		Return(&model.Role{Name: "support", Permissions: []string{"users:impersonate"}}, true).Maybe()
+	f := newAuthFixture(t, &cfg, AuthOptions{RoleStorage: mockRoleStorage})
+	f.users[mockImpersonator] = &userService.GetByUsernameResponse{Uuid: "uuid-" + mockImpersonator, Name: mockImpersonator}
+
+	return NewTokenExchangeService(&exchangeCfg, f.auth), f.auth
+}
+
+func TestTokenExchange_Exchange(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	_, subjectToken, err := auth.GenerateTokens(context.Background(), mockUsername, "orders:read orders:write")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	result, err := exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		Audience:         mockAudience,
+		Scope:            "orders:read",
+	})
+
+	assert.NoError(t, err, "Expected no error when exchanging token")
+	claims, err := auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected exchanged token to be valid")
+	assert.Equal(t, mockUsername, claims.Username, "Exchanged token subject mismatch")
+	assert.Equal(t, mockAudience, claims.Audience, "Exchanged token audience mismatch")
+	assert.Equal(t, "orders:read", claims.Scope, "Exchanged token scope mismatch")
+	assert.Equal(t, GrantTypeTokenExchange, claims.GrantType, "Expected exchanged token to be marked")
+	assert.Nil(t, claims.Act, "Expected no actor claim")
+	assert.LessOrEqual(t, result.ExpiresIn, int64((5 * time.Minute).Seconds()), "Exchanged token lifetime is not narrowed")
+
+	t.Log("exchanged token is only good for its audience")
+	_, err = auth.ValidateToken(context.Background(), result.AccessToken, ValidateOptions{Audience: mockAudience})
+	assert.NoError(t, err, "Expected no error when validating exchanged token for its audience")
+	_, err = auth.ValidateToken(context.Background(), result.AccessToken, ValidateOptions{})
+	assert.ErrorIs(t, err, ErrInvalidTokenAudience, "Expected ErrInvalidTokenAudience for service audience")
+	err = auth.SignOut(context.Background(), result.AccessToken, false)
+	assert.ErrorIs(t, err, ErrInvalidTokenAudience, "Expected exchanged token to be rejected by auth service RPCs")
+}
+
+func TestTokenExchange_Exchange_BoundToken(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	mockReplayCache := mocks.NewDPoPReplayCache(t)
+	mockReplayCache.On("Remember", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(true)
+	dpop := NewDPoPService(mockDPoPConfig, mockReplayCache)
+	key, jkt := newDPoPKey(t)
+	ctx, err := dpop.WithProof(context.Background(), newDPoPProof(t, key, "POST", mockTokenURI, time.Now(), ""),
+		"POST", mockTokenURI)
+	assert.NoError(t, err, "Expected no error when verifying proof")
+	_, subjectToken, err := auth.GenerateTokens(ctx, mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	request := &ExchangeRequest{SubjectToken: subjectToken, SubjectTokenType: TokenTypeAccessToken, Audience: mockAudience}
+	_, err = exchange.Exchange(context.Background(), request)
+	assert.ErrorIs(t, err, ErrDPoPProofRequired, "Expected ErrDPoPProofRequired for bound subject token without proof")
+
+	ctx, err = dpop.WithProof(context.Background(),
+		newDPoPProof(t, key, "POST", mockTokenURI, time.Now(), subjectToken), "POST", mockTokenURI)
+	assert.NoError(t, err, "Expected no error when verifying proof")
+	result, err := exchange.Exchange(ctx, request)
+	assert.NoError(t, err, "Expected no error when exchanging bound token")
+	claims, err := auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected exchanged token to be valid")
+	assert.Equal(t, &Confirmation{JKT: jkt}, claims.Cnf, "Expected exchanged token bound to the same DPoP key")
+}
+
+func TestTokenExchange_Exchange_InvalidTarget(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	_, subjectToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	_, err = exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		Audience:         "billing-service",
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidTarget, "Expected ErrInvalidTarget for not allowed audience")
+}
+
+func TestTokenExchange_Exchange_InvalidScope(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	_, unscoped, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	_, err = exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     unscoped,
+		SubjectTokenType: TokenTypeAccessToken,
+		Scope:            "orders:read",
+	})
+	assert.ErrorIs(t, err, ErrInvalidScope, "Expected ErrInvalidScope when token without scope requests one")
+	_, subjectToken, err := auth.GenerateTokens(context.Background(), mockUsername, "orders:read orders:write")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	narrowed, err := exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		Scope:            "orders:read",
+	})
+	assert.NoError(t, err, "Expected no error when exchanging token")
+
+	_, err = exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     narrowed.AccessToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		Scope:            "orders:read orders:write",
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidScope, "Expected ErrInvalidScope when widening scope")
+}
+
+func TestTokenExchange_Exchange_Impersonation(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	_, subjectToken, err := auth.GenerateTokens(context.Background(), mockImpersonator, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	result, err := exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		RequestedSubject: mockUsername,
+	})
+
+	assert.NoError(t, err, "Expected no error when impersonating user")
+	claims, err := auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected exchanged token to be valid")
+	assert.Equal(t, mockUsername, claims.Username, "Impersonated token subject mismatch")
+	assert.Equal(t, mockUserID, claims.Subject, "Impersonated token subject mismatch")
+	assert.Equal(t, &ActorClaim{Subject: "uuid-" + mockImpersonator}, claims.Act, "Impersonated token actor mismatch")
+}
+
+func TestTokenExchange_Exchange_ImpersonationNotAllowed(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	_, subjectToken, err := auth.GenerateTokens(context.Background(), mockUsername, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	_, err = exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		RequestedSubject: mockImpersonator,
+	})
+
+	assert.ErrorIs(t, err, ErrExchangeNotAllowed, "Expected ErrExchangeNotAllowed for not allowed impersonator")
+}
+
+func TestTokenExchange_Exchange_ImpersonationOtherTenant(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	acme := WithTenant(context.Background(), "acme")
+	_, subjectToken, err := auth.GenerateTokens(acme, mockImpersonator, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+
+	_, err = exchange.Exchange(acme, &ExchangeRequest{
+		SubjectToken:     subjectToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		RequestedSubject: mockUsername,
+	})
+
+	assert.ErrorIs(t, err, ErrNotTenantMember, "Expected ErrNotTenantMember when impersonating user of other tenant")
+}
+
+func TestTokenExchange_Exchange_Delegation(t *testing.T) {
+	exchange, auth := newTokenExchangeTestService(t)
+	actorToken := newTestAccessToken(t, auth, &Claim{Username: mockActor,
+		StandardClaims: jwt.StandardClaims{Subject: "uuid-" + mockActor, Audience: mockTokenAudience}})
+	_, impersonatorToken, err := auth.GenerateTokens(context.Background(), mockImpersonator, "")
+	assert.NoError(t, err, "Expected no error when generating tokens")
+	impersonated, err := exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     impersonatorToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		RequestedSubject: mockUsername,
+	})
+	assert.NoError(t, err, "Expected no error when impersonating user")
+
+	result, err := exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     impersonated.AccessToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		ActorToken:       actorToken,
+		ActorTokenType:   TokenTypeAccessToken,
+	})
+
+	assert.NoError(t, err, "Expected no error when delegating")
+	claims, err := auth.parseAccessToken(context.Background(), result.AccessToken)
+	assert.NoError(t, err, "Expected exchanged token to be valid")
+	assert.Equal(t, mockUserID, claims.Subject, "Delegated token subject mismatch")
+	assert.Equal(t, &ActorClaim{Subject: "uuid-" + mockActor, Act: &ActorClaim{Subject: "uuid-" + mockImpersonator}}, claims.Act,
+		"Expected prior actor of subject token nested below current actor")
+
+	t.Log("actor token acting for someone else is refused")
+	actorToken = newTestAccessToken(t, auth, &Claim{Username: mockActor, Act: &ActorClaim{Subject: "uuid-" + mockImpersonator},
+		StandardClaims: jwt.StandardClaims{Subject: "uuid-" + mockActor, Audience: mockTokenAudience}})
+	_, err = exchange.Exchange(context.Background(), &ExchangeRequest{
+		SubjectToken:     impersonated.AccessToken,
+		SubjectTokenType: TokenTypeAccessToken,
+		ActorToken:       actorToken,
+		ActorTokenType:   TokenTypeAccessToken,
+	})
+	assert.ErrorIs(t, err, ErrExchangeNotAllowed, "Expected ErrExchangeNotAllowed for actor token with act claim")
+}
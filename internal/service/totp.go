@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 -- RFC 6238 authenticator apps use HMAC-SHA-1
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+)
+
+// TOTP parameters understood by common authenticator apps, see RFC 6238
+const (
+	totpSecretSize = 20
+	totpDigits     = 6
+	totpPeriod     = 30
+	totpSkew       = 1
+)
+
+var (
+	// ErrTOTPAlreadyEnrolled godoc
+	ErrTOTPAlreadyEnrolled = errors.New("authenticator app is already enrolled")
+	// ErrInvalidTOTPSecret godoc
+	ErrInvalidTOTPSecret = errors.New("invalid authenticator app secret")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPSecretStorage used to store authenticator app secrets of users
+type TOTPSecretStorage interface {
+	Load(userID string) (*model.TOTPSecret, bool)
+	Save(userID string, secret *model.TOTPSecret) error
+	UseStep(userID string, step int64) (bool, error)
+}
+
+// TOTPEnrollment authenticator app secret and the otpauth URI to show it as QR code
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// TOTP time-based one-time password second factor service struct
+type TOTP struct {
+	cfg     *config.StepUpConfig
+	auth    *Auth
+	storage TOTPSecretStorage
+}
+
+// NewTOTPService creates new TOTP service
+func NewTOTPService(cfg *config.StepUpConfig, auth *Auth, storage TOTPSecretStorage) *TOTP {
+	return &TOTP{cfg: cfg, auth: auth, storage: storage}
+}
+
+// Enroll generates authenticator app secret for user of recently authenticated access token.
+// Secret is not stored until Activate proves the app generates matching codes.
+func (t *TOTP) Enroll(ctx context.Context, accessToken string) (*TOTPEnrollment, error) {
+	claims, err := t.enrollingUser(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, totpSecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+	encoded := totpEncoding.EncodeToString(secret)
+	label := url.PathEscape(t.cfg.TOTPIssuer + ":" + claims.Username)
+	query := url.Values{"secret": {encoded}, "issuer": {t.cfg.TOTPIssuer}}
+	return &TOTPEnrollment{
+		Secret: encoded,
+		URI:    fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode()),
+	}, nil
+}
+
+// Activate stores secret returned by Enroll once code generated by authenticator app matches it
+func (t *TOTP) Activate(ctx context.Context, accessToken, secret, code string) error {
+	claims, err := t.enrollingUser(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) < totpSecretSize {
+		return ErrInvalidTOTPSecret
+	}
+	step, ok := matchTOTP(key, code, time.Now())
+	if !ok {
+		return ErrInvalidSecondFactor
+	}
+	return t.storage.Save(claims.Subject, &model.TOTPSecret{Secret: secret, LastStep: step})
+}
+
+// Verify checks one-time password generated by user authenticator app, each code is accepted once
+func (t *TOTP) Verify(_ context.Context, userID, method, credential string) error {
+	if method != AuthMethodOTP {
+		return ErrUnsupportedAuthMethod
+	}
+	secret, ok := t.storage.Load(userID)
+	if !ok {
+		return ErrInvalidSecondFactor
+	}
+	key, err := totpEncoding.DecodeString(secret.Secret)
+	if err != nil {
+		return err
+	}
+	step, ok := matchTOTP(key, credential, time.Now())
+	if !ok {
+		return ErrInvalidSecondFactor
+	}
+	used, err := t.storage.UseStep(userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidSecondFactor
+	}
+	return nil
+}
+
+// enrollingUser validates access token of user enrolling authenticator app, the token must be recently
+// authenticated and the user must not have an app yet, so a stolen token can't replace the second factor
+func (t *TOTP) enrollingUser(ctx context.Context, accessToken string) (*Claim, error) {
+	claims, err := t.auth.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(time.Unix(claims.AuthTime, 0)) > t.cfg.EnrollMaxAuthAge {
+		return nil, ErrAuthenticationTooOld
+	}
+	if _, ok := t.storage.Load(claims.Subject); ok {
+		return nil, ErrTOTPAlreadyEnrolled
+	}
+	return claims, nil
+}
+
+// matchTOTP returns time step of code if it matches key within allowed clock skew
+func matchTOTP(key []byte, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode returns code of time step, see RFC 4226 section 5.3
+func totpCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%uint32(math.Pow10(totpDigits)))
+}
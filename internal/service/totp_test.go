@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/model"
+	"github.com/Entetry/authService/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTOTPTestService(t *testing.T) (*TOTP, *authFixture, map[string]*model.TOTPSecret) {
+	cfg := config.JwtConfig{
+		AccessTokenKey:         mockAccessTokenKey,
+		AccessTokenExpiration:  30 * time.Minute,
+		RefreshTokenExpiration: 24 * time.Hour}
+	f := newAuthFixture(t, &cfg, AuthOptions{})
+	secrets := make(map[string]*model.TOTPSecret)
+	mockStorage := mocks.NewTOTPSecretStorage(t)
+	mockStorage.On("Load", mock.AnythingOfType("string")).Return(
+		func(userID string) *model.TOTPSecret { return secrets[userID] },
+		func(userID string) bool {
+			_, ok := secrets[userID]
+			return ok
+		}).Maybe()
+	mockStorage.On("Save", mock.AnythingOfType("string"), mock.AnythingOfType("*model.TOTPSecret")).
+		Run(func(args mock.Arguments) { secrets[args.String(0)] = args.Get(1).(*model.TOTPSecret) }).Return(nil).Maybe()
+	mockStorage.On("UseStep", mock.AnythingOfType("string"), mock.AnythingOfType("int64")).Return(
+		func(userID string, step int64) bool {
+			if step <= secrets[userID].LastStep {
+				return false
+			}
+			secrets[userID].LastStep = step
+			return true
+		}, nil).Maybe()
+	stepUpCfg := &config.StepUpConfig{TOTPIssuer: "authService", EnrollMaxAuthAge: 10 * time.Minute}
+
+	return NewTOTPService(stepUpCfg, f.auth, mockStorage), f, secrets
+}
+
+// TestTOTPCode checks codes against the SHA-1 test vectors of RFC 6238 appendix B truncated to 6 digits
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{time: 59, code: "287082"},
+		{time: 1111111109, code: "081804"},
+		{time: 1111111111, code: "050471"},
+		{time: 1234567890, code: "005924"},
+		{time: 2000000000, code: "279037"},
+		{time: 20000000000, code: "353130"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, totpCode(key, tt.time/totpPeriod), "Code mismatch at time %d", tt.time)
+	}
+}
+
+func TestTOTP_EnrollActivateVerify(t *testing.T) {
+	totp, f, secrets := newTOTPTestService(t)
+	ctx := context.Background()
+	accessToken := newTestAccessToken(t, f.auth, &Claim{AuthTime: time.Now().Unix()})
+
+	t.Log("Step 1: enrollment returns secret without storing it")
+	enrollment, err := totp.Enroll(ctx, accessToken)
+	assert.NoError(t, err, "Expected no error when enrolling")
+	uri, err := url.Parse(enrollment.URI)
+	assert.NoError(t, err, "Expected otpauth URI to parse")
+	assert.Equal(t, "otpauth", uri.Scheme, "URI scheme mismatch")
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"), "URI secret mismatch")
+	assert.Empty(t, secrets, "Secret must not be stored before activation")
+
+	t.Log("Step 2: activation requires code matching the secret")
+	key, err := totpEncoding.DecodeString(enrollment.Secret)
+	assert.NoError(t, err, "Expected secret to be base32")
+	now := time.Now()
+	err = totp.Activate(ctx, accessToken, enrollment.Secret, "not-a-code")
+	assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected ErrInvalidSecondFactor for wrong code")
+	err = totp.Activate(ctx, accessToken, "short", totpCode(key, now.Unix()/totpPeriod))
+	assert.ErrorIs(t, err, ErrInvalidTOTPSecret, "Expected ErrInvalidTOTPSecret for malformed secret")
+	err = totp.Activate(ctx, accessToken, enrollment.Secret, totpCode(key, now.Unix()/totpPeriod-1))
+	assert.NoError(t, err, "Expected no error when activating with valid code")
+	assert.Contains(t, secrets, mockUserID, "Secret was not stored")
+	_, err = totp.Enroll(ctx, accessToken)
+	assert.ErrorIs(t, err, ErrTOTPAlreadyEnrolled, "Expected ErrTOTPAlreadyEnrolled once app is active")
+
+	t.Log("Step 3: codes are accepted once and never before the activation code")
+	err = totp.Verify(ctx, mockUserID, AuthMethodOTP, totpCode(key, now.Unix()/totpPeriod-1))
+	assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected activation code to be used up")
+	err = totp.Verify(ctx, mockUserID, AuthMethodOTP, totpCode(key, now.Unix()/totpPeriod))
+	assert.NoError(t, err, "Expected no error for current code")
+	err = totp.Verify(ctx, mockUserID, AuthMethodOTP, totpCode(key, now.Unix()/totpPeriod))
+	assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected replayed code to be rejected")
+	err = totp.Verify(ctx, mockUserID, AuthMethodWebAuthn, "assertion")
+	assert.ErrorIs(t, err, ErrUnsupportedAuthMethod, "Expected ErrUnsupportedAuthMethod for webauthn")
+	err = totp.Verify(ctx, "other-user", AuthMethodOTP, totpCode(key, now.Unix()/totpPeriod+1))
+	assert.ErrorIs(t, err, ErrInvalidSecondFactor, "Expected ErrInvalidSecondFactor for user without app")
+}
+
+func TestTOTP_Enroll_OldAuthentication(t *testing.T) {
+	totp, f, _ := newTOTPTestService(t)
+	accessToken := newTestAccessToken(t, f.auth, &Claim{AuthTime: time.Now().Add(-time.Hour).Unix()})
+
+	_, err := totp.Enroll(context.Background(), accessToken)
+
+	assert.ErrorIs(t, err, ErrAuthenticationTooOld, "Expected ErrAuthenticationTooOld for old authentication")
+}
@@ -3,21 +3,29 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/Entetry/authService/internal/audit"
 	"github.com/Entetry/authService/internal/config"
+	"github.com/Entetry/authService/internal/directory"
 	"github.com/Entetry/authService/internal/handler"
+	"github.com/Entetry/authService/internal/notify"
+	"github.com/Entetry/authService/internal/policy"
 	"github.com/Entetry/authService/internal/repository"
 	"github.com/Entetry/authService/internal/service"
 	"github.com/Entetry/authService/protocol/authService"
 	"github.com/Entetry/userService/protocol/userService"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -26,11 +34,67 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	serverTLS, err := cfg.ServerTLS()
+	if err != nil {
+		log.Fatal(err)
+	}
 	jwtCfg, err := config.NewJwtConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
-	_, cancel := context.WithCancel(context.Background())
+	tokenExchangeCfg, err := config.NewTokenExchangeConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rbacCfg, err := config.NewRBACConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	authorizationCfg, err := config.NewAuthorizationConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	stepUpCfg, err := config.NewStepUpConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	smtpCfg, err := config.NewSMTPConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	emailVerificationCfg, err := config.NewEmailVerificationConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	passwordlessCfg, err := config.NewPasswordlessConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	federationCfg, err := config.NewFederationConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	samlCfg, err := config.NewSAMLConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ldapCfg, err := config.NewLDAPConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiKeyCfg, err := config.NewAPIKeyConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	dpopCfg, err := config.NewDPoPConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokenReviewCfg, err := config.NewTokenReviewConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM)
@@ -50,14 +114,124 @@ func main() {
 		}
 	}()
 
+	realms := make([]service.CredentialRealm, 0, len(ldapCfg.Realms))
+	for _, realm := range ldapCfg.Realms {
+		verifier := directory.NewLDAP(realm)
+		defer verifier.Close()
+		realms = append(realms, service.CredentialRealm{Name: realm.Name, Pattern: realm.Pattern, Verifier: verifier})
+	}
+	credentialVerifier := service.NewRealmVerifier(service.NewUserServiceVerifier(userServiceClient), realms...)
+
 	sessionStorage := repository.NewRefreshSessionStorage(&sync.Map{})
-	authSvc := service.NewAuthService(jwtCfg, sessionStorage, userServiceClient)
-	authHandler := handler.NewAuth(authSvc)
-	grpcServer := grpc.NewServer()
+	tokenDenylist := repository.NewAccessTokenDenylist(
+		&sync.Map{}, &sync.Map{}, jwtCfg.RevokedTokensCapacity, jwtCfg.RevokedTokensFalsePositiveRate)
+	go tokenDenylist.RunCleanup(ctx, jwtCfg.RevokedTokensCleanupInterval)
+	roleStorage := repository.NewRoleStorage(&sync.Map{}, &sync.Map{})
+	auditLogger := audit.NewLogger(log.StandardLogger())
+	if jwtCfg.RequireVerifiedEmail && emailVerificationCfg.StorageFile == "" {
+		log.Fatal("REQUIRE_VERIFIED_EMAIL needs EMAIL_VERIFICATION_FILE, verified users would be locked out after restart")
+	}
+	emailVerificationStorage, err := repository.NewEmailVerificationStorage(emailVerificationCfg.StorageFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	referenceTokenStorage := repository.NewReferenceTokenStorage(&sync.Map{})
+	go referenceTokenStorage.RunCleanup(ctx, jwtCfg.ReferenceTokenCleanupInterval)
+	apiKeyStorage := repository.NewAPIKeyStorage(&sync.Map{})
+	authSvc, err := service.NewAuthService(jwtCfg, sessionStorage, userServiceClient, service.AuthOptions{
+		TokenDenylist:            tokenDenylist,
+		RoleStorage:              roleStorage,
+		EmailVerificationStorage: emailVerificationStorage,
+		AuditLogger:              auditLogger,
+		CredentialVerifier:       credentialVerifier,
+		ReferenceTokenStorage:    referenceTokenStorage,
+		APIKeyStorage:            apiKeyStorage,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokenExchangeSvc := service.NewTokenExchangeService(tokenExchangeCfg, authSvc)
+	rbacSvc := service.NewRBACService(rbacCfg, roleStorage, authSvc, userServiceClient)
+	decisionCache := repository.NewDecisionCache(&sync.Map{})
+	go decisionCache.RunCleanup(ctx, authorizationCfg.DecisionCacheCleanupInterval)
+	policyEngine, err := policy.NewEngine(authorizationCfg.PolicyDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go policyEngine.RunReload(ctx, authorizationCfg.PolicyReloadInterval)
+	authorizerSvc := service.NewAuthorizerService(authorizationCfg, decisionCache, policyEngine, authSvc)
+	totpSecretStorage, err := repository.NewTOTPSecretStorage(stepUpCfg.TOTPSecretsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	totpSvc := service.NewTOTPService(stepUpCfg, authSvc, totpSecretStorage)
+	stepUpAttempts := repository.NewAttemptCounter(&sync.Map{})
+	go stepUpAttempts.RunCleanup(ctx, stepUpCfg.CleanupInterval)
+	stepUpSvc := service.NewStepUpService(stepUpCfg, authSvc, totpSvc, stepUpAttempts)
+	mailer := notify.NewSMTPMailer(smtpCfg)
+	emailVerificationSvc := service.NewEmailVerificationService(emailVerificationCfg, authSvc, mailer)
+	passwordlessStorage := repository.NewPasswordlessStorage(&sync.Map{})
+	go passwordlessStorage.RunCleanup(ctx, passwordlessCfg.CleanupInterval)
+	passwordlessSvc := service.NewPasswordlessService(passwordlessCfg, authSvc, passwordlessStorage, mailer)
+	federationStateStorage := repository.NewFederationStateStorage(&sync.Map{})
+	go federationStateStorage.RunCleanup(ctx, federationCfg.CleanupInterval)
+	federatedIdentityStorage := repository.NewFederatedIdentityStorage(&sync.Map{})
+	federationSvc := service.NewFederationService(federationCfg, authSvc, federationStateStorage,
+		federatedIdentityStorage)
+	samlRequestStorage := repository.NewSAMLRequestStorage(&sync.Map{})
+	go samlRequestStorage.RunCleanup(ctx, samlCfg.CleanupInterval)
+	samlAssertionCache := repository.NewSAMLAssertionCache(&sync.Map{})
+	go samlAssertionCache.RunCleanup(ctx, samlCfg.CleanupInterval)
+	samlSvc := service.NewSAMLService(samlCfg, authSvc, samlRequestStorage, samlAssertionCache,
+		federatedIdentityStorage)
+	apiKeySvc := service.NewAPIKeyService(apiKeyCfg, authSvc, apiKeyStorage)
+	dpopReplayCache := repository.NewDPoPReplayCache(&sync.Map{})
+	go dpopReplayCache.RunCleanup(ctx, dpopCfg.CleanupInterval)
+	dpopSvc := service.NewDPoPService(dpopCfg, dpopReplayCache)
+	authHandler := handler.NewAuth(authSvc, handler.Services{
+		TokenExchange:     tokenExchangeSvc,
+		RBAC:              rbacSvc,
+		Authorizer:        authorizerSvc,
+		StepUp:            stepUpSvc,
+		TOTP:              totpSvc,
+		EmailVerification: emailVerificationSvc,
+		Passwordless:      passwordlessSvc,
+		Federation:        federationSvc,
+		SAML:              samlSvc,
+		APIKeys:           apiKeySvc,
+		DPoP:              dpopSvc,
+	})
+	serverOptions := []grpc.ServerOption{grpc.ChainUnaryInterceptor(handler.TenantInterceptor(authSvc),
+		handler.CertificateInterceptor, handler.DPoPInterceptor(dpopSvc), handler.ClientInterceptor(authSvc))}
+	if serverTLS != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(serverTLS)))
+	}
+	grpcServer := grpc.NewServer(serverOptions...)
 	authService.RegisterAuthGRPCServiceServer(grpcServer, authHandler)
+	var tokenReviewServer *http.Server
+	if tokenReviewCfg.Port != 0 {
+		tokenReviewTLS, err := tokenReviewCfg.ServerTLS(serverTLS)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(tokenReviewCfg.Path, handler.NewTokenReviewWebhook(tokenReviewCfg, authSvc))
+		tokenReviewServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", tokenReviewCfg.Port),
+			Handler:           mux,
+			TLSConfig:         tokenReviewTLS,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go serveTokenReview(tokenReviewServer)
+	}
 	go func() {
 		<-sigChan
 		cancel()
+		if tokenReviewServer != nil {
+			if err := tokenReviewServer.Shutdown(context.Background()); err != nil {
+				log.Errorf("can't stop TokenReview webhook gracefully %v", err)
+			}
+		}
 		grpcServer.GracefulStop()
 		if err != nil {
 			log.Errorf("can't stop server gracefully %v", err)
@@ -74,3 +248,12 @@ func main() {
 		return
 	}
 }
+
+// serveTokenReview serves Kubernetes TokenReview webhook over TLS
+func serveTokenReview(server *http.Server) {
+	log.Info("TokenReview webhook started on ", server.Addr)
+	err := server.ListenAndServeTLS("", "")
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Errorf("failed to serve TokenReview webhook: %v", err)
+	}
+}
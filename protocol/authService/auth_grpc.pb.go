@@ -20,11 +20,37 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AuthGRPCService_ValidateTokens_FullMethodName = "/proto.AuthGRPCService/ValidateTokens"
-	AuthGRPCService_GenerateTokens_FullMethodName = "/proto.AuthGRPCService/GenerateTokens"
-	AuthGRPCService_RefreshTokens_FullMethodName  = "/proto.AuthGRPCService/RefreshTokens"
-	AuthGRPCService_SignUp_FullMethodName         = "/proto.AuthGRPCService/SignUp"
-	AuthGRPCService_SignIn_FullMethodName         = "/proto.AuthGRPCService/SignIn"
+	AuthGRPCService_ValidateTokens_FullMethodName         = "/proto.AuthGRPCService/ValidateTokens"
+	AuthGRPCService_GenerateTokens_FullMethodName         = "/proto.AuthGRPCService/GenerateTokens"
+	AuthGRPCService_RefreshTokens_FullMethodName          = "/proto.AuthGRPCService/RefreshTokens"
+	AuthGRPCService_SignUp_FullMethodName                 = "/proto.AuthGRPCService/SignUp"
+	AuthGRPCService_SignIn_FullMethodName                 = "/proto.AuthGRPCService/SignIn"
+	AuthGRPCService_RevokeAccessToken_FullMethodName      = "/proto.AuthGRPCService/RevokeAccessToken"
+	AuthGRPCService_SignOut_FullMethodName                = "/proto.AuthGRPCService/SignOut"
+	AuthGRPCService_SignOutEverywhere_FullMethodName      = "/proto.AuthGRPCService/SignOutEverywhere"
+	AuthGRPCService_ExchangeToken_FullMethodName          = "/proto.AuthGRPCService/ExchangeToken"
+	AuthGRPCService_PutRole_FullMethodName                = "/proto.AuthGRPCService/PutRole"
+	AuthGRPCService_DeleteRole_FullMethodName             = "/proto.AuthGRPCService/DeleteRole"
+	AuthGRPCService_ListRoles_FullMethodName              = "/proto.AuthGRPCService/ListRoles"
+	AuthGRPCService_AssignRole_FullMethodName             = "/proto.AuthGRPCService/AssignRole"
+	AuthGRPCService_UnassignRole_FullMethodName           = "/proto.AuthGRPCService/UnassignRole"
+	AuthGRPCService_ListUserRoles_FullMethodName          = "/proto.AuthGRPCService/ListUserRoles"
+	AuthGRPCService_Authorize_FullMethodName              = "/proto.AuthGRPCService/Authorize"
+	AuthGRPCService_StepUp_FullMethodName                 = "/proto.AuthGRPCService/StepUp"
+	AuthGRPCService_VerifyEmail_FullMethodName            = "/proto.AuthGRPCService/VerifyEmail"
+	AuthGRPCService_ResendVerification_FullMethodName     = "/proto.AuthGRPCService/ResendVerification"
+	AuthGRPCService_StartPasswordless_FullMethodName      = "/proto.AuthGRPCService/StartPasswordless"
+	AuthGRPCService_CompletePasswordless_FullMethodName   = "/proto.AuthGRPCService/CompletePasswordless"
+	AuthGRPCService_BeginFederatedLogin_FullMethodName    = "/proto.AuthGRPCService/BeginFederatedLogin"
+	AuthGRPCService_CompleteFederatedLogin_FullMethodName = "/proto.AuthGRPCService/CompleteFederatedLogin"
+	AuthGRPCService_GetSAMLMetadata_FullMethodName        = "/proto.AuthGRPCService/GetSAMLMetadata"
+	AuthGRPCService_BeginSAMLLogin_FullMethodName         = "/proto.AuthGRPCService/BeginSAMLLogin"
+	AuthGRPCService_CompleteSAMLLogin_FullMethodName      = "/proto.AuthGRPCService/CompleteSAMLLogin"
+	AuthGRPCService_CreateAPIKey_FullMethodName           = "/proto.AuthGRPCService/CreateAPIKey"
+	AuthGRPCService_ListAPIKeys_FullMethodName            = "/proto.AuthGRPCService/ListAPIKeys"
+	AuthGRPCService_RevokeAPIKey_FullMethodName           = "/proto.AuthGRPCService/RevokeAPIKey"
+	AuthGRPCService_EnrollTOTP_FullMethodName             = "/proto.AuthGRPCService/EnrollTOTP"
+	AuthGRPCService_ActivateTOTP_FullMethodName           = "/proto.AuthGRPCService/ActivateTOTP"
 )
 
 // AuthGRPCServiceClient is the client API for AuthGRPCService service.
@@ -36,6 +62,32 @@ type AuthGRPCServiceClient interface {
 	RefreshTokens(ctx context.Context, in *RefreshTokensRequest, opts ...grpc.CallOption) (*RefreshTokensResponse, error)
 	SignUp(ctx context.Context, in *SignUpRequest, opts ...grpc.CallOption) (*SignUpResponse, error)
 	SignIn(ctx context.Context, in *SignInRequest, opts ...grpc.CallOption) (*SignInResponse, error)
+	RevokeAccessToken(ctx context.Context, in *RevokeAccessTokenRequest, opts ...grpc.CallOption) (*RevokeAccessTokenResponse, error)
+	SignOut(ctx context.Context, in *SignOutRequest, opts ...grpc.CallOption) (*SignOutResponse, error)
+	SignOutEverywhere(ctx context.Context, in *SignOutEverywhereRequest, opts ...grpc.CallOption) (*SignOutEverywhereResponse, error)
+	ExchangeToken(ctx context.Context, in *ExchangeTokenRequest, opts ...grpc.CallOption) (*ExchangeTokenResponse, error)
+	PutRole(ctx context.Context, in *PutRoleRequest, opts ...grpc.CallOption) (*PutRoleResponse, error)
+	DeleteRole(ctx context.Context, in *DeleteRoleRequest, opts ...grpc.CallOption) (*DeleteRoleResponse, error)
+	ListRoles(ctx context.Context, in *ListRolesRequest, opts ...grpc.CallOption) (*ListRolesResponse, error)
+	AssignRole(ctx context.Context, in *AssignRoleRequest, opts ...grpc.CallOption) (*AssignRoleResponse, error)
+	UnassignRole(ctx context.Context, in *UnassignRoleRequest, opts ...grpc.CallOption) (*UnassignRoleResponse, error)
+	ListUserRoles(ctx context.Context, in *ListUserRolesRequest, opts ...grpc.CallOption) (*ListUserRolesResponse, error)
+	Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error)
+	StepUp(ctx context.Context, in *StepUpRequest, opts ...grpc.CallOption) (*StepUpResponse, error)
+	VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*VerifyEmailResponse, error)
+	ResendVerification(ctx context.Context, in *ResendVerificationRequest, opts ...grpc.CallOption) (*ResendVerificationResponse, error)
+	StartPasswordless(ctx context.Context, in *StartPasswordlessRequest, opts ...grpc.CallOption) (*StartPasswordlessResponse, error)
+	CompletePasswordless(ctx context.Context, in *CompletePasswordlessRequest, opts ...grpc.CallOption) (*CompletePasswordlessResponse, error)
+	BeginFederatedLogin(ctx context.Context, in *BeginFederatedLoginRequest, opts ...grpc.CallOption) (*BeginFederatedLoginResponse, error)
+	CompleteFederatedLogin(ctx context.Context, in *CompleteFederatedLoginRequest, opts ...grpc.CallOption) (*CompleteFederatedLoginResponse, error)
+	GetSAMLMetadata(ctx context.Context, in *GetSAMLMetadataRequest, opts ...grpc.CallOption) (*GetSAMLMetadataResponse, error)
+	BeginSAMLLogin(ctx context.Context, in *BeginSAMLLoginRequest, opts ...grpc.CallOption) (*BeginSAMLLoginResponse, error)
+	CompleteSAMLLogin(ctx context.Context, in *CompleteSAMLLoginRequest, opts ...grpc.CallOption) (*CompleteSAMLLoginResponse, error)
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error)
+	EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*EnrollTOTPResponse, error)
+	ActivateTOTP(ctx context.Context, in *ActivateTOTPRequest, opts ...grpc.CallOption) (*ActivateTOTPResponse, error)
 }
 
 type authGRPCServiceClient struct {
@@ -91,6 +143,240 @@ func (c *authGRPCServiceClient) SignIn(ctx context.Context, in *SignInRequest, o
 	return out, nil
 }
 
+func (c *authGRPCServiceClient) RevokeAccessToken(ctx context.Context, in *RevokeAccessTokenRequest, opts ...grpc.CallOption) (*RevokeAccessTokenResponse, error) {
+	out := new(RevokeAccessTokenResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_RevokeAccessToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) SignOut(ctx context.Context, in *SignOutRequest, opts ...grpc.CallOption) (*SignOutResponse, error) {
+	out := new(SignOutResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_SignOut_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) SignOutEverywhere(ctx context.Context, in *SignOutEverywhereRequest, opts ...grpc.CallOption) (*SignOutEverywhereResponse, error) {
+	out := new(SignOutEverywhereResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_SignOutEverywhere_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) ExchangeToken(ctx context.Context, in *ExchangeTokenRequest, opts ...grpc.CallOption) (*ExchangeTokenResponse, error) {
+	out := new(ExchangeTokenResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_ExchangeToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) PutRole(ctx context.Context, in *PutRoleRequest, opts ...grpc.CallOption) (*PutRoleResponse, error) {
+	out := new(PutRoleResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_PutRole_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) DeleteRole(ctx context.Context, in *DeleteRoleRequest, opts ...grpc.CallOption) (*DeleteRoleResponse, error) {
+	out := new(DeleteRoleResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_DeleteRole_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) ListRoles(ctx context.Context, in *ListRolesRequest, opts ...grpc.CallOption) (*ListRolesResponse, error) {
+	out := new(ListRolesResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_ListRoles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) AssignRole(ctx context.Context, in *AssignRoleRequest, opts ...grpc.CallOption) (*AssignRoleResponse, error) {
+	out := new(AssignRoleResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_AssignRole_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) UnassignRole(ctx context.Context, in *UnassignRoleRequest, opts ...grpc.CallOption) (*UnassignRoleResponse, error) {
+	out := new(UnassignRoleResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_UnassignRole_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) ListUserRoles(ctx context.Context, in *ListUserRolesRequest, opts ...grpc.CallOption) (*ListUserRolesResponse, error) {
+	out := new(ListUserRolesResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_ListUserRoles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error) {
+	out := new(AuthorizeResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_Authorize_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) StepUp(ctx context.Context, in *StepUpRequest, opts ...grpc.CallOption) (*StepUpResponse, error) {
+	out := new(StepUpResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_StepUp_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*VerifyEmailResponse, error) {
+	out := new(VerifyEmailResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_VerifyEmail_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) ResendVerification(ctx context.Context, in *ResendVerificationRequest, opts ...grpc.CallOption) (*ResendVerificationResponse, error) {
+	out := new(ResendVerificationResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_ResendVerification_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) StartPasswordless(ctx context.Context, in *StartPasswordlessRequest, opts ...grpc.CallOption) (*StartPasswordlessResponse, error) {
+	out := new(StartPasswordlessResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_StartPasswordless_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) CompletePasswordless(ctx context.Context, in *CompletePasswordlessRequest, opts ...grpc.CallOption) (*CompletePasswordlessResponse, error) {
+	out := new(CompletePasswordlessResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_CompletePasswordless_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) BeginFederatedLogin(ctx context.Context, in *BeginFederatedLoginRequest, opts ...grpc.CallOption) (*BeginFederatedLoginResponse, error) {
+	out := new(BeginFederatedLoginResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_BeginFederatedLogin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) CompleteFederatedLogin(ctx context.Context, in *CompleteFederatedLoginRequest, opts ...grpc.CallOption) (*CompleteFederatedLoginResponse, error) {
+	out := new(CompleteFederatedLoginResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_CompleteFederatedLogin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) GetSAMLMetadata(ctx context.Context, in *GetSAMLMetadataRequest, opts ...grpc.CallOption) (*GetSAMLMetadataResponse, error) {
+	out := new(GetSAMLMetadataResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_GetSAMLMetadata_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) BeginSAMLLogin(ctx context.Context, in *BeginSAMLLoginRequest, opts ...grpc.CallOption) (*BeginSAMLLoginResponse, error) {
+	out := new(BeginSAMLLoginResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_BeginSAMLLogin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) CompleteSAMLLogin(ctx context.Context, in *CompleteSAMLLoginRequest, opts ...grpc.CallOption) (*CompleteSAMLLoginResponse, error) {
+	out := new(CompleteSAMLLoginResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_CompleteSAMLLogin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error) {
+	out := new(CreateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_CreateAPIKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error) {
+	out := new(ListAPIKeysResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_ListAPIKeys_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error) {
+	out := new(RevokeAPIKeyResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_RevokeAPIKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*EnrollTOTPResponse, error) {
+	out := new(EnrollTOTPResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_EnrollTOTP_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authGRPCServiceClient) ActivateTOTP(ctx context.Context, in *ActivateTOTPRequest, opts ...grpc.CallOption) (*ActivateTOTPResponse, error) {
+	out := new(ActivateTOTPResponse)
+	err := c.cc.Invoke(ctx, AuthGRPCService_ActivateTOTP_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthGRPCServiceServer is the server API for AuthGRPCService service.
 // All implementations must embed UnimplementedAuthGRPCServiceServer
 // for forward compatibility
@@ -100,6 +386,32 @@ type AuthGRPCServiceServer interface {
 	RefreshTokens(context.Context, *RefreshTokensRequest) (*RefreshTokensResponse, error)
 	SignUp(context.Context, *SignUpRequest) (*SignUpResponse, error)
 	SignIn(context.Context, *SignInRequest) (*SignInResponse, error)
+	RevokeAccessToken(context.Context, *RevokeAccessTokenRequest) (*RevokeAccessTokenResponse, error)
+	SignOut(context.Context, *SignOutRequest) (*SignOutResponse, error)
+	SignOutEverywhere(context.Context, *SignOutEverywhereRequest) (*SignOutEverywhereResponse, error)
+	ExchangeToken(context.Context, *ExchangeTokenRequest) (*ExchangeTokenResponse, error)
+	PutRole(context.Context, *PutRoleRequest) (*PutRoleResponse, error)
+	DeleteRole(context.Context, *DeleteRoleRequest) (*DeleteRoleResponse, error)
+	ListRoles(context.Context, *ListRolesRequest) (*ListRolesResponse, error)
+	AssignRole(context.Context, *AssignRoleRequest) (*AssignRoleResponse, error)
+	UnassignRole(context.Context, *UnassignRoleRequest) (*UnassignRoleResponse, error)
+	ListUserRoles(context.Context, *ListUserRolesRequest) (*ListUserRolesResponse, error)
+	Authorize(context.Context, *AuthorizeRequest) (*AuthorizeResponse, error)
+	StepUp(context.Context, *StepUpRequest) (*StepUpResponse, error)
+	VerifyEmail(context.Context, *VerifyEmailRequest) (*VerifyEmailResponse, error)
+	ResendVerification(context.Context, *ResendVerificationRequest) (*ResendVerificationResponse, error)
+	StartPasswordless(context.Context, *StartPasswordlessRequest) (*StartPasswordlessResponse, error)
+	CompletePasswordless(context.Context, *CompletePasswordlessRequest) (*CompletePasswordlessResponse, error)
+	BeginFederatedLogin(context.Context, *BeginFederatedLoginRequest) (*BeginFederatedLoginResponse, error)
+	CompleteFederatedLogin(context.Context, *CompleteFederatedLoginRequest) (*CompleteFederatedLoginResponse, error)
+	GetSAMLMetadata(context.Context, *GetSAMLMetadataRequest) (*GetSAMLMetadataResponse, error)
+	BeginSAMLLogin(context.Context, *BeginSAMLLoginRequest) (*BeginSAMLLoginResponse, error)
+	CompleteSAMLLogin(context.Context, *CompleteSAMLLoginRequest) (*CompleteSAMLLoginResponse, error)
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error)
+	RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error)
+	EnrollTOTP(context.Context, *EnrollTOTPRequest) (*EnrollTOTPResponse, error)
+	ActivateTOTP(context.Context, *ActivateTOTPRequest) (*ActivateTOTPResponse, error)
 	mustEmbedUnimplementedAuthGRPCServiceServer()
 }
 
@@ -122,6 +434,84 @@ func (UnimplementedAuthGRPCServiceServer) SignUp(context.Context, *SignUpRequest
 func (UnimplementedAuthGRPCServiceServer) SignIn(context.Context, *SignInRequest) (*SignInResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SignIn not implemented")
 }
+func (UnimplementedAuthGRPCServiceServer) RevokeAccessToken(context.Context, *RevokeAccessTokenRequest) (*RevokeAccessTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAccessToken not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) SignOut(context.Context, *SignOutRequest) (*SignOutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignOut not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) SignOutEverywhere(context.Context, *SignOutEverywhereRequest) (*SignOutEverywhereResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignOutEverywhere not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) ExchangeToken(context.Context, *ExchangeTokenRequest) (*ExchangeTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExchangeToken not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) PutRole(context.Context, *PutRoleRequest) (*PutRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutRole not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) DeleteRole(context.Context, *DeleteRoleRequest) (*DeleteRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRole not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) ListRoles(context.Context, *ListRolesRequest) (*ListRolesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRoles not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) AssignRole(context.Context, *AssignRoleRequest) (*AssignRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignRole not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) UnassignRole(context.Context, *UnassignRoleRequest) (*UnassignRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnassignRole not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) ListUserRoles(context.Context, *ListUserRolesRequest) (*ListUserRolesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserRoles not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) Authorize(context.Context, *AuthorizeRequest) (*AuthorizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Authorize not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) StepUp(context.Context, *StepUpRequest) (*StepUpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StepUp not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) VerifyEmail(context.Context, *VerifyEmailRequest) (*VerifyEmailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyEmail not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) ResendVerification(context.Context, *ResendVerificationRequest) (*ResendVerificationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResendVerification not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) StartPasswordless(context.Context, *StartPasswordlessRequest) (*StartPasswordlessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartPasswordless not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) CompletePasswordless(context.Context, *CompletePasswordlessRequest) (*CompletePasswordlessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompletePasswordless not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) BeginFederatedLogin(context.Context, *BeginFederatedLoginRequest) (*BeginFederatedLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginFederatedLogin not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) CompleteFederatedLogin(context.Context, *CompleteFederatedLoginRequest) (*CompleteFederatedLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteFederatedLogin not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) GetSAMLMetadata(context.Context, *GetSAMLMetadataRequest) (*GetSAMLMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSAMLMetadata not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) BeginSAMLLogin(context.Context, *BeginSAMLLoginRequest) (*BeginSAMLLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginSAMLLogin not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) CompleteSAMLLogin(context.Context, *CompleteSAMLLoginRequest) (*CompleteSAMLLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteSAMLLogin not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAPIKeys not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAPIKey not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) EnrollTOTP(context.Context, *EnrollTOTPRequest) (*EnrollTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedAuthGRPCServiceServer) ActivateTOTP(context.Context, *ActivateTOTPRequest) (*ActivateTOTPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateTOTP not implemented")
+}
 func (UnimplementedAuthGRPCServiceServer) mustEmbedUnimplementedAuthGRPCServiceServer() {}
 
 // UnsafeAuthGRPCServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -225,32 +615,604 @@ func _AuthGRPCService_SignIn_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
-// AuthGRPCService_ServiceDesc is the grpc.ServiceDesc for AuthGRPCService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var AuthGRPCService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "proto.AuthGRPCService",
-	HandlerType: (*AuthGRPCServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "ValidateTokens",
-			Handler:    _AuthGRPCService_ValidateTokens_Handler,
-		},
-		{
-			MethodName: "GenerateTokens",
-			Handler:    _AuthGRPCService_GenerateTokens_Handler,
-		},
-		{
-			MethodName: "RefreshTokens",
-			Handler:    _AuthGRPCService_RefreshTokens_Handler,
-		},
-		{
-			MethodName: "SignUp",
-			Handler:    _AuthGRPCService_SignUp_Handler,
-		},
-		{
-			MethodName: "SignIn",
-			Handler:    _AuthGRPCService_SignIn_Handler,
+func _AuthGRPCService_RevokeAccessToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAccessTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).RevokeAccessToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_RevokeAccessToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).RevokeAccessToken(ctx, req.(*RevokeAccessTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_SignOut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignOutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).SignOut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_SignOut_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).SignOut(ctx, req.(*SignOutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_SignOutEverywhere_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignOutEverywhereRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).SignOutEverywhere(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_SignOutEverywhere_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).SignOutEverywhere(ctx, req.(*SignOutEverywhereRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_ExchangeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExchangeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).ExchangeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_ExchangeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).ExchangeToken(ctx, req.(*ExchangeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_PutRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).PutRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_PutRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).PutRole(ctx, req.(*PutRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_DeleteRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).DeleteRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_DeleteRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).DeleteRole(ctx, req.(*DeleteRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_ListRoles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRolesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).ListRoles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_ListRoles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).ListRoles(ctx, req.(*ListRolesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_AssignRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).AssignRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_AssignRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).AssignRole(ctx, req.(*AssignRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_UnassignRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnassignRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).UnassignRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_UnassignRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).UnassignRole(ctx, req.(*UnassignRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_ListUserRoles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserRolesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).ListUserRoles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_ListUserRoles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).ListUserRoles(ctx, req.(*ListUserRolesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_Authorize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthorizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).Authorize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_Authorize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).Authorize(ctx, req.(*AuthorizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_StepUp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepUpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).StepUp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_StepUp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).StepUp(ctx, req.(*StepUpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).VerifyEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_VerifyEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).VerifyEmail(ctx, req.(*VerifyEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_ResendVerification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResendVerificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).ResendVerification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_ResendVerification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).ResendVerification(ctx, req.(*ResendVerificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_StartPasswordless_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartPasswordlessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).StartPasswordless(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_StartPasswordless_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).StartPasswordless(ctx, req.(*StartPasswordlessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_CompletePasswordless_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompletePasswordlessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).CompletePasswordless(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_CompletePasswordless_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).CompletePasswordless(ctx, req.(*CompletePasswordlessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_BeginFederatedLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginFederatedLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).BeginFederatedLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_BeginFederatedLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).BeginFederatedLogin(ctx, req.(*BeginFederatedLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_CompleteFederatedLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteFederatedLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).CompleteFederatedLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_CompleteFederatedLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).CompleteFederatedLogin(ctx, req.(*CompleteFederatedLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_GetSAMLMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSAMLMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).GetSAMLMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_GetSAMLMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).GetSAMLMetadata(ctx, req.(*GetSAMLMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_BeginSAMLLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginSAMLLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).BeginSAMLLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_BeginSAMLLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).BeginSAMLLogin(ctx, req.(*BeginSAMLLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_CompleteSAMLLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteSAMLLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).CompleteSAMLLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_CompleteSAMLLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).CompleteSAMLLogin(ctx, req.(*CompleteSAMLLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_CreateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_ListAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).ListAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_ListAPIKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).ListAPIKeys(ctx, req.(*ListAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_RevokeAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).RevokeAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_RevokeAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).RevokeAPIKey(ctx, req.(*RevokeAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).EnrollTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_EnrollTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).EnrollTOTP(ctx, req.(*EnrollTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthGRPCService_ActivateTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivateTOTPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthGRPCServiceServer).ActivateTOTP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthGRPCService_ActivateTOTP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthGRPCServiceServer).ActivateTOTP(ctx, req.(*ActivateTOTPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuthGRPCService_ServiceDesc is the grpc.ServiceDesc for AuthGRPCService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuthGRPCService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.AuthGRPCService",
+	HandlerType: (*AuthGRPCServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateTokens",
+			Handler:    _AuthGRPCService_ValidateTokens_Handler,
+		},
+		{
+			MethodName: "GenerateTokens",
+			Handler:    _AuthGRPCService_GenerateTokens_Handler,
+		},
+		{
+			MethodName: "RefreshTokens",
+			Handler:    _AuthGRPCService_RefreshTokens_Handler,
+		},
+		{
+			MethodName: "SignUp",
+			Handler:    _AuthGRPCService_SignUp_Handler,
+		},
+		{
+			MethodName: "SignIn",
+			Handler:    _AuthGRPCService_SignIn_Handler,
+		},
+		{
+			MethodName: "RevokeAccessToken",
+			Handler:    _AuthGRPCService_RevokeAccessToken_Handler,
+		},
+		{
+			MethodName: "SignOut",
+			Handler:    _AuthGRPCService_SignOut_Handler,
+		},
+		{
+			MethodName: "SignOutEverywhere",
+			Handler:    _AuthGRPCService_SignOutEverywhere_Handler,
+		},
+		{
+			MethodName: "ExchangeToken",
+			Handler:    _AuthGRPCService_ExchangeToken_Handler,
+		},
+		{
+			MethodName: "PutRole",
+			Handler:    _AuthGRPCService_PutRole_Handler,
+		},
+		{
+			MethodName: "DeleteRole",
+			Handler:    _AuthGRPCService_DeleteRole_Handler,
+		},
+		{
+			MethodName: "ListRoles",
+			Handler:    _AuthGRPCService_ListRoles_Handler,
+		},
+		{
+			MethodName: "AssignRole",
+			Handler:    _AuthGRPCService_AssignRole_Handler,
+		},
+		{
+			MethodName: "UnassignRole",
+			Handler:    _AuthGRPCService_UnassignRole_Handler,
+		},
+		{
+			MethodName: "ListUserRoles",
+			Handler:    _AuthGRPCService_ListUserRoles_Handler,
+		},
+		{
+			MethodName: "Authorize",
+			Handler:    _AuthGRPCService_Authorize_Handler,
+		},
+		{
+			MethodName: "StepUp",
+			Handler:    _AuthGRPCService_StepUp_Handler,
+		},
+		{
+			MethodName: "VerifyEmail",
+			Handler:    _AuthGRPCService_VerifyEmail_Handler,
+		},
+		{
+			MethodName: "ResendVerification",
+			Handler:    _AuthGRPCService_ResendVerification_Handler,
+		},
+		{
+			MethodName: "StartPasswordless",
+			Handler:    _AuthGRPCService_StartPasswordless_Handler,
+		},
+		{
+			MethodName: "CompletePasswordless",
+			Handler:    _AuthGRPCService_CompletePasswordless_Handler,
+		},
+		{
+			MethodName: "BeginFederatedLogin",
+			Handler:    _AuthGRPCService_BeginFederatedLogin_Handler,
+		},
+		{
+			MethodName: "CompleteFederatedLogin",
+			Handler:    _AuthGRPCService_CompleteFederatedLogin_Handler,
+		},
+		{
+			MethodName: "GetSAMLMetadata",
+			Handler:    _AuthGRPCService_GetSAMLMetadata_Handler,
+		},
+		{
+			MethodName: "BeginSAMLLogin",
+			Handler:    _AuthGRPCService_BeginSAMLLogin_Handler,
+		},
+		{
+			MethodName: "CompleteSAMLLogin",
+			Handler:    _AuthGRPCService_CompleteSAMLLogin_Handler,
+		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _AuthGRPCService_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "ListAPIKeys",
+			Handler:    _AuthGRPCService_ListAPIKeys_Handler,
+		},
+		{
+			MethodName: "RevokeAPIKey",
+			Handler:    _AuthGRPCService_RevokeAPIKey_Handler,
+		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _AuthGRPCService_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "ActivateTOTP",
+			Handler:    _AuthGRPCService_ActivateTOTP_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},